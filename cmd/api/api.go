@@ -8,11 +8,14 @@ import (
 	_ "lunar/docs"
 	"lunar/internal/auth"
 	"lunar/internal/config"
+	"lunar/internal/filestore"
 	"lunar/internal/friendship"
 	"lunar/internal/httputil"
 	"lunar/internal/livekit"
 	"lunar/internal/message"
+	"lunar/internal/presence"
 	"lunar/internal/room"
+	"lunar/internal/search"
 	"lunar/internal/user"
 	"lunar/internal/ws"
 	"net/http"
@@ -54,12 +57,19 @@ func (app *application) mount() http.Handler {
 	authHandler := auth.NewHandler(app.validator, app.authService)
 	userHandler := user.NewHandler(app.validator, app.userService)
 	roomHandler := room.NewHandler(app.validator, app.roomService, app.wsService)
-	messageHandler := message.NewHandler(app.validator, app.messageService)
+	messageHandler := message.NewHandler(app.validator, app.messageService, app.wsService)
 	friendshipHandler := friendship.NewHandler(app.validator, app.friendshipService)
 	livekitHandler := livekit.NewHandler(app.livekitService)
+	presenceHandler := presence.NewHandler(app.presenceService)
+	searchHandler := search.NewHandler(app.searchService)
+	wsHandler := ws.NewHandler(app.wsService)
 
 	r.Mount("/api", r)
-	r.Handle("/uploads/*", http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads"))))
+	r.Get("/uploads/avatars/{filename}", userHandler.Avatar)
+	// The local and memory stores serve their own signed URLs.
+	if fileServer, ok := app.fileStore.(http.Handler); ok {
+		r.Get("/files/*", fileServer.ServeHTTP)
+	}
 
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/register", authHandler.Register)
@@ -78,6 +88,7 @@ func (app *application) mount() http.Handler {
 			r.Put("/email", userHandler.UpdateEmail)
 
 			r.Put("/password", userHandler.ChangePassword)
+			r.Put("/privacy", userHandler.UpdatePrivacy)
 			r.Post("/avatar", userHandler.UploadAvatar)
 		})
 
@@ -86,10 +97,42 @@ func (app *application) mount() http.Handler {
 			r.Post("/", roomHandler.CreateRoom)
 			r.Route("/{roomSlug:[a-z0-9]{11}}", func(r chi.Router) {
 				r.Post("/", roomHandler.JoinCurrentUser)
+				r.Patch("/", roomHandler.UpdateRoom)
+				r.Put("/owner", roomHandler.TransferOwnership)
+				r.Get("/members", roomHandler.ListMembers)
+				r.Delete("/members/me", roomHandler.LeaveRoom)
+				r.Delete("/members/{userId}", roomHandler.KickMember)
+				r.Put("/members/{userId}/role", roomHandler.SetMemberRole)
+				r.Put("/bans/{userId}", roomHandler.BanMember)
+				r.Delete("/bans/{userId}", roomHandler.UnbanUser)
+				r.Get("/invites", roomHandler.ListInvites)
+				r.Post("/invites", roomHandler.CreateInvite)
+				r.Delete("/invites/{inviteId}", roomHandler.RevokeInvite)
+				r.Put("/read", roomHandler.MarkRead)
+				r.Get("/pins", roomHandler.ListPins)
+				r.Put("/pins/{messageId}", roomHandler.PinMessage)
+				r.Delete("/pins/{messageId}", roomHandler.UnpinMessage)
+				r.Post("/attachments", messageHandler.UploadAttachment)
+				r.Get("/attachments/{attachmentId}", messageHandler.DownloadAttachment)
+				r.Get("/attachments/{attachmentId}/thumbnail", messageHandler.DownloadThumbnail)
 				r.Get("/messages", messageHandler.ListMessages)
+				r.Patch("/messages/{messageId}", messageHandler.EditMessage)
+				r.Delete("/messages/{messageId}", messageHandler.DeleteMessage)
+				r.Get("/messages/{messageId}/revisions", messageHandler.ListRevisions)
+				r.Get("/messages/{messageId}/receipts", messageHandler.ListReadReceipts)
+				r.Get("/messages/{messageId}/thread", messageHandler.ListThreadMessages)
+				r.Put("/messages/{messageId}/thread/follow", messageHandler.FollowThread)
+				r.Delete("/messages/{messageId}/thread/follow", messageHandler.UnfollowThread)
+				r.Put("/messages/{messageId}/reactions/{emoji}", messageHandler.AddReaction)
+				r.Delete("/messages/{messageId}/reactions/{emoji}", messageHandler.RemoveReaction)
+				r.Get("/presence", presenceHandler.ListRoomPresence)
 			})
 		})
 
+		r.Post("/invites/{code}", roomHandler.JoinWithInvite)
+
+		r.Get("/search/messages", searchHandler.SearchMessages)
+
 		r.Route("/friends", func(r chi.Router) {
 			r.Get("/", friendshipHandler.ListFriends)
 			r.Post("/requests", friendshipHandler.SendFriendRequest)
@@ -104,8 +147,10 @@ func (app *application) mount() http.Handler {
 		r.Get("/livekit/token/{roomSlug}", livekitHandler.Token)
 	})
 
-	r.With(wsAuthMw).
-		Get("/rooms/{roomSlug:[a-z0-9]{11}}/ws", roomHandler.Websocket)
+	r.With(wsAuthMw).Group(func(r chi.Router) {
+		r.Get("/ws", wsHandler.Connect)
+		r.Get("/rooms/{roomSlug:[a-z0-9]{11}}/ws", roomHandler.Websocket)
+	})
 
 	return r
 }
@@ -121,6 +166,12 @@ func (app *application) run(h http.Handler) error {
 
 	shutdown := make(chan error)
 
+	sweepCtx, stopSweep := context.WithCancel(context.Background())
+	defer stopSweep()
+	go app.wsService.SweepPresence(sweepCtx, app.config.Presence.SweepInterval)
+	messageCfg := app.config.Message
+	go app.messageService.PurgeDeleted(sweepCtx, messageCfg.PurgeInterval, messageCfg.DeletedRetention, messageCfg.UnsentAttachmentRetention)
+
 	go func() {
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -149,6 +200,9 @@ func (app *application) run(h http.Handler) error {
 	slog.Info("completing background tasks", "addr", srv.Addr)
 
 	app.db.Close()
+	if err := app.roomStream.Close(); err != nil {
+		slog.Error("failed to close room stream", "error", err)
+	}
 	if err := app.rdb.Close(); err != nil {
 		slog.Error("failed to close redis", "error", err)
 	}
@@ -162,12 +216,16 @@ type application struct {
 	config            *config.Config
 	db                *pgxpool.Pool
 	rdb               *redis.Client
+	fileStore         filestore.Store
+	roomStream        *ws.EventStream
 	authenticator     *auth.Authenticator
 	authService       *auth.Service
 	userService       *user.Service
 	roomService       *room.Service
+	presenceService   *presence.Service
 	wsService         *ws.Service
 	messageService    *message.Service
+	searchService     *search.Service
 	friendshipService *friendship.FriendshipService
 	validator         *httputil.Validator
 	livekitService    *livekit.Service
@@ -2,18 +2,24 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"fmt"
 	"log/slog"
+	"lunar/internal/access"
 	"lunar/internal/auth"
 	"lunar/internal/config"
 	"lunar/internal/db/postgres"
 	db "lunar/internal/db/postgres/sqlc"
 	redis2 "lunar/internal/db/redis"
+	"lunar/internal/filestore"
 	"lunar/internal/friendship"
 	"lunar/internal/httputil"
 	"lunar/internal/livekit"
 	"lunar/internal/message"
 	"lunar/internal/notification"
+	"lunar/internal/presence"
 	"lunar/internal/room"
+	"lunar/internal/search"
 	"lunar/internal/user"
 
 	"lunar/internal/ws"
@@ -51,14 +57,26 @@ func main() {
 
 	queries := db.New(pool)
 
+	fileStore, err := newFileStore(cfg.FileStore)
+	if err != nil {
+		slog.Error("failed to set up file store", "error", err)
+		os.Exit(1)
+	}
+
 	accessCfg := cfg.Auth.AccessToken
 	authenticator := auth.NewJWTAuthenticator(accessCfg.Secret, accessCfg.Issuer, accessCfg.TTL)
 	refreshCfg := cfg.Auth.RefreshToken
 	refreshRepo := redis2.NewRefreshTokenRepository(rdb, refreshCfg.KeyPrefix, refreshCfg.UserKeyPrefix, refreshCfg.TTL)
 	userRepo := postgres.NewUserRepository(queries)
-	roomRepo := postgres.NewRoomRepository(queries)
-	messageRepo := postgres.NewMessageRepository(queries)
+	roomRepo := postgres.NewRoomRepository(pool, queries)
+	messageRepo := postgres.NewMessageRepository(pool, queries)
+	reactionRepo := postgres.NewReactionRepository(queries)
+	threadRepo := postgres.NewThreadRepository(queries)
+	attachmentRepo := postgres.NewAttachmentRepository(queries)
+	inviteRepo := postgres.NewInviteRepository(pool, queries)
 	friendshipRepo := postgres.NewFriendshipRepository(pool, queries)
+	presenceCfg := cfg.Presence
+	presenceRepo := redis2.NewPresenceRepository(rdb, presenceCfg.KeyPrefix, presenceCfg.SessionTTL, presenceCfg.LastSeenTTL)
 
 	authService := auth.NewService(
 		authenticator,
@@ -67,24 +85,53 @@ func main() {
 		notification.NewLogEmailSender(logger),
 		cfg.Features.HasEmailVerification,
 	)
-	userService := user.NewService(userRepo, authService, cfg.FileStore.AvatarsPath())
-	roomService := room.NewService(roomRepo)
-	wsService := ws.NewService(rdb, userRepo, messageRepo, cfg.CORS.AllowedOrigins)
-	messageService := message.NewService(roomRepo, messageRepo)
-	friendshipService := friendship.NewFriendshipService(friendshipRepo, userRepo)
-	livekitService := livekit.NewService(cfg.LiveKit.APIKey, cfg.LiveKit.APISecret)
+	userService := user.NewService(userRepo, authService, filestore.WithPrefix(fileStore, cfg.FileStore.AvatarsDir))
+	roomAccess := access.NewChecker(roomRepo)
+	roomService := room.NewService(roomAccess, roomRepo, messageRepo, inviteRepo, presenceRepo, cfg.Room.MaxPins)
+	presenceService := presence.NewService(presenceRepo, roomAccess, roomRepo, friendshipRepo)
+	messageService := message.NewService(
+		roomAccess,
+		messageRepo,
+		reactionRepo,
+		threadRepo,
+		attachmentRepo,
+		filestore.WithPrefix(fileStore, cfg.FileStore.AttachmentsDir),
+		cfg.FileStore.SignedURLTTL,
+		cfg.Message.MaxAttachmentSize,
+	)
+	wsCfg := cfg.WebSocket
+	roomStream := ws.NewEventStream(rdb, wsCfg.StreamKeyPrefix, wsCfg.StreamMaxLen, wsCfg.StreamTTL, wsCfg.ReaderPoolSize)
+	userStream := roomStream.WithKeyPrefix(wsCfg.UserStreamKeyPrefix)
+	wsService := ws.NewService(
+		roomStream,
+		userStream,
+		roomService,
+		messageService,
+		presenceService,
+		userRepo,
+		messageRepo,
+		wsCfg.SendQueueSize,
+		cfg.CORS.AllowedOrigins,
+	)
+	searchService := search.NewService(roomRepo, messageRepo, attachmentRepo)
+	friendshipService := friendship.NewFriendshipService(friendshipRepo, userRepo, presenceRepo)
+	livekitService := livekit.NewService(roomAccess, cfg.LiveKit.APIKey, cfg.LiveKit.APISecret)
 	validator := httputil.NewValidator()
 
 	api := application{
 		config:            cfg,
 		db:                pool,
 		rdb:               rdb,
+		fileStore:         fileStore,
+		roomStream:        roomStream,
 		authenticator:     authenticator,
 		authService:       authService,
 		userService:       userService,
 		roomService:       roomService,
+		presenceService:   presenceService,
 		wsService:         wsService,
 		messageService:    messageService,
+		searchService:     searchService,
 		friendshipService: friendshipService,
 		livekitService:    livekitService,
 		validator:         validator,
@@ -95,3 +142,32 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newFileStore sets up the file store backend selected in the config.
+func newFileStore(cfg config.FileStoreConfig) (filestore.Store, error) {
+	signingKey := []byte(cfg.SigningKey)
+	if len(signingKey) == 0 && cfg.Backend != "s3" {
+		signingKey = make([]byte, 32)
+		rand.Read(signingKey)
+		slog.Warn("APP_FILESTORE_SIGNING_KEY is not set, signed file URLs only work on this instance")
+	}
+
+	switch cfg.Backend {
+	case "local":
+		return filestore.NewLocalStore(cfg.RootDir, cfg.PublicURL, signingKey), nil
+	case "memory":
+		return filestore.NewMemoryStore(cfg.PublicURL, signingKey), nil
+	case "s3":
+		return filestore.NewS3Store(filestore.S3Config{
+			Endpoint:        cfg.S3.Endpoint,
+			PublicEndpoint:  cfg.S3.PublicEndpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			PathStyle:       cfg.S3.PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown file store backend %q", cfg.Backend)
+	}
+}
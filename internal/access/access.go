@@ -0,0 +1,67 @@
+// Package access decides what a user may do in a room based on their role in
+// it. Services of the room, message and call features share it so the same
+// action is allowed or refused the same way everywhere.
+package access
+
+import (
+	"context"
+	"errors"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRoomNotFound     = repository.ErrRoomNotFound
+	ErrNotRoomMember    = errors.New("not a room member")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+type Checker struct {
+	rooms repository.RoomRepository
+}
+
+func NewChecker(rooms repository.RoomRepository) *Checker {
+	return &Checker{rooms}
+}
+
+// Member loads a room the user is a member of along with their role in it.
+func (c *Checker) Member(ctx context.Context, userID uuid.UUID, roomSlug string) (model.Room, model.RoomRole, error) {
+	room, err := c.rooms.GetBySlug(ctx, roomSlug)
+	if err != nil {
+		return model.Room{}, "", err
+	}
+
+	role, err := c.rooms.GetMemberRole(ctx, room.ID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoomMemberNotFound) {
+			return model.Room{}, "", ErrNotRoomMember
+		}
+		return model.Room{}, "", err
+	}
+	return room, role, nil
+}
+
+// Require is like Member but fails with ErrPermissionDenied unless the role
+// of the user grants the permission.
+func (c *Checker) Require(ctx context.Context, userID uuid.UUID, roomSlug string, permission model.RoomPermission) (model.Room, model.RoomRole, error) {
+	room, role, err := c.Member(ctx, userID, roomSlug)
+	if err != nil {
+		return model.Room{}, "", err
+	}
+	if !role.Can(permission) {
+		return model.Room{}, "", ErrPermissionDenied
+	}
+	return room, role, nil
+}
+
+// RoleOf returns the role of a user in a room, or no role if they are not a
+// member, which every role outranks.
+func (c *Checker) RoleOf(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (model.RoomRole, error) {
+	role, err := c.rooms.GetMemberRole(ctx, roomID, userID)
+	if errors.Is(err, repository.ErrRoomMemberNotFound) {
+		return "", nil
+	}
+	return role, err
+}
@@ -0,0 +1,84 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type fakeRooms struct {
+	repository.RoomRepository
+	room  model.Room
+	roles map[uuid.UUID]model.RoomRole
+}
+
+func (r fakeRooms) GetBySlug(_ context.Context, slug string) (model.Room, error) {
+	if slug != r.room.Slug {
+		return model.Room{}, repository.ErrRoomNotFound
+	}
+	return r.room, nil
+}
+
+func (r fakeRooms) GetMemberRole(_ context.Context, roomID uuid.UUID, userID uuid.UUID) (model.RoomRole, error) {
+	role, ok := r.roles[userID]
+	if roomID != r.room.ID || !ok {
+		return "", repository.ErrRoomMemberNotFound
+	}
+	return role, nil
+}
+
+func TestRequire(t *testing.T) {
+	room := model.Room{ID: uuid.New(), Slug: "general"}
+	moderator := uuid.New()
+	member := uuid.New()
+	checker := NewChecker(fakeRooms{room: room, roles: map[uuid.UUID]model.RoomRole{
+		moderator: model.RoomRoleModerator,
+		member:    model.RoomRoleMember,
+	}})
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		slug       string
+		permission model.RoomPermission
+		want       error
+	}{
+		{"granted", moderator, "general", model.PermKickMembers, nil},
+		{"not granted", moderator, "general", model.PermBanMembers, ErrPermissionDenied},
+		{"member", member, "general", model.PermPinMessages, ErrPermissionDenied},
+		{"not a member", uuid.New(), "general", model.PermPinMessages, ErrNotRoomMember},
+		{"unknown room", moderator, "random", model.PermKickMembers, ErrRoomNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, role, err := checker.Require(context.Background(), tt.userID, tt.slug, tt.permission)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Require = %v, want %v", err, tt.want)
+			}
+			if err == nil && (got.ID != room.ID || role != model.RoomRoleModerator) {
+				t.Errorf("Require = %v as %q", got.ID, role)
+			}
+		})
+	}
+}
+
+func TestRoleOf(t *testing.T) {
+	room := model.Room{ID: uuid.New(), Slug: "general"}
+	admin := uuid.New()
+	checker := NewChecker(fakeRooms{room: room, roles: map[uuid.UUID]model.RoomRole{
+		admin: model.RoomRoleAdmin,
+	}})
+
+	role, err := checker.RoleOf(context.Background(), room.ID, admin)
+	if err != nil || role != model.RoomRoleAdmin {
+		t.Errorf("RoleOf(admin) = %q, %v", role, err)
+	}
+	role, err = checker.RoleOf(context.Background(), room.ID, uuid.New())
+	if err != nil || role != "" {
+		t.Errorf("RoleOf(stranger) = %q, %v", role, err)
+	}
+}
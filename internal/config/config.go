@@ -12,6 +12,10 @@ type Config struct {
 	Redis     RedisConfig
 	FileStore FileStoreConfig
 	LiveKit   LiveKitConfig
+	WebSocket WebSocketConfig
+	Presence  PresenceConfig
+	Message   MessageConfig
+	Room      RoomConfig
 	Features  FeaturesConfig
 }
 
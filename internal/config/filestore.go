@@ -1,12 +1,29 @@
 package config
 
-import "path/filepath"
+import "time"
 
 type FileStoreConfig struct {
-	RootDir    string `env:"APP_FILESTORE_ROOT" envDefault:"./uploads"`
-	AvatarsDir string `env:"APP_FILESTORE_ROOT" envDefault:"avatars"`
+	// Backend selects where files are kept: "local", "s3" or "memory".
+	Backend        string `env:"APP_FILESTORE_BACKEND" envDefault:"local"`
+	RootDir        string `env:"APP_FILESTORE_ROOT" envDefault:"./uploads"`
+	AvatarsDir     string `env:"APP_FILESTORE_AVATARS_DIR" envDefault:"avatars"`
+	AttachmentsDir string `env:"APP_FILESTORE_ATTACHMENTS_DIR" envDefault:"attachments"`
+	// PublicURL is where the local and memory backends serve their signed
+	// URLs from.
+	PublicURL string `env:"APP_FILESTORE_PUBLIC_URL" envDefault:"/files"`
+	// SigningKey signs the URLs of the local and memory backends. Replicas
+	// sharing a disk need the same key; without one a random key is used.
+	SigningKey   string        `env:"APP_FILESTORE_SIGNING_KEY"`
+	SignedURLTTL time.Duration `env:"APP_FILESTORE_SIGNED_URL_TTL" envDefault:"5m"`
+	S3           S3Config
 }
 
-func (c FileStoreConfig) AvatarsPath() string {
-	return filepath.Join(c.RootDir, c.AvatarsDir)
+type S3Config struct {
+	Endpoint        string `env:"APP_S3_ENDPOINT"`
+	PublicEndpoint  string `env:"APP_S3_PUBLIC_ENDPOINT"`
+	Region          string `env:"APP_S3_REGION" envDefault:"us-east-1"`
+	Bucket          string `env:"APP_S3_BUCKET"`
+	AccessKeyID     string `env:"APP_S3_ACCESS_KEY_ID"`
+	SecretAccessKey string `env:"APP_S3_SECRET_ACCESS_KEY"`
+	PathStyle       bool   `env:"APP_S3_PATH_STYLE" envDefault:"true"`
 }
@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+type MessageConfig struct {
+	// DeletedRetention is how long the content of a deleted message is kept
+	// before it is purged.
+	DeletedRetention time.Duration `env:"MESSAGE_DELETED_RETENTION" envDefault:"720h"`
+	PurgeInterval    time.Duration `env:"MESSAGE_PURGE_INTERVAL" envDefault:"1h"`
+	// MaxAttachmentSize is the largest file in bytes that can be uploaded as
+	// an attachment.
+	MaxAttachmentSize int64 `env:"MESSAGE_MAX_ATTACHMENT_SIZE" envDefault:"26214400"`
+	// UnsentAttachmentRetention is how long an upload that was never sent
+	// with a message is kept.
+	UnsentAttachmentRetention time.Duration `env:"MESSAGE_UNSENT_ATTACHMENT_RETENTION" envDefault:"24h"`
+}
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type PresenceConfig struct {
+	KeyPrefix     string        `env:"PRESENCE_KEY_PREFIX" envDefault:"presence:"`
+	SessionTTL    time.Duration `env:"PRESENCE_SESSION_TTL" envDefault:"90s"`
+	LastSeenTTL   time.Duration `env:"PRESENCE_LAST_SEEN_TTL" envDefault:"720h"`
+	SweepInterval time.Duration `env:"PRESENCE_SWEEP_INTERVAL" envDefault:"15s"`
+}
@@ -0,0 +1,7 @@
+package config
+
+type RoomConfig struct {
+	// MaxPins limits the pinned messages of rooms that don't set their own
+	// limit.
+	MaxPins int `env:"ROOM_MAX_PINS" envDefault:"50"`
+}
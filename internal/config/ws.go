@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+type WebSocketConfig struct {
+	StreamKeyPrefix     string        `env:"WS_STREAM_KEY_PREFIX" envDefault:"room:events:"`
+	UserStreamKeyPrefix string        `env:"WS_USER_STREAM_KEY_PREFIX" envDefault:"user:events:"`
+	StreamMaxLen        int64         `env:"WS_STREAM_MAX_LEN" envDefault:"1000"`
+	StreamTTL           time.Duration `env:"WS_STREAM_TTL" envDefault:"168h"`
+	ReaderPoolSize      int           `env:"WS_READER_POOL_SIZE" envDefault:"1000"`
+	SendQueueSize       int           `env:"WS_SEND_QUEUE_SIZE" envDefault:"256"`
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	db "lunar/internal/db/postgres/sqlc"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type AttachmentRepository struct {
+	queries db.Querier
+}
+
+func NewAttachmentRepository(queries db.Querier) repository.AttachmentRepository {
+	return &AttachmentRepository{queries}
+}
+
+func mapAttachment(a db.Attachment) model.Attachment {
+	return model.Attachment{
+		ID:           a.ID,
+		RoomID:       a.RoomID,
+		UploaderID:   a.UploaderID,
+		MessageID:    uuidOrNil(a.MessageID),
+		Name:         a.Name,
+		ContentType:  a.ContentType,
+		Size:         a.Size,
+		Width:        intOrNil(a.Width),
+		Height:       intOrNil(a.Height),
+		HasThumbnail: a.ThumbnailKey.Valid,
+		CreatedAt:    a.CreatedAt.Time,
+		StorageKey:   a.StorageKey,
+		ThumbnailKey: textOrEmpty(a.ThumbnailKey),
+	}
+}
+
+func mapAttachments(rows []db.Attachment) []model.Attachment {
+	result := make([]model.Attachment, len(rows))
+	for i, row := range rows {
+		result[i] = mapAttachment(row)
+	}
+	return result
+}
+
+func (r *AttachmentRepository) CreateAttachment(ctx context.Context, a model.Attachment) (model.Attachment, error) {
+	created, err := r.queries.CreateAttachment(ctx, db.CreateAttachmentParams{
+		ID:           a.ID,
+		RoomID:       a.RoomID,
+		UploaderID:   a.UploaderID,
+		Name:         a.Name,
+		ContentType:  a.ContentType,
+		Size:         a.Size,
+		Width:        int4OrNull(a.Width),
+		Height:       int4OrNull(a.Height),
+		StorageKey:   a.StorageKey,
+		ThumbnailKey: textFromString(a.ThumbnailKey),
+		CreatedAt:    timestampFromTime(a.CreatedAt),
+	})
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	return mapAttachment(created), nil
+}
+
+func (r *AttachmentRepository) GetAttachment(ctx context.Context, id uuid.UUID) (model.Attachment, error) {
+	row, err := r.queries.GetAttachment(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Attachment{}, repository.ErrAttachmentNotFound
+		}
+		return model.Attachment{}, err
+	}
+	if row.MessageDeletedAt.Valid {
+		return model.Attachment{}, repository.ErrAttachmentNotFound
+	}
+
+	return mapAttachment(db.Attachment{
+		ID:           row.ID,
+		RoomID:       row.RoomID,
+		UploaderID:   row.UploaderID,
+		MessageID:    row.MessageID,
+		Name:         row.Name,
+		ContentType:  row.ContentType,
+		Size:         row.Size,
+		Width:        row.Width,
+		Height:       row.Height,
+		StorageKey:   row.StorageKey,
+		ThumbnailKey: row.ThumbnailKey,
+		CreatedAt:    row.CreatedAt,
+	}), nil
+}
+
+func (r *AttachmentRepository) ListAttachmentsByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Attachment, error) {
+	rows, err := r.queries.ListAttachmentsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return mapAttachments(rows), nil
+}
+
+func (r *AttachmentRepository) ListAttachments(ctx context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]model.Attachment, error) {
+	rows, err := r.queries.ListMessagesAttachments(ctx, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make(map[uuid.UUID][]model.Attachment)
+	for _, row := range rows {
+		attachments[row.MessageID.Bytes] = append(attachments[row.MessageID.Bytes], mapAttachment(row))
+	}
+	return attachments, nil
+}
+
+func (r *AttachmentRepository) DeleteExpired(ctx context.Context, unsentBefore time.Time, deletedBefore time.Time, limit int) ([]model.Attachment, error) {
+	rows, err := r.queries.DeleteExpiredAttachments(ctx, db.DeleteExpiredAttachmentsParams{
+		UnsentBefore:  timestampFromTime(unsentBefore),
+		DeletedBefore: timestampFromTime(deletedBefore),
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapAttachments(rows), nil
+}
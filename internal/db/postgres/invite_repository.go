@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	db "lunar/internal/db/postgres/sqlc"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type InviteRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+func NewInviteRepository(pool *pgxpool.Pool, queries *db.Queries) repository.InviteRepository {
+	return &InviteRepository{pool, queries}
+}
+
+func mapRoomInvite(i db.RoomInvite) model.RoomInvite {
+	return model.RoomInvite{
+		ID:        i.ID,
+		RoomID:    i.RoomID,
+		Code:      i.Code,
+		CreatedBy: i.CreatedBy,
+		MaxUses:   intOrNil(i.MaxUses),
+		Uses:      int(i.Uses),
+		ExpiresAt: i.ExpiresAt.Time,
+		RevokedAt: timeOrNil(i.RevokedAt),
+		CreatedAt: i.CreatedAt.Time,
+	}
+}
+
+func (r *InviteRepository) CreateInvite(ctx context.Context, invite model.RoomInvite) (model.RoomInvite, error) {
+	created, err := r.queries.CreateRoomInvite(ctx, db.CreateRoomInviteParams{
+		ID:        invite.ID,
+		RoomID:    invite.RoomID,
+		Code:      invite.Code,
+		CreatedBy: invite.CreatedBy,
+		MaxUses:   int4OrNull(invite.MaxUses),
+		ExpiresAt: timestampFromTime(invite.ExpiresAt),
+		CreatedAt: timestampFromTime(invite.CreatedAt),
+	})
+	if err != nil {
+		return model.RoomInvite{}, err
+	}
+	return mapRoomInvite(created), nil
+}
+
+func (r *InviteRepository) ListActiveInvites(ctx context.Context, roomID uuid.UUID, now time.Time) ([]model.RoomInvite, error) {
+	rows, err := r.queries.ListActiveRoomInvites(ctx, db.ListActiveRoomInvitesParams{
+		RoomID: roomID,
+		Now:    timestampFromTime(now),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invites := make([]model.RoomInvite, len(rows))
+	for i, row := range rows {
+		invites[i] = mapRoomInvite(row)
+	}
+	return invites, nil
+}
+
+func (r *InviteRepository) RevokeInvite(ctx context.Context, roomID uuid.UUID, inviteID uuid.UUID, at time.Time) error {
+	rows, err := r.queries.RevokeRoomInvite(ctx, db.RevokeRoomInviteParams{
+		ID:        inviteID,
+		RoomID:    roomID,
+		RevokedAt: timestampFromTime(at),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrInviteNotFound
+	}
+	return nil
+}
+
+func (r *InviteRepository) RedeemInvite(ctx context.Context, code string, userID uuid.UUID, now time.Time) (model.RoomInvite, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return model.RoomInvite{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	// The row stays locked until commit so concurrent joins can't exceed
+	// the max uses.
+	row, err := qtx.GetRoomInviteByCodeForUpdate(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.RoomInvite{}, repository.ErrInviteNotFound
+		}
+		return model.RoomInvite{}, err
+	}
+	invite := mapRoomInvite(row)
+
+	isMember, err := qtx.IsUserRoomMember(ctx, db.IsUserRoomMemberParams{
+		RoomID: invite.RoomID,
+		UserID: userID,
+	})
+	if err != nil {
+		return model.RoomInvite{}, err
+	}
+	if isMember {
+		return invite, nil
+	}
+
+	if err := invite.Usable(now); err != nil {
+		return model.RoomInvite{}, err
+	}
+
+	_, err = qtx.GetActiveRoomBan(ctx, db.GetActiveRoomBanParams{
+		RoomID: invite.RoomID,
+		UserID: userID,
+		Now:    timestampFromTime(now),
+	})
+	if err == nil {
+		return model.RoomInvite{}, model.ErrBannedFromRoom
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return model.RoomInvite{}, err
+	}
+
+	if err := qtx.IncrementRoomInviteUses(ctx, invite.ID); err != nil {
+		return model.RoomInvite{}, err
+	}
+	if err := addMember(ctx, qtx, model.NewRoomMember(userID, invite.RoomID, model.RoomRoleMember)); err != nil {
+		return model.RoomInvite{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.RoomInvite{}, err
+	}
+	invite.Uses++
+	return invite, nil
+}
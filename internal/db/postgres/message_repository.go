@@ -2,63 +2,245 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	db "lunar/internal/db/postgres/sqlc"
 	"lunar/internal/model"
 	"lunar/internal/pagination"
+	"lunar/internal/repository"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type MessageRepository struct {
+	pool    *pgxpool.Pool
 	queries *db.Queries
 }
 
-func NewMessageRepository(queries *db.Queries) *MessageRepository {
-	return &MessageRepository{queries}
+func NewMessageRepository(pool *pgxpool.Pool, queries *db.Queries) *MessageRepository {
+	return &MessageRepository{pool, queries}
 }
 
 func mapMessage(message db.Message, sender model.MessageSender) model.Message {
 	return model.Message{
 		ID:        message.ID,
 		RoomID:    message.RoomID,
-		Content:   message.Content,
+		Content:   tombstoneContent(message.Content, message.DeletedAt),
 		Sender:    sender,
 		CreatedAt: message.CreatedAt.Time,
+		EditedAt:  timeOrNil(message.EditedAt),
+		DeletedAt: timeOrNil(message.DeletedAt),
+		ThreadID:  uuidOrNil(message.ThreadID),
 	}
 }
 
-func mapMessages(rows []db.GetMessagesPagingRow) []model.Message {
-	result := make([]model.Message, 0, len(rows))
-	for _, r := range rows {
-		result = append(result, model.Message{
-			ID:        r.ID,
-			RoomID:    r.RoomID,
-			Content:   r.Content,
-			CreatedAt: r.CreatedAt.Time,
+func mapMessageRow(r db.GetMessagesPagingRow) model.Message {
+	message := model.Message{
+		ID:        r.ID,
+		RoomID:    r.RoomID,
+		Content:   tombstoneContent(r.Content, r.DeletedAt),
+		CreatedAt: r.CreatedAt.Time,
+		EditedAt:  timeOrNil(r.EditedAt),
+		DeletedAt: timeOrNil(r.DeletedAt),
+		ThreadID:  uuidOrNil(r.ThreadID),
+		Sender: model.MessageSender{
+			ID:        r.SenderID,
+			Username:  r.Username,
+			AvatarURL: textOrEmpty(r.AvatarUrl),
+		},
+	}
+
+	if r.ReplyToID.Valid {
+		reply := model.NewMessageReply(model.Message{
+			ID:        r.ReplyToID.Bytes,
+			Content:   textOrEmpty(r.ParentContent),
+			DeletedAt: timeOrNil(r.ParentDeletedAt),
 			Sender: model.MessageSender{
-				ID:        r.SenderID,
-				Username:  r.Username,
-				AvatarURL: textOrEmpty(r.AvatarUrl),
+				ID:        r.ParentSenderID.Bytes,
+				Username:  textOrEmpty(r.ParentSenderUsername),
+				AvatarURL: textOrEmpty(r.ParentSenderAvatarUrl),
 			},
 		})
+		message.ReplyTo = &reply
+	}
+
+	return message
+}
+
+// tombstoneContent hides the content of deleted messages until it is purged.
+func tombstoneContent(content string, deletedAt pgtype.Timestamptz) string {
+	if deletedAt.Valid {
+		return ""
+	}
+	return content
+}
+
+func replyToID(reply *model.MessageReply) pgtype.UUID {
+	if reply == nil {
+		return pgtype.UUID{}
+	}
+	return uuidOrNull(&reply.ID)
+}
+
+func mapMessages(rows []db.GetMessagesPagingRow) []model.Message {
+	result := make([]model.Message, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, mapMessageRow(r))
 	}
 	return result
 }
 
 func (r *MessageRepository) CreateMessage(ctx context.Context, msg model.Message) (model.Message, error) {
-	createdMessage, err := r.queries.CreateMessage(ctx, db.CreateMessageParams{
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return model.Message{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	createdMessage, err := qtx.CreateMessage(ctx, db.CreateMessageParams{
 		ID:        msg.ID,
 		RoomID:    msg.RoomID,
 		SenderID:  msg.Sender.ID,
 		Content:   msg.Content,
 		CreatedAt: timestampFromTime(msg.CreatedAt),
+		ReplyToID: replyToID(msg.ReplyTo),
+		ThreadID:  uuidOrNull(msg.ThreadID),
 	})
 	if err != nil {
 		return model.Message{}, err
 	}
 
-	return mapMessage(createdMessage, msg.Sender), err
+	if len(msg.Attachments) > 0 {
+		ids := make([]uuid.UUID, len(msg.Attachments))
+		for i, a := range msg.Attachments {
+			ids[i] = a.ID
+		}
+
+		// Only claim uploads that are still unsent, so an attachment can't
+		// end up on two messages sent at the same time.
+		claimed, err := qtx.ClaimAttachments(ctx, db.ClaimAttachmentsParams{
+			MessageID:  msg.ID,
+			Ids:        ids,
+			RoomID:     msg.RoomID,
+			UploaderID: msg.Sender.ID,
+		})
+		if err != nil {
+			return model.Message{}, err
+		}
+		if claimed != int64(len(ids)) {
+			return model.Message{}, model.ErrAttachmentUnavailable
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Message{}, err
+	}
+
+	created := mapMessage(createdMessage, msg.Sender)
+	created.ReplyTo = msg.ReplyTo
+	created.Attachments = msg.Attachments
+	return created, nil
+}
+
+func (r *MessageRepository) GetMessage(ctx context.Context, id uuid.UUID) (model.Message, error) {
+	row, err := r.queries.GetMessage(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Message{}, repository.ErrMessageNotFound
+		}
+		return model.Message{}, err
+	}
+
+	return mapMessageRow(db.GetMessagesPagingRow(row)), nil
+}
+
+func (r *MessageRepository) UpdateMessage(ctx context.Context, msg model.Message) (model.Message, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return model.Message{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	// Lock the row so concurrent edits each record the content they replace.
+	current, err := qtx.LockMessage(ctx, msg.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Message{}, repository.ErrMessageNotFound
+		}
+		return model.Message{}, err
+	}
+	if current.DeletedAt.Valid {
+		return model.Message{}, model.ErrMessageDeleted
+	}
+
+	if err := qtx.CreateMessageRevision(ctx, db.CreateMessageRevisionParams{
+		ID:        uuid.Must(uuid.NewV7()),
+		MessageID: current.ID,
+		Content:   current.Content,
+		EditedAt:  timestampFromTime(*msg.EditedAt),
+	}); err != nil {
+		return model.Message{}, err
+	}
+
+	if err := qtx.UpdateMessageContent(ctx, db.UpdateMessageContentParams{
+		Content:  msg.Content,
+		EditedAt: timestampFromTime(*msg.EditedAt),
+		ID:       msg.ID,
+	}); err != nil {
+		return model.Message{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Message{}, err
+	}
+	return msg, nil
+}
+
+func (r *MessageRepository) DeleteMessage(ctx context.Context, msg model.Message, deletedBy uuid.UUID) error {
+	rows, err := r.queries.SoftDeleteMessage(ctx, db.SoftDeleteMessageParams{
+		DeletedAt: timestampFromTime(*msg.DeletedAt),
+		DeletedBy: pgtype.UUID{Bytes: deletedBy, Valid: true},
+		ID:        msg.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return model.ErrMessageDeleted
+	}
+	return nil
+}
+
+func (r *MessageRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	return r.queries.PurgeDeletedMessages(ctx, db.PurgeDeletedMessagesParams{
+		DeletedBefore: timestampFromTime(deletedBefore),
+		Limit:         int32(limit),
+	})
+}
+
+func (r *MessageRepository) ListRevisions(ctx context.Context, messageID uuid.UUID) ([]model.MessageRevision, error) {
+	rows, err := r.queries.ListMessageRevisions(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]model.MessageRevision, len(rows))
+	for i, row := range rows {
+		revisions[i] = model.MessageRevision{
+			ID:        row.ID,
+			MessageID: row.MessageID,
+			Content:   row.Content,
+			EditedAt:  row.EditedAt.Time,
+		}
+	}
+	return revisions, nil
 }
 
 func (r *MessageRepository) ListMessages(ctx context.Context, roomID uuid.UUID, limit int, cursor *pagination.Cursor) ([]model.Message, error) {
@@ -83,3 +265,119 @@ func (r *MessageRepository) ListMessages(ctx context.Context, roomID uuid.UUID,
 
 	return mapMessages(messages), nil
 }
+
+func (r *MessageRepository) ListThreadMessages(ctx context.Context, threadID uuid.UUID, limit int, cursor *pagination.Cursor) ([]model.Message, error) {
+	params := db.GetThreadMessagesPagingParams{
+		ThreadID: threadID,
+		Limit:    int32(limit),
+	}
+
+	if cursor != nil {
+		params.CursorID = cursor.ID
+		params.CursorCreatedAt = timestampFromTime(cursor.CreatedAt)
+	}
+
+	rows, err := r.queries.GetThreadMessagesPaging(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.Message, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, mapMessageRow(db.GetMessagesPagingRow(row)))
+	}
+	return result, nil
+}
+
+func (r *MessageRepository) ListMessagesAfter(ctx context.Context, roomID uuid.UUID, limit int, cursor pagination.Cursor) ([]model.Message, error) {
+	rows, err := r.queries.GetMessagesAfter(ctx, db.GetMessagesAfterParams{
+		RoomID:          roomID,
+		CursorCreatedAt: timestampFromTime(cursor.CreatedAt),
+		CursorID:        cursor.ID,
+		Limit:           int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.Message, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, mapMessageRow(db.GetMessagesPagingRow(row)))
+	}
+	return result, nil
+}
+
+func (r *MessageRepository) ListReadReceipts(ctx context.Context, msg model.Message) ([]model.ReadReceipt, error) {
+	rows, err := r.queries.ListMessageReaders(ctx, db.ListMessageReadersParams{
+		RoomID:    msg.RoomID,
+		SenderID:  msg.Sender.ID,
+		CreatedAt: timestampFromTime(msg.CreatedAt),
+		MessageID: msg.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make([]model.ReadReceipt, len(rows))
+	for i, row := range rows {
+		receipts[i] = model.ReadReceipt{
+			UserID:    row.ID,
+			Username:  row.Username,
+			AvatarURL: textOrEmpty(row.AvatarUrl),
+			MessageID: msg.ID,
+		}
+	}
+	return receipts, nil
+}
+
+func (r *MessageRepository) SearchMessages(ctx context.Context, userID uuid.UUID, search repository.MessageSearch, limit int, cursor *pagination.RankedCursor) ([]model.MessageSearchResult, error) {
+	params := db.SearchMessagesParams{
+		Query:      search.Query,
+		UserID:     userID,
+		RoomID:     uuidOrNull(search.RoomID),
+		SenderID:   uuidOrNull(search.SenderID),
+		SentAfter:  timestampOrNull(search.SentAfter),
+		SentBefore: timestampOrNull(search.SentBefore),
+		Limit:      int32(limit),
+	}
+	if search.HasAttachment != nil {
+		params.HasAttachment = pgtype.Bool{Bool: *search.HasAttachment, Valid: true}
+	}
+	if cursor != nil {
+		params.CursorRank = pgtype.Float4{Float32: cursor.Rank, Valid: true}
+		params.CursorCreatedAt = timestampFromTime(cursor.Cursor.CreatedAt)
+		params.CursorID = cursor.Cursor.ID
+	}
+
+	rows, err := r.queries.SearchMessages(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.MessageSearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = model.MessageSearchResult{
+			Message: model.Message{
+				ID:        row.ID,
+				RoomID:    row.RoomID,
+				Content:   row.Content,
+				CreatedAt: row.CreatedAt.Time,
+				EditedAt:  timeOrNil(row.EditedAt),
+				ThreadID:  uuidOrNil(row.ThreadID),
+				Sender: model.MessageSender{
+					ID:        row.SenderID,
+					Username:  row.SenderUsername,
+					AvatarURL: textOrEmpty(row.SenderAvatarUrl),
+				},
+			},
+			Room: model.SearchRoom{
+				ID:   row.RoomID,
+				Slug: row.RoomSlug,
+				Name: textOrEmpty(row.RoomName),
+			},
+			Highlight: row.Highlight,
+			Rank:      row.Rank,
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	db "lunar/internal/db/postgres/sqlc"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReactionRepository struct {
+	queries db.Querier
+}
+
+func NewReactionRepository(queries db.Querier) repository.ReactionRepository {
+	return &ReactionRepository{queries}
+}
+
+func (r *ReactionRepository) AddReaction(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string, maxEmojis int) (bool, error) {
+	rows, err := r.queries.AddMessageReaction(ctx, db.AddMessageReactionParams{
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: timestampFromTime(time.Now()),
+		MaxEmojis: int32(maxEmojis),
+	})
+	if err != nil {
+		return false, err
+	}
+	if rows > 0 {
+		return true, nil
+	}
+
+	// Nothing was inserted either because the reaction exists or because
+	// the message is at the cap.
+	exists, err := r.queries.UserReactionExists(ctx, db.UserReactionExistsParams{
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+	})
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, model.ErrTooManyReactions
+	}
+	return false, nil
+}
+
+func (r *ReactionRepository) RemoveReaction(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) (bool, error) {
+	rows, err := r.queries.RemoveMessageReaction(ctx, db.RemoveMessageReactionParams{
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *ReactionRepository) CountReaction(ctx context.Context, messageID uuid.UUID, emoji string) (int, error) {
+	count, err := r.queries.CountMessageReaction(ctx, db.CountMessageReactionParams{
+		MessageID: messageID,
+		Emoji:     emoji,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *ReactionRepository) ListReactions(ctx context.Context, messageIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID][]model.Reaction, error) {
+	rows, err := r.queries.ListMessagesReactions(ctx, db.ListMessagesReactionsParams{
+		ViewerID:   viewerID,
+		MessageIds: messageIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reactions := make(map[uuid.UUID][]model.Reaction)
+	for _, row := range rows {
+		reactions[row.MessageID] = append(reactions[row.MessageID], model.Reaction{
+			Emoji:       row.Emoji,
+			Count:       int(row.Count),
+			ReactedByMe: row.ReactedByMe,
+		})
+	}
+	return reactions, nil
+}
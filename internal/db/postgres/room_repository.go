@@ -5,25 +5,33 @@ import (
 	"errors"
 	db "lunar/internal/db/postgres/sqlc"
 	"lunar/internal/model"
+	"lunar/internal/pagination"
 	"lunar/internal/repository"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type RoomRepository struct {
-	queries db.Querier
+	pool    *pgxpool.Pool
+	queries *db.Queries
 }
 
-func NewRoomRepository(queries db.Querier) repository.RoomRepository {
-	return &RoomRepository{queries}
+func NewRoomRepository(pool *pgxpool.Pool, queries *db.Queries) repository.RoomRepository {
+	return &RoomRepository{pool, queries}
 }
 
 func mapRoom(room db.Room) model.Room {
 	return model.Room{
-		ID:   room.ID,
-		Name: room.Name.String,
-		Slug: room.Slug,
+		ID:         room.ID,
+		Name:       room.Name.String,
+		Slug:       room.Slug,
+		Visibility: model.RoomVisibility(room.Visibility),
+		MaxPins:    intOrNil(room.MaxPins),
 	}
 }
 
@@ -43,18 +51,108 @@ func (r *RoomRepository) ListUserRooms(ctx context.Context, userID uuid.UUID) ([
 	return mapRooms(rooms), nil
 }
 
-func (r *RoomRepository) Create(ctx context.Context, room model.Room) (model.Room, error) {
-	createdRoom, err := r.queries.CreateRoom(ctx, db.CreateRoomParams{
-		ID:        room.ID,
-		Name:      textFromString(room.Name),
-		Slug:      room.Slug,
-		CreatedAt: timestampFromTime(room.CreatedAt),
+func (r *RoomRepository) ListUserRoomsWithReadState(ctx context.Context, userID uuid.UUID) ([]model.Room, error) {
+	rows, err := r.queries.GetUserRoomsWithReadState(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms := make([]model.Room, len(rows))
+	for i, row := range rows {
+		rooms[i] = model.Room{
+			ID:         row.ID,
+			Name:       row.Name.String,
+			Slug:       row.Slug,
+			Visibility: model.RoomVisibility(row.Visibility),
+			Role:       model.RoomRole(row.Role),
+			ReadState: &model.RoomReadState{
+				RoomID:            row.ID,
+				LastReadMessageID: uuidOrNil(row.LastReadMessageID),
+				UnreadCount:       int(row.UnreadCount),
+				MentionCount:      int(row.MentionCount),
+			},
+		}
+	}
+	return rooms, nil
+}
+
+func (r *RoomRepository) GetReadState(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (model.RoomReadState, error) {
+	row, err := r.queries.GetRoomReadState(ctx, db.GetRoomReadStateParams{
+		RoomID: roomID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.RoomReadState{}, repository.ErrRoomNotFound
+		}
+		return model.RoomReadState{}, err
+	}
+
+	return model.RoomReadState{
+		RoomID:            roomID,
+		LastReadMessageID: uuidOrNil(row.LastReadMessageID),
+		UnreadCount:       int(row.UnreadCount),
+		MentionCount:      int(row.MentionCount),
+	}, nil
+}
+
+func (r *RoomRepository) AdvanceReadMarker(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, cursor pagination.Cursor) (bool, error) {
+	rows, err := r.queries.AdvanceReadMarker(ctx, db.AdvanceReadMarkerParams{
+		MessageID:        cursor.ID,
+		MessageCreatedAt: timestampFromTime(cursor.CreatedAt),
+		RoomID:           roomID,
+		UserID:           userID,
 	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *RoomRepository) Create(ctx context.Context, room model.Room, ownerID uuid.UUID) (model.Room, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return model.Room{}, err
 	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	createdRoom, err := qtx.CreateRoom(ctx, db.CreateRoomParams{
+		ID:         room.ID,
+		Name:       textFromString(room.Name),
+		Slug:       room.Slug,
+		Visibility: string(room.Visibility),
+		CreatedAt:  timestampFromTime(room.CreatedAt),
+	})
+	if err != nil {
+		return model.Room{}, err
+	}
+
+	if err := addMember(ctx, qtx, model.NewRoomMember(ownerID, room.ID, model.RoomRoleOwner)); err != nil {
+		return model.Room{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Room{}, err
+	}
 	return mapRoom(createdRoom), nil
+}
 
+func (r *RoomRepository) Update(ctx context.Context, room model.Room) (model.Room, error) {
+	updated, err := r.queries.UpdateRoom(ctx, db.UpdateRoomParams{
+		ID:         room.ID,
+		Name:       textFromString(room.Name),
+		Visibility: string(room.Visibility),
+		MaxPins:    int4OrNull(room.MaxPins),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Room{}, repository.ErrRoomNotFound
+		}
+		return model.Room{}, err
+	}
+	return mapRoom(updated), nil
 }
 
 func (r *RoomRepository) RoomExists(ctx context.Context, id uuid.UUID) (bool, error) {
@@ -62,16 +160,30 @@ func (r *RoomRepository) RoomExists(ctx context.Context, id uuid.UUID) (bool, er
 }
 
 func (r *RoomRepository) AddMember(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) error {
-	member := model.NewRoomMember(roomID, userID)
+	return addMember(ctx, r.queries, model.NewRoomMember(userID, roomID, model.RoomRoleMember))
+}
 
-	return r.queries.AddRoomMember(ctx, db.AddRoomMemberParams{
+func addMember(ctx context.Context, queries *db.Queries, member model.RoomMember) error {
+	return queries.AddRoomMember(ctx, db.AddRoomMemberParams{
 		ID:       member.ID,
 		RoomID:   member.RoomID,
 		UserID:   member.UserID,
+		Role:     string(member.Role),
 		JoinedAt: timestampFromTime(member.JoinedAt),
 	})
 }
 
+func (r *RoomRepository) Get(ctx context.Context, id uuid.UUID) (model.Room, error) {
+	room, err := r.queries.GetRoom(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Room{}, repository.ErrRoomNotFound
+		}
+		return model.Room{}, err
+	}
+	return mapRoom(room), nil
+}
+
 func (r *RoomRepository) GetBySlug(ctx context.Context, slug string) (model.Room, error) {
 	room, err := r.queries.GetRoomBySlug(ctx, slug)
 	if err != nil {
@@ -82,3 +194,301 @@ func (r *RoomRepository) GetBySlug(ctx context.Context, slug string) (model.Room
 	}
 	return mapRoom(room), nil
 }
+
+func (r *RoomRepository) IsMember(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (bool, error) {
+	return r.queries.IsUserRoomMember(ctx, db.IsUserRoomMemberParams{
+		RoomID: roomID,
+		UserID: userID,
+	})
+}
+
+func (r *RoomRepository) RemoveMember(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) error {
+	return removeMember(ctx, r.queries, roomID, userID)
+}
+
+func removeMember(ctx context.Context, queries *db.Queries, roomID uuid.UUID, userID uuid.UUID) error {
+	rows, err := queries.RemoveRoomMember(ctx, db.RemoveRoomMemberParams{
+		RoomID: roomID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrRoomMemberNotFound
+	}
+	return nil
+}
+
+func (r *RoomRepository) BanMember(ctx context.Context, ban model.RoomBan) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	// Users who already left can be banned too.
+	err = removeMember(ctx, qtx, ban.RoomID, ban.UserID)
+	if err != nil && !errors.Is(err, repository.ErrRoomMemberNotFound) {
+		return err
+	}
+
+	err = qtx.UpsertRoomBan(ctx, db.UpsertRoomBanParams{
+		RoomID:    ban.RoomID,
+		UserID:    ban.UserID,
+		BannedBy:  uuidOrNull(ban.BannedBy),
+		Reason:    ban.Reason,
+		ExpiresAt: timestampOrNull(ban.ExpiresAt),
+		CreatedAt: timestampFromTime(ban.CreatedAt),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return repository.ErrUserNotFound
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *RoomRepository) GetActiveBan(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, now time.Time) (model.RoomBan, error) {
+	ban, err := r.queries.GetActiveRoomBan(ctx, db.GetActiveRoomBanParams{
+		RoomID: roomID,
+		UserID: userID,
+		Now:    timestampFromTime(now),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.RoomBan{}, repository.ErrBanNotFound
+		}
+		return model.RoomBan{}, err
+	}
+
+	return model.RoomBan{
+		RoomID:    ban.RoomID,
+		UserID:    ban.UserID,
+		BannedBy:  uuidOrNil(ban.BannedBy),
+		Reason:    ban.Reason,
+		ExpiresAt: timeOrNil(ban.ExpiresAt),
+		CreatedAt: ban.CreatedAt.Time,
+	}, nil
+}
+
+func (r *RoomRepository) Unban(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) error {
+	rows, err := r.queries.DeleteRoomBan(ctx, db.DeleteRoomBanParams{
+		RoomID: roomID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrBanNotFound
+	}
+	return nil
+}
+
+func (r *RoomRepository) GetMemberRole(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (model.RoomRole, error) {
+	role, err := r.queries.GetRoomMemberRole(ctx, db.GetRoomMemberRoleParams{
+		RoomID: roomID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", repository.ErrRoomMemberNotFound
+		}
+		return "", err
+	}
+	return model.RoomRole(role), nil
+}
+
+func (r *RoomRepository) SetMemberRole(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, role model.RoomRole) error {
+	return setMemberRole(ctx, r.queries, roomID, userID, role)
+}
+
+func (r *RoomRepository) TransferOwnership(ctx context.Context, roomID uuid.UUID, ownerID uuid.UUID, newOwnerID uuid.UUID) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	// The old owner steps down first; a room can't have two owners.
+	if err := setMemberRole(ctx, qtx, roomID, ownerID, model.RoomRoleAdmin); err != nil {
+		return err
+	}
+	if err := setMemberRole(ctx, qtx, roomID, newOwnerID, model.RoomRoleOwner); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func setMemberRole(ctx context.Context, queries *db.Queries, roomID uuid.UUID, userID uuid.UUID, role model.RoomRole) error {
+	rows, err := queries.UpdateRoomMemberRole(ctx, db.UpdateRoomMemberRoleParams{
+		RoomID: roomID,
+		UserID: userID,
+		Role:   string(role),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrRoomMemberNotFound
+	}
+	return nil
+}
+
+func (r *RoomRepository) ListMemberIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error) {
+	return r.queries.ListRoomMemberIDs(ctx, roomID)
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *RoomRepository) ListMembers(ctx context.Context, roomID uuid.UUID, usernamePrefix string, limit int, cursor *pagination.Cursor) ([]model.RoomMemberProfile, error) {
+	params := db.ListRoomMembersParams{
+		RoomID:          roomID,
+		UsernamePattern: likeEscaper.Replace(strings.ToLower(usernamePrefix)) + "%",
+		Limit:           int32(limit),
+	}
+
+	if cursor != nil {
+		params.CursorUserID = cursor.ID
+		params.CursorJoinedAt = timestampFromTime(cursor.CreatedAt)
+	}
+
+	rows, err := r.queries.ListRoomMembers(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]model.RoomMemberProfile, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, model.RoomMemberProfile{
+			UserID:    row.UserID,
+			Username:  row.Username,
+			AvatarURL: textOrEmpty(row.AvatarUrl),
+			Role:      model.RoomRole(row.Role),
+			JoinedAt:  row.JoinedAt.Time,
+		})
+	}
+	return members, nil
+}
+
+func mapRoomPin(r db.ListRoomPinsRow) model.RoomPin {
+	pin := model.RoomPin{
+		Message: model.Message{
+			ID:        r.ID,
+			RoomID:    r.RoomID,
+			Content:   r.Content,
+			CreatedAt: r.CreatedAt.Time,
+			EditedAt:  timeOrNil(r.EditedAt),
+			ThreadID:  uuidOrNil(r.ThreadID),
+			Sender: model.MessageSender{
+				ID:        r.SenderID,
+				Username:  r.SenderUsername,
+				AvatarURL: textOrEmpty(r.SenderAvatarUrl),
+			},
+		},
+		PinnedAt: r.PinnedAt.Time,
+	}
+
+	if r.PinnedByID.Valid {
+		pin.PinnedBy = &model.MessageSender{
+			ID:        r.PinnedByID.Bytes,
+			Username:  textOrEmpty(r.PinnedByUsername),
+			AvatarURL: textOrEmpty(r.PinnedByAvatarUrl),
+		}
+	}
+	return pin
+}
+
+func (r *RoomRepository) PinMessage(ctx context.Context, roomID uuid.UUID, messageID uuid.UUID, pinnedBy uuid.UUID, maxPins int) (bool, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	// Lock the room so concurrent pins count each other against the limit.
+	if _, err := qtx.LockRoom(ctx, roomID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, repository.ErrRoomNotFound
+		}
+		return false, err
+	}
+
+	rows, err := qtx.PinMessage(ctx, db.PinMessageParams{
+		RoomID:    roomID,
+		MessageID: messageID,
+		PinnedBy:  pinnedBy,
+		PinnedAt:  timestampFromTime(time.Now()),
+		MaxPins:   int32(maxPins),
+	})
+	if err != nil {
+		return false, err
+	}
+	if rows > 0 {
+		return true, tx.Commit(ctx)
+	}
+
+	// Nothing was inserted either because the message is pinned already or
+	// because the room is at the limit.
+	pinned, err := qtx.IsMessagePinned(ctx, db.IsMessagePinnedParams{
+		RoomID:    roomID,
+		MessageID: messageID,
+	})
+	if err != nil {
+		return false, err
+	}
+	if !pinned {
+		return false, model.ErrTooManyPins
+	}
+	return false, nil
+}
+
+func (r *RoomRepository) UnpinMessage(ctx context.Context, roomID uuid.UUID, messageID uuid.UUID) (bool, error) {
+	rows, err := r.queries.UnpinMessage(ctx, db.UnpinMessageParams{
+		RoomID:    roomID,
+		MessageID: messageID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *RoomRepository) GetPin(ctx context.Context, roomID uuid.UUID, messageID uuid.UUID) (model.RoomPin, error) {
+	row, err := r.queries.GetRoomPin(ctx, db.GetRoomPinParams{
+		RoomID:    roomID,
+		MessageID: messageID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.RoomPin{}, repository.ErrPinNotFound
+		}
+		return model.RoomPin{}, err
+	}
+	return mapRoomPin(db.ListRoomPinsRow(row)), nil
+}
+
+func (r *RoomRepository) ListPins(ctx context.Context, roomID uuid.UUID) ([]model.RoomPin, error) {
+	rows, err := r.queries.ListRoomPins(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	pins := make([]model.RoomPin, len(rows))
+	for i, row := range rows {
+		pins[i] = mapRoomPin(row)
+	}
+	return pins, nil
+}
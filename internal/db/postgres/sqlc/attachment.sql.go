@@ -0,0 +1,269 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: attachment.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimAttachments = `-- name: ClaimAttachments :execrows
+UPDATE attachments
+SET message_id = $1::uuid
+WHERE id = ANY ($2::uuid[])
+  AND room_id = $3::uuid
+  AND uploader_id = $4::uuid
+  AND message_id IS NULL
+`
+
+type ClaimAttachmentsParams struct {
+	MessageID  uuid.UUID   `db:"message_id" json:"messageId"`
+	Ids        []uuid.UUID `db:"ids" json:"ids"`
+	RoomID     uuid.UUID   `db:"room_id" json:"roomId"`
+	UploaderID uuid.UUID   `db:"uploader_id" json:"uploaderId"`
+}
+
+func (q *Queries) ClaimAttachments(ctx context.Context, arg ClaimAttachmentsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, claimAttachments,
+		arg.MessageID,
+		arg.Ids,
+		arg.RoomID,
+		arg.UploaderID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createAttachment = `-- name: CreateAttachment :one
+INSERT INTO attachments (id, room_id, uploader_id, name, content_type, size, width, height, storage_key,
+                         thumbnail_key, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, room_id, uploader_id, message_id, name, content_type, size, width, height, storage_key, thumbnail_key, created_at
+`
+
+type CreateAttachmentParams struct {
+	ID           uuid.UUID          `db:"id" json:"id"`
+	RoomID       uuid.UUID          `db:"room_id" json:"roomId"`
+	UploaderID   uuid.UUID          `db:"uploader_id" json:"uploaderId"`
+	Name         string             `db:"name" json:"name"`
+	ContentType  string             `db:"content_type" json:"contentType"`
+	Size         int64              `db:"size" json:"size"`
+	Width        pgtype.Int4        `db:"width" json:"width"`
+	Height       pgtype.Int4        `db:"height" json:"height"`
+	StorageKey   string             `db:"storage_key" json:"storageKey"`
+	ThumbnailKey pgtype.Text        `db:"thumbnail_key" json:"thumbnailKey"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+}
+
+func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error) {
+	row := q.db.QueryRow(ctx, createAttachment,
+		arg.ID,
+		arg.RoomID,
+		arg.UploaderID,
+		arg.Name,
+		arg.ContentType,
+		arg.Size,
+		arg.Width,
+		arg.Height,
+		arg.StorageKey,
+		arg.ThumbnailKey,
+		arg.CreatedAt,
+	)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.UploaderID,
+		&i.MessageID,
+		&i.Name,
+		&i.ContentType,
+		&i.Size,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteExpiredAttachments = `-- name: DeleteExpiredAttachments :many
+DELETE
+FROM attachments
+WHERE id IN (SELECT a.id
+             FROM attachments a
+                      LEFT JOIN messages m ON m.id = a.message_id
+             WHERE (a.message_id IS NULL AND a.created_at < $1::timestamptz)
+                OR m.deleted_at < $2::timestamptz
+             LIMIT $3 FOR UPDATE OF a SKIP LOCKED)
+RETURNING id, room_id, uploader_id, message_id, name, content_type, size, width, height, storage_key, thumbnail_key, created_at
+`
+
+type DeleteExpiredAttachmentsParams struct {
+	UnsentBefore  pgtype.Timestamptz `db:"unsent_before" json:"unsentBefore"`
+	DeletedBefore pgtype.Timestamptz `db:"deleted_before" json:"deletedBefore"`
+	Limit         int32              `db:"limit_" json:"limit"`
+}
+
+func (q *Queries) DeleteExpiredAttachments(ctx context.Context, arg DeleteExpiredAttachmentsParams) ([]Attachment, error) {
+	rows, err := q.db.Query(ctx, deleteExpiredAttachments, arg.UnsentBefore, arg.DeletedBefore, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Attachment{}
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.UploaderID,
+			&i.MessageID,
+			&i.Name,
+			&i.ContentType,
+			&i.Size,
+			&i.Width,
+			&i.Height,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttachment = `-- name: GetAttachment :one
+SELECT a.id, a.room_id, a.uploader_id, a.message_id, a.name, a.content_type, a.size, a.width, a.height, a.storage_key, a.thumbnail_key, a.created_at, m.deleted_at AS message_deleted_at
+FROM attachments a
+         LEFT JOIN messages m ON m.id = a.message_id
+WHERE a.id = $1
+`
+
+type GetAttachmentRow struct {
+	ID               uuid.UUID          `db:"id" json:"id"`
+	RoomID           uuid.UUID          `db:"room_id" json:"roomId"`
+	UploaderID       uuid.UUID          `db:"uploader_id" json:"uploaderId"`
+	MessageID        pgtype.UUID        `db:"message_id" json:"messageId"`
+	Name             string             `db:"name" json:"name"`
+	ContentType      string             `db:"content_type" json:"contentType"`
+	Size             int64              `db:"size" json:"size"`
+	Width            pgtype.Int4        `db:"width" json:"width"`
+	Height           pgtype.Int4        `db:"height" json:"height"`
+	StorageKey       string             `db:"storage_key" json:"storageKey"`
+	ThumbnailKey     pgtype.Text        `db:"thumbnail_key" json:"thumbnailKey"`
+	CreatedAt        pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	MessageDeletedAt pgtype.Timestamptz `db:"message_deleted_at" json:"messageDeletedAt"`
+}
+
+func (q *Queries) GetAttachment(ctx context.Context, id uuid.UUID) (GetAttachmentRow, error) {
+	row := q.db.QueryRow(ctx, getAttachment, id)
+	var i GetAttachmentRow
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.UploaderID,
+		&i.MessageID,
+		&i.Name,
+		&i.ContentType,
+		&i.Size,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+		&i.CreatedAt,
+		&i.MessageDeletedAt,
+	)
+	return i, err
+}
+
+const listAttachmentsByIDs = `-- name: ListAttachmentsByIDs :many
+SELECT id, room_id, uploader_id, message_id, name, content_type, size, width, height, storage_key, thumbnail_key, created_at
+FROM attachments
+WHERE id = ANY ($1::uuid[])
+`
+
+func (q *Queries) ListAttachmentsByIDs(ctx context.Context, ids []uuid.UUID) ([]Attachment, error) {
+	rows, err := q.db.Query(ctx, listAttachmentsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Attachment{}
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.UploaderID,
+			&i.MessageID,
+			&i.Name,
+			&i.ContentType,
+			&i.Size,
+			&i.Width,
+			&i.Height,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessagesAttachments = `-- name: ListMessagesAttachments :many
+SELECT id, room_id, uploader_id, message_id, name, content_type, size, width, height, storage_key, thumbnail_key, created_at
+FROM attachments
+WHERE message_id = ANY ($1::uuid[])
+ORDER BY message_id, created_at, id
+`
+
+func (q *Queries) ListMessagesAttachments(ctx context.Context, messageIds []uuid.UUID) ([]Attachment, error) {
+	rows, err := q.db.Query(ctx, listMessagesAttachments, messageIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Attachment{}
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.UploaderID,
+			&i.MessageID,
+			&i.Name,
+			&i.ContentType,
+			&i.Size,
+			&i.Width,
+			&i.Height,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,92 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: ban.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteRoomBan = `-- name: DeleteRoomBan :execrows
+DELETE
+FROM room_bans
+WHERE room_id = $1
+  AND user_id = $2
+`
+
+type DeleteRoomBanParams struct {
+	RoomID uuid.UUID `db:"room_id" json:"roomId"`
+	UserID uuid.UUID `db:"user_id" json:"userId"`
+}
+
+func (q *Queries) DeleteRoomBan(ctx context.Context, arg DeleteRoomBanParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRoomBan, arg.RoomID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getActiveRoomBan = `-- name: GetActiveRoomBan :one
+SELECT room_id, user_id, banned_by, reason, expires_at, created_at
+FROM room_bans
+WHERE room_id = $1
+  AND user_id = $2
+  AND (expires_at IS NULL OR expires_at > $3::timestamptz)
+`
+
+type GetActiveRoomBanParams struct {
+	RoomID uuid.UUID          `db:"room_id" json:"roomId"`
+	UserID uuid.UUID          `db:"user_id" json:"userId"`
+	Now    pgtype.Timestamptz `db:"now" json:"now"`
+}
+
+func (q *Queries) GetActiveRoomBan(ctx context.Context, arg GetActiveRoomBanParams) (RoomBan, error) {
+	row := q.db.QueryRow(ctx, getActiveRoomBan, arg.RoomID, arg.UserID, arg.Now)
+	var i RoomBan
+	err := row.Scan(
+		&i.RoomID,
+		&i.UserID,
+		&i.BannedBy,
+		&i.Reason,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertRoomBan = `-- name: UpsertRoomBan :exec
+INSERT INTO room_bans (room_id, user_id, banned_by, reason, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (room_id, user_id) DO UPDATE
+    SET banned_by  = excluded.banned_by,
+        reason     = excluded.reason,
+        expires_at = excluded.expires_at,
+        created_at = excluded.created_at
+`
+
+type UpsertRoomBanParams struct {
+	RoomID    uuid.UUID          `db:"room_id" json:"roomId"`
+	UserID    uuid.UUID          `db:"user_id" json:"userId"`
+	BannedBy  pgtype.UUID        `db:"banned_by" json:"bannedBy"`
+	Reason    string             `db:"reason" json:"reason"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+}
+
+func (q *Queries) UpsertRoomBan(ctx context.Context, arg UpsertRoomBanParams) error {
+	_, err := q.db.Exec(ctx, upsertRoomBan,
+		arg.RoomID,
+		arg.UserID,
+		arg.BannedBy,
+		arg.Reason,
+		arg.ExpiresAt,
+		arg.CreatedAt,
+	)
+	return err
+}
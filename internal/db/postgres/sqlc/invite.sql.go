@@ -0,0 +1,156 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: invite.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createRoomInvite = `-- name: CreateRoomInvite :one
+INSERT INTO room_invites (id, room_id, code, created_by, max_uses, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, room_id, code, created_by, max_uses, uses, expires_at, revoked_at, created_at
+`
+
+type CreateRoomInviteParams struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	RoomID    uuid.UUID          `db:"room_id" json:"roomId"`
+	Code      string             `db:"code" json:"code"`
+	CreatedBy uuid.UUID          `db:"created_by" json:"createdBy"`
+	MaxUses   pgtype.Int4        `db:"max_uses" json:"maxUses"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+}
+
+func (q *Queries) CreateRoomInvite(ctx context.Context, arg CreateRoomInviteParams) (RoomInvite, error) {
+	row := q.db.QueryRow(ctx, createRoomInvite,
+		arg.ID,
+		arg.RoomID,
+		arg.Code,
+		arg.CreatedBy,
+		arg.MaxUses,
+		arg.ExpiresAt,
+		arg.CreatedAt,
+	)
+	var i RoomInvite
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Code,
+		&i.CreatedBy,
+		&i.MaxUses,
+		&i.Uses,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRoomInviteByCodeForUpdate = `-- name: GetRoomInviteByCodeForUpdate :one
+SELECT id, room_id, code, created_by, max_uses, uses, expires_at, revoked_at, created_at
+FROM room_invites
+WHERE code = $1
+    FOR UPDATE
+`
+
+func (q *Queries) GetRoomInviteByCodeForUpdate(ctx context.Context, code string) (RoomInvite, error) {
+	row := q.db.QueryRow(ctx, getRoomInviteByCodeForUpdate, code)
+	var i RoomInvite
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Code,
+		&i.CreatedBy,
+		&i.MaxUses,
+		&i.Uses,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const incrementRoomInviteUses = `-- name: IncrementRoomInviteUses :exec
+UPDATE room_invites
+SET uses = uses + 1
+WHERE id = $1
+`
+
+func (q *Queries) IncrementRoomInviteUses(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, incrementRoomInviteUses, id)
+	return err
+}
+
+const listActiveRoomInvites = `-- name: ListActiveRoomInvites :many
+SELECT id, room_id, code, created_by, max_uses, uses, expires_at, revoked_at, created_at
+FROM room_invites
+WHERE room_id = $1
+  AND revoked_at IS NULL
+  AND expires_at > $2::timestamptz
+  AND (max_uses IS NULL OR uses < max_uses)
+ORDER BY created_at DESC
+`
+
+type ListActiveRoomInvitesParams struct {
+	RoomID uuid.UUID          `db:"room_id" json:"roomId"`
+	Now    pgtype.Timestamptz `db:"now" json:"now"`
+}
+
+func (q *Queries) ListActiveRoomInvites(ctx context.Context, arg ListActiveRoomInvitesParams) ([]RoomInvite, error) {
+	rows, err := q.db.Query(ctx, listActiveRoomInvites, arg.RoomID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RoomInvite{}
+	for rows.Next() {
+		var i RoomInvite
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Code,
+			&i.CreatedBy,
+			&i.MaxUses,
+			&i.Uses,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeRoomInvite = `-- name: RevokeRoomInvite :execrows
+UPDATE room_invites
+SET revoked_at = $3
+WHERE id = $1
+  AND room_id = $2
+  AND revoked_at IS NULL
+`
+
+type RevokeRoomInviteParams struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	RoomID    uuid.UUID          `db:"room_id" json:"roomId"`
+	RevokedAt pgtype.Timestamptz `db:"revoked_at" json:"revokedAt"`
+}
+
+func (q *Queries) RevokeRoomInvite(ctx context.Context, arg RevokeRoomInviteParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeRoomInvite, arg.ID, arg.RoomID, arg.RevokedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
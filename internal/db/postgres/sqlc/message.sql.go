@@ -13,9 +13,9 @@ import (
 )
 
 const createMessage = `-- name: CreateMessage :one
-INSERT INTO messages(id, room_id, sender_id, content, created_at)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, room_id, sender_id, content, created_at
+INSERT INTO messages(id, room_id, sender_id, content, created_at, reply_to_id, thread_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, room_id, sender_id, content, created_at, edited_at, deleted_at, deleted_by, reply_to_id, thread_id
 `
 
 type CreateMessageParams struct {
@@ -24,6 +24,8 @@ type CreateMessageParams struct {
 	SenderID  uuid.UUID          `db:"sender_id" json:"senderId"`
 	Content   string             `db:"content" json:"content"`
 	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	ReplyToID pgtype.UUID        `db:"reply_to_id" json:"replyToId"`
+	ThreadID  pgtype.UUID        `db:"thread_id" json:"threadId"`
 }
 
 func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error) {
@@ -33,6 +35,8 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		arg.SenderID,
 		arg.Content,
 		arg.CreatedAt,
+		arg.ReplyToID,
+		arg.ThreadID,
 	)
 	var i Message
 	err := row.Scan(
@@ -41,15 +45,224 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		&i.SenderID,
 		&i.Content,
 		&i.CreatedAt,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ReplyToID,
+		&i.ThreadID,
 	)
 	return i, err
 }
 
+const createMessageRevision = `-- name: CreateMessageRevision :exec
+INSERT INTO message_revisions (id, message_id, content, edited_at)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateMessageRevisionParams struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	MessageID uuid.UUID          `db:"message_id" json:"messageId"`
+	Content   string             `db:"content" json:"content"`
+	EditedAt  pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
+}
+
+func (q *Queries) CreateMessageRevision(ctx context.Context, arg CreateMessageRevisionParams) error {
+	_, err := q.db.Exec(ctx, createMessageRevision,
+		arg.ID,
+		arg.MessageID,
+		arg.Content,
+		arg.EditedAt,
+	)
+	return err
+}
+
+const getMessage = `-- name: GetMessage :one
+SELECT m.id, m.room_id, m.sender_id, m.content, m.created_at, m.edited_at, m.deleted_at, m.deleted_by, m.reply_to_id, m.thread_id,
+       u.id, u.username, u.email, u.email_verified, u.password_hash, u.created_at, u.avatar_url, u.share_read_receipts,
+       p.content     AS parent_content,
+       p.deleted_at  AS parent_deleted_at,
+       pu.id         AS parent_sender_id,
+       pu.username   AS parent_sender_username,
+       pu.avatar_url AS parent_sender_avatar_url
+FROM messages m
+         JOIN users u ON u.id = m.sender_id
+         LEFT JOIN messages p ON p.id = m.reply_to_id
+         LEFT JOIN users pu ON pu.id = p.sender_id
+WHERE m.id = $1
+`
+
+type GetMessageRow struct {
+	ID                    uuid.UUID          `db:"id" json:"id"`
+	RoomID                uuid.UUID          `db:"room_id" json:"roomId"`
+	SenderID              uuid.UUID          `db:"sender_id" json:"senderId"`
+	Content               string             `db:"content" json:"content"`
+	CreatedAt             pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	EditedAt              pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
+	DeletedAt             pgtype.Timestamptz `db:"deleted_at" json:"deletedAt"`
+	DeletedBy             pgtype.UUID        `db:"deleted_by" json:"deletedBy"`
+	ReplyToID             pgtype.UUID        `db:"reply_to_id" json:"replyToId"`
+	ThreadID              pgtype.UUID        `db:"thread_id" json:"threadId"`
+	ID_2                  uuid.UUID          `db:"id_2" json:"id2"`
+	Username              string             `db:"username" json:"username"`
+	Email                 string             `db:"email" json:"email"`
+	EmailVerified         bool               `db:"email_verified" json:"emailVerified"`
+	PasswordHash          pgtype.Text        `db:"password_hash" json:"passwordHash"`
+	CreatedAt_2           pgtype.Timestamptz `db:"created_at_2" json:"createdAt2"`
+	AvatarUrl             pgtype.Text        `db:"avatar_url" json:"avatarUrl"`
+	ShareReadReceipts     bool               `db:"share_read_receipts" json:"shareReadReceipts"`
+	ParentContent         pgtype.Text        `db:"parent_content" json:"parentContent"`
+	ParentDeletedAt       pgtype.Timestamptz `db:"parent_deleted_at" json:"parentDeletedAt"`
+	ParentSenderID        pgtype.UUID        `db:"parent_sender_id" json:"parentSenderId"`
+	ParentSenderUsername  pgtype.Text        `db:"parent_sender_username" json:"parentSenderUsername"`
+	ParentSenderAvatarUrl pgtype.Text        `db:"parent_sender_avatar_url" json:"parentSenderAvatarUrl"`
+}
+
+func (q *Queries) GetMessage(ctx context.Context, id uuid.UUID) (GetMessageRow, error) {
+	row := q.db.QueryRow(ctx, getMessage, id)
+	var i GetMessageRow
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.SenderID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ReplyToID,
+		&i.ThreadID,
+		&i.ID_2,
+		&i.Username,
+		&i.Email,
+		&i.EmailVerified,
+		&i.PasswordHash,
+		&i.CreatedAt_2,
+		&i.AvatarUrl,
+		&i.ShareReadReceipts,
+		&i.ParentContent,
+		&i.ParentDeletedAt,
+		&i.ParentSenderID,
+		&i.ParentSenderUsername,
+		&i.ParentSenderAvatarUrl,
+	)
+	return i, err
+}
+
+const getMessagesAfter = `-- name: GetMessagesAfter :many
+SELECT m.id, m.room_id, m.sender_id, m.content, m.created_at, m.edited_at, m.deleted_at, m.deleted_by, m.reply_to_id, m.thread_id,
+       u.id, u.username, u.email, u.email_verified, u.password_hash, u.created_at, u.avatar_url, u.share_read_receipts,
+       p.content     AS parent_content,
+       p.deleted_at  AS parent_deleted_at,
+       pu.id         AS parent_sender_id,
+       pu.username   AS parent_sender_username,
+       pu.avatar_url AS parent_sender_avatar_url
+FROM messages m
+         JOIN users u ON u.id = m.sender_id
+         LEFT JOIN messages p ON p.id = m.reply_to_id
+         LEFT JOIN users pu ON pu.id = p.sender_id
+WHERE m.room_id = $1::uuid
+  AND m.thread_id IS NULL
+  AND (m.created_at, m.id) > ($2::timestamptz, $3::uuid)
+ORDER BY m.created_at, m.id
+LIMIT $4
+`
+
+type GetMessagesAfterParams struct {
+	RoomID          uuid.UUID          `db:"room_id" json:"roomId"`
+	CursorCreatedAt pgtype.Timestamptz `db:"cursor_created_at" json:"cursorCreatedAt"`
+	CursorID        uuid.UUID          `db:"cursor_id" json:"cursorId"`
+	Limit           int32              `db:"limit_" json:"limit"`
+}
+
+type GetMessagesAfterRow struct {
+	ID                    uuid.UUID          `db:"id" json:"id"`
+	RoomID                uuid.UUID          `db:"room_id" json:"roomId"`
+	SenderID              uuid.UUID          `db:"sender_id" json:"senderId"`
+	Content               string             `db:"content" json:"content"`
+	CreatedAt             pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	EditedAt              pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
+	DeletedAt             pgtype.Timestamptz `db:"deleted_at" json:"deletedAt"`
+	DeletedBy             pgtype.UUID        `db:"deleted_by" json:"deletedBy"`
+	ReplyToID             pgtype.UUID        `db:"reply_to_id" json:"replyToId"`
+	ThreadID              pgtype.UUID        `db:"thread_id" json:"threadId"`
+	ID_2                  uuid.UUID          `db:"id_2" json:"id2"`
+	Username              string             `db:"username" json:"username"`
+	Email                 string             `db:"email" json:"email"`
+	EmailVerified         bool               `db:"email_verified" json:"emailVerified"`
+	PasswordHash          pgtype.Text        `db:"password_hash" json:"passwordHash"`
+	CreatedAt_2           pgtype.Timestamptz `db:"created_at_2" json:"createdAt2"`
+	AvatarUrl             pgtype.Text        `db:"avatar_url" json:"avatarUrl"`
+	ShareReadReceipts     bool               `db:"share_read_receipts" json:"shareReadReceipts"`
+	ParentContent         pgtype.Text        `db:"parent_content" json:"parentContent"`
+	ParentDeletedAt       pgtype.Timestamptz `db:"parent_deleted_at" json:"parentDeletedAt"`
+	ParentSenderID        pgtype.UUID        `db:"parent_sender_id" json:"parentSenderId"`
+	ParentSenderUsername  pgtype.Text        `db:"parent_sender_username" json:"parentSenderUsername"`
+	ParentSenderAvatarUrl pgtype.Text        `db:"parent_sender_avatar_url" json:"parentSenderAvatarUrl"`
+}
+
+func (q *Queries) GetMessagesAfter(ctx context.Context, arg GetMessagesAfterParams) ([]GetMessagesAfterRow, error) {
+	rows, err := q.db.Query(ctx, getMessagesAfter,
+		arg.RoomID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetMessagesAfterRow{}
+	for rows.Next() {
+		var i GetMessagesAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.SenderID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ReplyToID,
+			&i.ThreadID,
+			&i.ID_2,
+			&i.Username,
+			&i.Email,
+			&i.EmailVerified,
+			&i.PasswordHash,
+			&i.CreatedAt_2,
+			&i.AvatarUrl,
+			&i.ShareReadReceipts,
+			&i.ParentContent,
+			&i.ParentDeletedAt,
+			&i.ParentSenderID,
+			&i.ParentSenderUsername,
+			&i.ParentSenderAvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMessagesPaging = `-- name: GetMessagesPaging :many
-SELECT m.id, m.room_id, m.sender_id, m.content, m.created_at, u.id, u.username, u.email, u.email_verified, u.password_hash, u.created_at, u.avatar_url
+SELECT m.id, m.room_id, m.sender_id, m.content, m.created_at, m.edited_at, m.deleted_at, m.deleted_by, m.reply_to_id, m.thread_id,
+       u.id, u.username, u.email, u.email_verified, u.password_hash, u.created_at, u.avatar_url, u.share_read_receipts,
+       p.content     AS parent_content,
+       p.deleted_at  AS parent_deleted_at,
+       pu.id         AS parent_sender_id,
+       pu.username   AS parent_sender_username,
+       pu.avatar_url AS parent_sender_avatar_url
 FROM messages m
          JOIN users u ON u.id = m.sender_id
+         LEFT JOIN messages p ON p.id = m.reply_to_id
+         LEFT JOIN users pu ON pu.id = p.sender_id
 WHERE m.room_id = $1::uuid
+  AND m.thread_id IS NULL
   AND (
       $2::timestamptz IS NULL
       OR
@@ -67,18 +280,29 @@ type GetMessagesPagingParams struct {
 }
 
 type GetMessagesPagingRow struct {
-	ID            uuid.UUID          `db:"id" json:"id"`
-	RoomID        uuid.UUID          `db:"room_id" json:"roomId"`
-	SenderID      uuid.UUID          `db:"sender_id" json:"senderId"`
-	Content       string             `db:"content" json:"content"`
-	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"createdAt"`
-	ID_2          uuid.UUID          `db:"id_2" json:"id2"`
-	Username      string             `db:"username" json:"username"`
-	Email         string             `db:"email" json:"email"`
-	EmailVerified bool               `db:"email_verified" json:"emailVerified"`
-	PasswordHash  pgtype.Text        `db:"password_hash" json:"passwordHash"`
-	CreatedAt_2   pgtype.Timestamptz `db:"created_at_2" json:"createdAt2"`
-	AvatarUrl     pgtype.Text        `db:"avatar_url" json:"avatarUrl"`
+	ID                    uuid.UUID          `db:"id" json:"id"`
+	RoomID                uuid.UUID          `db:"room_id" json:"roomId"`
+	SenderID              uuid.UUID          `db:"sender_id" json:"senderId"`
+	Content               string             `db:"content" json:"content"`
+	CreatedAt             pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	EditedAt              pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
+	DeletedAt             pgtype.Timestamptz `db:"deleted_at" json:"deletedAt"`
+	DeletedBy             pgtype.UUID        `db:"deleted_by" json:"deletedBy"`
+	ReplyToID             pgtype.UUID        `db:"reply_to_id" json:"replyToId"`
+	ThreadID              pgtype.UUID        `db:"thread_id" json:"threadId"`
+	ID_2                  uuid.UUID          `db:"id_2" json:"id2"`
+	Username              string             `db:"username" json:"username"`
+	Email                 string             `db:"email" json:"email"`
+	EmailVerified         bool               `db:"email_verified" json:"emailVerified"`
+	PasswordHash          pgtype.Text        `db:"password_hash" json:"passwordHash"`
+	CreatedAt_2           pgtype.Timestamptz `db:"created_at_2" json:"createdAt2"`
+	AvatarUrl             pgtype.Text        `db:"avatar_url" json:"avatarUrl"`
+	ShareReadReceipts     bool               `db:"share_read_receipts" json:"shareReadReceipts"`
+	ParentContent         pgtype.Text        `db:"parent_content" json:"parentContent"`
+	ParentDeletedAt       pgtype.Timestamptz `db:"parent_deleted_at" json:"parentDeletedAt"`
+	ParentSenderID        pgtype.UUID        `db:"parent_sender_id" json:"parentSenderId"`
+	ParentSenderUsername  pgtype.Text        `db:"parent_sender_username" json:"parentSenderUsername"`
+	ParentSenderAvatarUrl pgtype.Text        `db:"parent_sender_avatar_url" json:"parentSenderAvatarUrl"`
 }
 
 func (q *Queries) GetMessagesPaging(ctx context.Context, arg GetMessagesPagingParams) ([]GetMessagesPagingRow, error) {
@@ -101,6 +325,11 @@ func (q *Queries) GetMessagesPaging(ctx context.Context, arg GetMessagesPagingPa
 			&i.SenderID,
 			&i.Content,
 			&i.CreatedAt,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ReplyToID,
+			&i.ThreadID,
 			&i.ID_2,
 			&i.Username,
 			&i.Email,
@@ -108,6 +337,12 @@ func (q *Queries) GetMessagesPaging(ctx context.Context, arg GetMessagesPagingPa
 			&i.PasswordHash,
 			&i.CreatedAt_2,
 			&i.AvatarUrl,
+			&i.ShareReadReceipts,
+			&i.ParentContent,
+			&i.ParentDeletedAt,
+			&i.ParentSenderID,
+			&i.ParentSenderUsername,
+			&i.ParentSenderAvatarUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -118,3 +353,281 @@ func (q *Queries) GetMessagesPaging(ctx context.Context, arg GetMessagesPagingPa
 	}
 	return items, nil
 }
+
+const getThreadMessagesPaging = `-- name: GetThreadMessagesPaging :many
+SELECT m.id, m.room_id, m.sender_id, m.content, m.created_at, m.edited_at, m.deleted_at, m.deleted_by, m.reply_to_id, m.thread_id,
+       u.id, u.username, u.email, u.email_verified, u.password_hash, u.created_at, u.avatar_url, u.share_read_receipts,
+       p.content     AS parent_content,
+       p.deleted_at  AS parent_deleted_at,
+       pu.id         AS parent_sender_id,
+       pu.username   AS parent_sender_username,
+       pu.avatar_url AS parent_sender_avatar_url
+FROM messages m
+         JOIN users u ON u.id = m.sender_id
+         LEFT JOIN messages p ON p.id = m.reply_to_id
+         LEFT JOIN users pu ON pu.id = p.sender_id
+WHERE m.thread_id = $1::uuid
+  AND (
+      $2::timestamptz IS NULL
+      OR
+      (m.created_at, m.id) < ($2::timestamptz, $3::uuid)
+      )
+ORDER BY m.created_at DESC, m.id DESC
+LIMIT $4
+`
+
+type GetThreadMessagesPagingParams struct {
+	ThreadID        uuid.UUID          `db:"thread_id" json:"threadId"`
+	CursorCreatedAt pgtype.Timestamptz `db:"cursor_created_at" json:"cursorCreatedAt"`
+	CursorID        uuid.UUID          `db:"cursor_id" json:"cursorId"`
+	Limit           int32              `db:"limit_" json:"limit"`
+}
+
+type GetThreadMessagesPagingRow struct {
+	ID                    uuid.UUID          `db:"id" json:"id"`
+	RoomID                uuid.UUID          `db:"room_id" json:"roomId"`
+	SenderID              uuid.UUID          `db:"sender_id" json:"senderId"`
+	Content               string             `db:"content" json:"content"`
+	CreatedAt             pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	EditedAt              pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
+	DeletedAt             pgtype.Timestamptz `db:"deleted_at" json:"deletedAt"`
+	DeletedBy             pgtype.UUID        `db:"deleted_by" json:"deletedBy"`
+	ReplyToID             pgtype.UUID        `db:"reply_to_id" json:"replyToId"`
+	ThreadID              pgtype.UUID        `db:"thread_id" json:"threadId"`
+	ID_2                  uuid.UUID          `db:"id_2" json:"id2"`
+	Username              string             `db:"username" json:"username"`
+	Email                 string             `db:"email" json:"email"`
+	EmailVerified         bool               `db:"email_verified" json:"emailVerified"`
+	PasswordHash          pgtype.Text        `db:"password_hash" json:"passwordHash"`
+	CreatedAt_2           pgtype.Timestamptz `db:"created_at_2" json:"createdAt2"`
+	AvatarUrl             pgtype.Text        `db:"avatar_url" json:"avatarUrl"`
+	ShareReadReceipts     bool               `db:"share_read_receipts" json:"shareReadReceipts"`
+	ParentContent         pgtype.Text        `db:"parent_content" json:"parentContent"`
+	ParentDeletedAt       pgtype.Timestamptz `db:"parent_deleted_at" json:"parentDeletedAt"`
+	ParentSenderID        pgtype.UUID        `db:"parent_sender_id" json:"parentSenderId"`
+	ParentSenderUsername  pgtype.Text        `db:"parent_sender_username" json:"parentSenderUsername"`
+	ParentSenderAvatarUrl pgtype.Text        `db:"parent_sender_avatar_url" json:"parentSenderAvatarUrl"`
+}
+
+func (q *Queries) GetThreadMessagesPaging(ctx context.Context, arg GetThreadMessagesPagingParams) ([]GetThreadMessagesPagingRow, error) {
+	rows, err := q.db.Query(ctx, getThreadMessagesPaging,
+		arg.ThreadID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetThreadMessagesPagingRow{}
+	for rows.Next() {
+		var i GetThreadMessagesPagingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.SenderID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ReplyToID,
+			&i.ThreadID,
+			&i.ID_2,
+			&i.Username,
+			&i.Email,
+			&i.EmailVerified,
+			&i.PasswordHash,
+			&i.CreatedAt_2,
+			&i.AvatarUrl,
+			&i.ShareReadReceipts,
+			&i.ParentContent,
+			&i.ParentDeletedAt,
+			&i.ParentSenderID,
+			&i.ParentSenderUsername,
+			&i.ParentSenderAvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessageReaders = `-- name: ListMessageReaders :many
+SELECT u.id, u.username, u.avatar_url
+FROM room_members rm
+         JOIN users u ON u.id = rm.user_id
+WHERE rm.room_id = $1::uuid
+  AND rm.user_id <> $2::uuid
+  AND u.share_read_receipts
+  AND (rm.last_read_at, rm.last_read_message_id) >= ($3::timestamptz, $4::uuid)
+ORDER BY u.username
+`
+
+type ListMessageReadersParams struct {
+	RoomID    uuid.UUID          `db:"room_id" json:"roomId"`
+	SenderID  uuid.UUID          `db:"sender_id" json:"senderId"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	MessageID uuid.UUID          `db:"message_id" json:"messageId"`
+}
+
+type ListMessageReadersRow struct {
+	ID        uuid.UUID   `db:"id" json:"id"`
+	Username  string      `db:"username" json:"username"`
+	AvatarUrl pgtype.Text `db:"avatar_url" json:"avatarUrl"`
+}
+
+func (q *Queries) ListMessageReaders(ctx context.Context, arg ListMessageReadersParams) ([]ListMessageReadersRow, error) {
+	rows, err := q.db.Query(ctx, listMessageReaders,
+		arg.RoomID,
+		arg.SenderID,
+		arg.CreatedAt,
+		arg.MessageID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMessageReadersRow{}
+	for rows.Next() {
+		var i ListMessageReadersRow
+		if err := rows.Scan(&i.ID, &i.Username, &i.AvatarUrl); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessageRevisions = `-- name: ListMessageRevisions :many
+SELECT id, message_id, content, edited_at
+FROM message_revisions
+WHERE message_id = $1
+ORDER BY edited_at
+`
+
+func (q *Queries) ListMessageRevisions(ctx context.Context, messageID uuid.UUID) ([]MessageRevision, error) {
+	rows, err := q.db.Query(ctx, listMessageRevisions, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MessageRevision{}
+	for rows.Next() {
+		var i MessageRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.MessageID,
+			&i.Content,
+			&i.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockMessage = `-- name: LockMessage :one
+SELECT id, room_id, sender_id, content, created_at, edited_at, deleted_at, deleted_by, reply_to_id, thread_id
+FROM messages
+WHERE id = $1
+    FOR UPDATE
+`
+
+func (q *Queries) LockMessage(ctx context.Context, id uuid.UUID) (Message, error) {
+	row := q.db.QueryRow(ctx, lockMessage, id)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.SenderID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ReplyToID,
+		&i.ThreadID,
+	)
+	return i, err
+}
+
+const purgeDeletedMessages = `-- name: PurgeDeletedMessages :execrows
+WITH purged AS (SELECT id
+                FROM messages
+                WHERE deleted_at < $1::timestamptz
+                  AND content <> ''
+                ORDER BY deleted_at
+                LIMIT $2 FOR UPDATE SKIP LOCKED),
+     revisions AS (
+         DELETE FROM message_revisions
+             WHERE message_id IN (SELECT id FROM purged))
+UPDATE messages
+SET content = ''
+WHERE id IN (SELECT id FROM purged)
+`
+
+type PurgeDeletedMessagesParams struct {
+	DeletedBefore pgtype.Timestamptz `db:"deleted_before" json:"deletedBefore"`
+	Limit         int32              `db:"limit_" json:"limit"`
+}
+
+func (q *Queries) PurgeDeletedMessages(ctx context.Context, arg PurgeDeletedMessagesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedMessages, arg.DeletedBefore, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteMessage = `-- name: SoftDeleteMessage :execrows
+UPDATE messages
+SET deleted_at = $1,
+    deleted_by = $2
+WHERE id = $3
+  AND deleted_at IS NULL
+`
+
+type SoftDeleteMessageParams struct {
+	DeletedAt pgtype.Timestamptz `db:"deleted_at" json:"deletedAt"`
+	DeletedBy pgtype.UUID        `db:"deleted_by" json:"deletedBy"`
+	ID        uuid.UUID          `db:"id" json:"id"`
+}
+
+func (q *Queries) SoftDeleteMessage(ctx context.Context, arg SoftDeleteMessageParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteMessage, arg.DeletedAt, arg.DeletedBy, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateMessageContent = `-- name: UpdateMessageContent :exec
+UPDATE messages
+SET content   = $1,
+    edited_at = $2
+WHERE id = $3
+`
+
+type UpdateMessageContentParams struct {
+	Content  string             `db:"content" json:"content"`
+	EditedAt pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
+	ID       uuid.UUID          `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateMessageContent(ctx context.Context, arg UpdateMessageContentParams) error {
+	_, err := q.db.Exec(ctx, updateMessageContent, arg.Content, arg.EditedAt, arg.ID)
+	return err
+}
@@ -9,6 +9,21 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Attachment struct {
+	ID           uuid.UUID          `db:"id" json:"id"`
+	RoomID       uuid.UUID          `db:"room_id" json:"roomId"`
+	UploaderID   uuid.UUID          `db:"uploader_id" json:"uploaderId"`
+	MessageID    pgtype.UUID        `db:"message_id" json:"messageId"`
+	Name         string             `db:"name" json:"name"`
+	ContentType  string             `db:"content_type" json:"contentType"`
+	Size         int64              `db:"size" json:"size"`
+	Width        pgtype.Int4        `db:"width" json:"width"`
+	Height       pgtype.Int4        `db:"height" json:"height"`
+	StorageKey   string             `db:"storage_key" json:"storageKey"`
+	ThumbnailKey pgtype.Text        `db:"thumbnail_key" json:"thumbnailKey"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+}
+
 type EmailVerificationCode struct {
 	UserID       uuid.UUID          `db:"user_id" json:"userId"`
 	CodeHash     string             `db:"code_hash" json:"codeHash"`
@@ -39,30 +54,90 @@ type Message struct {
 	SenderID  uuid.UUID          `db:"sender_id" json:"senderId"`
 	Content   string             `db:"content" json:"content"`
 	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	EditedAt  pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
+	DeletedAt pgtype.Timestamptz `db:"deleted_at" json:"deletedAt"`
+	DeletedBy pgtype.UUID        `db:"deleted_by" json:"deletedBy"`
+	ReplyToID pgtype.UUID        `db:"reply_to_id" json:"replyToId"`
+	ThreadID  pgtype.UUID        `db:"thread_id" json:"threadId"`
+}
+
+type MessageReaction struct {
+	MessageID uuid.UUID          `db:"message_id" json:"messageId"`
+	UserID    uuid.UUID          `db:"user_id" json:"userId"`
+	Emoji     string             `db:"emoji" json:"emoji"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+}
+
+type MessageRevision struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	MessageID uuid.UUID          `db:"message_id" json:"messageId"`
+	Content   string             `db:"content" json:"content"`
+	EditedAt  pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
 }
 
 type Room struct {
+	ID         uuid.UUID          `db:"id" json:"id"`
+	Name       pgtype.Text        `db:"name" json:"name"`
+	Slug       string             `db:"slug" json:"slug"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	Visibility string             `db:"visibility" json:"visibility"`
+	MaxPins    pgtype.Int4        `db:"max_pins" json:"maxPins"`
+}
+
+type RoomBan struct {
+	RoomID    uuid.UUID          `db:"room_id" json:"roomId"`
+	UserID    uuid.UUID          `db:"user_id" json:"userId"`
+	BannedBy  pgtype.UUID        `db:"banned_by" json:"bannedBy"`
+	Reason    string             `db:"reason" json:"reason"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+}
+
+type RoomInvite struct {
 	ID        uuid.UUID          `db:"id" json:"id"`
-	Name      pgtype.Text        `db:"name" json:"name"`
-	Slug      string             `db:"slug" json:"slug"`
+	RoomID    uuid.UUID          `db:"room_id" json:"roomId"`
+	Code      string             `db:"code" json:"code"`
+	CreatedBy uuid.UUID          `db:"created_by" json:"createdBy"`
+	MaxUses   pgtype.Int4        `db:"max_uses" json:"maxUses"`
+	Uses      int32              `db:"uses" json:"uses"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+	RevokedAt pgtype.Timestamptz `db:"revoked_at" json:"revokedAt"`
 	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
 }
 
 type RoomMember struct {
-	ID       uuid.UUID          `db:"id" json:"id"`
-	RoomID   uuid.UUID          `db:"room_id" json:"roomId"`
-	UserID   uuid.UUID          `db:"user_id" json:"userId"`
-	JoinedAt pgtype.Timestamptz `db:"joined_at" json:"joinedAt"`
+	ID                uuid.UUID          `db:"id" json:"id"`
+	RoomID            uuid.UUID          `db:"room_id" json:"roomId"`
+	UserID            uuid.UUID          `db:"user_id" json:"userId"`
+	JoinedAt          pgtype.Timestamptz `db:"joined_at" json:"joinedAt"`
+	LastReadMessageID pgtype.UUID        `db:"last_read_message_id" json:"lastReadMessageId"`
+	LastReadAt        pgtype.Timestamptz `db:"last_read_at" json:"lastReadAt"`
+	Role              string             `db:"role" json:"role"`
+}
+
+type RoomPin struct {
+	RoomID    uuid.UUID          `db:"room_id" json:"roomId"`
+	MessageID uuid.UUID          `db:"message_id" json:"messageId"`
+	PinnedBy  pgtype.UUID        `db:"pinned_by" json:"pinnedBy"`
+	PinnedAt  pgtype.Timestamptz `db:"pinned_at" json:"pinnedAt"`
+}
+
+type ThreadFollow struct {
+	ThreadID  uuid.UUID          `db:"thread_id" json:"threadId"`
+	UserID    uuid.UUID          `db:"user_id" json:"userId"`
+	Following bool               `db:"following" json:"following"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updatedAt"`
 }
 
 type User struct {
-	ID            uuid.UUID          `db:"id" json:"id"`
-	Username      string             `db:"username" json:"username"`
-	Email         string             `db:"email" json:"email"`
-	EmailVerified bool               `db:"email_verified" json:"emailVerified"`
-	PasswordHash  pgtype.Text        `db:"password_hash" json:"passwordHash"`
-	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"createdAt"`
-	AvatarUrl     pgtype.Text        `db:"avatar_url" json:"avatarUrl"`
+	ID                uuid.UUID          `db:"id" json:"id"`
+	Username          string             `db:"username" json:"username"`
+	Email             string             `db:"email" json:"email"`
+	EmailVerified     bool               `db:"email_verified" json:"emailVerified"`
+	PasswordHash      pgtype.Text        `db:"password_hash" json:"passwordHash"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	AvatarUrl         pgtype.Text        `db:"avatar_url" json:"avatarUrl"`
+	ShareReadReceipts bool               `db:"share_read_receipts" json:"shareReadReceipts"`
 }
 
 type UserBlock struct {
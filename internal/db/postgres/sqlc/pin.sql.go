@@ -0,0 +1,222 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: pin.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getRoomPin = `-- name: GetRoomPin :one
+SELECT m.id,
+       m.room_id,
+       m.content,
+       m.created_at,
+       m.edited_at,
+       m.thread_id,
+       u.id          AS sender_id,
+       u.username    AS sender_username,
+       u.avatar_url  AS sender_avatar_url,
+       p.pinned_at,
+       pu.id         AS pinned_by_id,
+       pu.username   AS pinned_by_username,
+       pu.avatar_url AS pinned_by_avatar_url
+FROM room_pins p
+         JOIN messages m ON m.id = p.message_id
+         JOIN users u ON u.id = m.sender_id
+         LEFT JOIN users pu ON pu.id = p.pinned_by
+WHERE p.room_id = $1
+  AND p.message_id = $2
+`
+
+type GetRoomPinParams struct {
+	RoomID    uuid.UUID `db:"room_id" json:"roomId"`
+	MessageID uuid.UUID `db:"message_id" json:"messageId"`
+}
+
+type GetRoomPinRow struct {
+	ID                uuid.UUID          `db:"id" json:"id"`
+	RoomID            uuid.UUID          `db:"room_id" json:"roomId"`
+	Content           string             `db:"content" json:"content"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	EditedAt          pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
+	ThreadID          pgtype.UUID        `db:"thread_id" json:"threadId"`
+	SenderID          uuid.UUID          `db:"sender_id" json:"senderId"`
+	SenderUsername    string             `db:"sender_username" json:"senderUsername"`
+	SenderAvatarUrl   pgtype.Text        `db:"sender_avatar_url" json:"senderAvatarUrl"`
+	PinnedAt          pgtype.Timestamptz `db:"pinned_at" json:"pinnedAt"`
+	PinnedByID        pgtype.UUID        `db:"pinned_by_id" json:"pinnedById"`
+	PinnedByUsername  pgtype.Text        `db:"pinned_by_username" json:"pinnedByUsername"`
+	PinnedByAvatarUrl pgtype.Text        `db:"pinned_by_avatar_url" json:"pinnedByAvatarUrl"`
+}
+
+func (q *Queries) GetRoomPin(ctx context.Context, arg GetRoomPinParams) (GetRoomPinRow, error) {
+	row := q.db.QueryRow(ctx, getRoomPin, arg.RoomID, arg.MessageID)
+	var i GetRoomPinRow
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.EditedAt,
+		&i.ThreadID,
+		&i.SenderID,
+		&i.SenderUsername,
+		&i.SenderAvatarUrl,
+		&i.PinnedAt,
+		&i.PinnedByID,
+		&i.PinnedByUsername,
+		&i.PinnedByAvatarUrl,
+	)
+	return i, err
+}
+
+const isMessagePinned = `-- name: IsMessagePinned :one
+SELECT EXISTS (SELECT 1
+               FROM room_pins
+               WHERE room_id = $1
+                 AND message_id = $2)
+`
+
+type IsMessagePinnedParams struct {
+	RoomID    uuid.UUID `db:"room_id" json:"roomId"`
+	MessageID uuid.UUID `db:"message_id" json:"messageId"`
+}
+
+func (q *Queries) IsMessagePinned(ctx context.Context, arg IsMessagePinnedParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isMessagePinned, arg.RoomID, arg.MessageID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listRoomPins = `-- name: ListRoomPins :many
+SELECT m.id,
+       m.room_id,
+       m.content,
+       m.created_at,
+       m.edited_at,
+       m.thread_id,
+       u.id          AS sender_id,
+       u.username    AS sender_username,
+       u.avatar_url  AS sender_avatar_url,
+       p.pinned_at,
+       pu.id         AS pinned_by_id,
+       pu.username   AS pinned_by_username,
+       pu.avatar_url AS pinned_by_avatar_url
+FROM room_pins p
+         JOIN messages m ON m.id = p.message_id
+         JOIN users u ON u.id = m.sender_id
+         LEFT JOIN users pu ON pu.id = p.pinned_by
+WHERE p.room_id = $1
+  AND m.deleted_at IS NULL
+ORDER BY p.pinned_at, m.id
+`
+
+type ListRoomPinsRow struct {
+	ID                uuid.UUID          `db:"id" json:"id"`
+	RoomID            uuid.UUID          `db:"room_id" json:"roomId"`
+	Content           string             `db:"content" json:"content"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	EditedAt          pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
+	ThreadID          pgtype.UUID        `db:"thread_id" json:"threadId"`
+	SenderID          uuid.UUID          `db:"sender_id" json:"senderId"`
+	SenderUsername    string             `db:"sender_username" json:"senderUsername"`
+	SenderAvatarUrl   pgtype.Text        `db:"sender_avatar_url" json:"senderAvatarUrl"`
+	PinnedAt          pgtype.Timestamptz `db:"pinned_at" json:"pinnedAt"`
+	PinnedByID        pgtype.UUID        `db:"pinned_by_id" json:"pinnedById"`
+	PinnedByUsername  pgtype.Text        `db:"pinned_by_username" json:"pinnedByUsername"`
+	PinnedByAvatarUrl pgtype.Text        `db:"pinned_by_avatar_url" json:"pinnedByAvatarUrl"`
+}
+
+func (q *Queries) ListRoomPins(ctx context.Context, roomID uuid.UUID) ([]ListRoomPinsRow, error) {
+	rows, err := q.db.Query(ctx, listRoomPins, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRoomPinsRow{}
+	for rows.Next() {
+		var i ListRoomPinsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.ThreadID,
+			&i.SenderID,
+			&i.SenderUsername,
+			&i.SenderAvatarUrl,
+			&i.PinnedAt,
+			&i.PinnedByID,
+			&i.PinnedByUsername,
+			&i.PinnedByAvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pinMessage = `-- name: PinMessage :execrows
+INSERT INTO room_pins (room_id, message_id, pinned_by, pinned_at)
+SELECT $1::uuid, $2::uuid, $3::uuid, $4::timestamptz
+WHERE (SELECT count(*)
+       FROM room_pins p
+                JOIN messages m ON m.id = p.message_id
+       WHERE p.room_id = $1::uuid
+         AND m.deleted_at IS NULL) < $5::int
+ON CONFLICT DO NOTHING
+`
+
+type PinMessageParams struct {
+	RoomID    uuid.UUID          `db:"room_id" json:"roomId"`
+	MessageID uuid.UUID          `db:"message_id" json:"messageId"`
+	PinnedBy  uuid.UUID          `db:"pinned_by" json:"pinnedBy"`
+	PinnedAt  pgtype.Timestamptz `db:"pinned_at" json:"pinnedAt"`
+	MaxPins   int32              `db:"max_pins" json:"maxPins"`
+}
+
+func (q *Queries) PinMessage(ctx context.Context, arg PinMessageParams) (int64, error) {
+	result, err := q.db.Exec(ctx, pinMessage,
+		arg.RoomID,
+		arg.MessageID,
+		arg.PinnedBy,
+		arg.PinnedAt,
+		arg.MaxPins,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const unpinMessage = `-- name: UnpinMessage :execrows
+DELETE
+FROM room_pins
+WHERE room_id = $1
+  AND message_id = $2
+`
+
+type UnpinMessageParams struct {
+	RoomID    uuid.UUID `db:"room_id" json:"roomId"`
+	MessageID uuid.UUID `db:"message_id" json:"messageId"`
+}
+
+func (q *Queries) UnpinMessage(ctx context.Context, arg UnpinMessageParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unpinMessage, arg.RoomID, arg.MessageID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
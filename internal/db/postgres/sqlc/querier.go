@@ -12,42 +12,93 @@ import (
 )
 
 type Querier interface {
+	AddMessageReaction(ctx context.Context, arg AddMessageReactionParams) (int64, error)
 	AddRoomMember(ctx context.Context, arg AddRoomMemberParams) error
+	AdvanceReadMarker(ctx context.Context, arg AdvanceReadMarkerParams) (int64, error)
+	AutoFollowThread(ctx context.Context, arg AutoFollowThreadParams) error
+	ClaimAttachments(ctx context.Context, arg ClaimAttachmentsParams) (int64, error)
+	CountMessageReaction(ctx context.Context, arg CountMessageReactionParams) (int32, error)
+	CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error)
 	CreateBlock(ctx context.Context, arg CreateBlockParams) error
 	CreateFriendRequest(ctx context.Context, arg CreateFriendRequestParams) error
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
+	CreateMessageRevision(ctx context.Context, arg CreateMessageRevisionParams) error
 	CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, error)
+	CreateRoomInvite(ctx context.Context, arg CreateRoomInviteParams) (RoomInvite, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteBlock(ctx context.Context, arg DeleteBlockParams) error
 	DeleteEmailVerificationCode(ctx context.Context, userID uuid.UUID) error
+	DeleteExpiredAttachments(ctx context.Context, arg DeleteExpiredAttachmentsParams) ([]Attachment, error)
 	DeleteFriendRequest(ctx context.Context, arg DeleteFriendRequestParams) error
 	DeleteFriendshipEdge(ctx context.Context, arg DeleteFriendshipEdgeParams) error
+	DeleteRoomBan(ctx context.Context, arg DeleteRoomBanParams) (int64, error)
+	GetActiveRoomBan(ctx context.Context, arg GetActiveRoomBanParams) (RoomBan, error)
+	GetAttachment(ctx context.Context, id uuid.UUID) (GetAttachmentRow, error)
 	GetEmailVerificationCode(ctx context.Context, userID uuid.UUID) (EmailVerificationCode, error)
 	GetEmailVerificationCodeByEmail(ctx context.Context, pendingEmail pgtype.Text) (EmailVerificationCode, error)
 	GetFriendRequest(ctx context.Context, arg GetFriendRequestParams) (FriendRequest, error)
+	GetMessage(ctx context.Context, id uuid.UUID) (GetMessageRow, error)
+	GetMessagesAfter(ctx context.Context, arg GetMessagesAfterParams) ([]GetMessagesAfterRow, error)
 	GetMessagesPaging(ctx context.Context, arg GetMessagesPagingParams) ([]GetMessagesPagingRow, error)
 	GetRoom(ctx context.Context, id uuid.UUID) (Room, error)
 	GetRoomBySlug(ctx context.Context, slug string) (Room, error)
+	GetRoomInviteByCodeForUpdate(ctx context.Context, code string) (RoomInvite, error)
+	GetRoomMemberRole(ctx context.Context, arg GetRoomMemberRoleParams) (string, error)
+	GetRoomPin(ctx context.Context, arg GetRoomPinParams) (GetRoomPinRow, error)
+	GetRoomReadState(ctx context.Context, arg GetRoomReadStateParams) (GetRoomReadStateRow, error)
+	GetThreadMessagesPaging(ctx context.Context, arg GetThreadMessagesPagingParams) ([]GetThreadMessagesPagingRow, error)
 	GetUser(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserByLogin(ctx context.Context, login string) (User, error)
 	GetUserRooms(ctx context.Context, userID uuid.UUID) ([]Room, error)
+	GetUserRoomsWithReadState(ctx context.Context, userID uuid.UUID) ([]GetUserRoomsWithReadStateRow, error)
+	IncrementRoomInviteUses(ctx context.Context, id uuid.UUID) error
 	IncrementVerificationAttempts(ctx context.Context, userID uuid.UUID) error
 	InsertFriendshipEdge(ctx context.Context, arg InsertFriendshipEdgeParams) error
 	IsBlocked(ctx context.Context, arg IsBlockedParams) (bool, error)
+	IsMessagePinned(ctx context.Context, arg IsMessagePinnedParams) (bool, error)
 	IsUserRoomMember(ctx context.Context, arg IsUserRoomMemberParams) (bool, error)
+	ListActiveRoomInvites(ctx context.Context, arg ListActiveRoomInvitesParams) ([]RoomInvite, error)
+	ListAttachmentsByIDs(ctx context.Context, ids []uuid.UUID) ([]Attachment, error)
 	ListBlocked(ctx context.Context, fromUserID uuid.UUID) ([]UserBlock, error)
 	ListFriends(ctx context.Context, userID uuid.UUID) ([]Friendship, error)
 	ListFriendsWithUsers(ctx context.Context, userID uuid.UUID) ([]ListFriendsWithUsersRow, error)
 	ListIncomingRequests(ctx context.Context, toUserID uuid.UUID) ([]FriendRequest, error)
 	ListIncomingRequestsWithUsers(ctx context.Context, toUserID uuid.UUID) ([]ListIncomingRequestsWithUsersRow, error)
+	ListMessageReaders(ctx context.Context, arg ListMessageReadersParams) ([]ListMessageReadersRow, error)
+	ListMessageRevisions(ctx context.Context, messageID uuid.UUID) ([]MessageRevision, error)
+	ListMessagesAttachments(ctx context.Context, messageIds []uuid.UUID) ([]Attachment, error)
+	ListMessagesReactions(ctx context.Context, arg ListMessagesReactionsParams) ([]ListMessagesReactionsRow, error)
 	ListOutgoingRequests(ctx context.Context, fromUserID uuid.UUID) ([]FriendRequest, error)
 	ListOutgoingRequestsWithUsers(ctx context.Context, fromUserID uuid.UUID) ([]ListOutgoingRequestsWithUsersRow, error)
+	ListRoomMemberIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error)
+	ListRoomMembers(ctx context.Context, arg ListRoomMembersParams) ([]ListRoomMembersRow, error)
+	ListRoomPins(ctx context.Context, roomID uuid.UUID) ([]ListRoomPinsRow, error)
+	ListThreadFollowerIDs(ctx context.Context, threadID uuid.UUID) ([]uuid.UUID, error)
+	ListThreadParticipants(ctx context.Context, arg ListThreadParticipantsParams) ([]ListThreadParticipantsRow, error)
+	ListThreadSummaries(ctx context.Context, arg ListThreadSummariesParams) ([]ListThreadSummariesRow, error)
+	LockMessage(ctx context.Context, id uuid.UUID) (Message, error)
+	LockRoom(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
 	MarkEmailVerified(ctx context.Context, id uuid.UUID) error
+	PinMessage(ctx context.Context, arg PinMessageParams) (int64, error)
+	PurgeDeletedMessages(ctx context.Context, arg PurgeDeletedMessagesParams) (int64, error)
+	RemoveMessageReaction(ctx context.Context, arg RemoveMessageReactionParams) (int64, error)
+	RemoveRoomMember(ctx context.Context, arg RemoveRoomMemberParams) (int64, error)
+	RevokeRoomInvite(ctx context.Context, arg RevokeRoomInviteParams) (int64, error)
 	RoomExists(ctx context.Context, id uuid.UUID) (bool, error)
+	SearchMessages(ctx context.Context, arg SearchMessagesParams) ([]SearchMessagesRow, error)
+	SetThreadFollow(ctx context.Context, arg SetThreadFollowParams) error
+	SoftDeleteMessage(ctx context.Context, arg SoftDeleteMessageParams) (int64, error)
+	UnpinMessage(ctx context.Context, arg UnpinMessageParams) (int64, error)
+	UpdateMessageContent(ctx context.Context, arg UpdateMessageContentParams) error
+	UpdateRoom(ctx context.Context, arg UpdateRoomParams) (Room, error)
+	UpdateRoomMemberRole(ctx context.Context, arg UpdateRoomMemberRoleParams) (int64, error)
 	UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarParams) error
 	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) error
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	UpdateUserShareReadReceipts(ctx context.Context, arg UpdateUserShareReadReceiptsParams) error
 	UpsertEmailVerificationCode(ctx context.Context, arg UpsertEmailVerificationCodeParams) error
+	UpsertRoomBan(ctx context.Context, arg UpsertRoomBanParams) error
+	UserReactionExists(ctx context.Context, arg UserReactionExistsParams) (bool, error)
 	UserWithEmailExists(ctx context.Context, email string) (bool, error)
 	UserWithUsernameExists(ctx context.Context, username string) (bool, error)
 }
@@ -0,0 +1,158 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: reaction.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const addMessageReaction = `-- name: AddMessageReaction :execrows
+INSERT INTO message_reactions (message_id, user_id, emoji, created_at)
+SELECT $1::uuid, $2::uuid, $3::text, $4::timestamptz
+WHERE EXISTS (SELECT 1
+              FROM message_reactions
+              WHERE message_id = $1::uuid
+                AND emoji = $3::text)
+   OR (SELECT count(DISTINCT emoji)
+       FROM message_reactions
+       WHERE message_id = $1::uuid) < $5::int
+ON CONFLICT DO NOTHING
+`
+
+type AddMessageReactionParams struct {
+	MessageID uuid.UUID          `db:"message_id" json:"messageId"`
+	UserID    uuid.UUID          `db:"user_id" json:"userId"`
+	Emoji     string             `db:"emoji" json:"emoji"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	MaxEmojis int32              `db:"max_emojis" json:"maxEmojis"`
+}
+
+func (q *Queries) AddMessageReaction(ctx context.Context, arg AddMessageReactionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addMessageReaction,
+		arg.MessageID,
+		arg.UserID,
+		arg.Emoji,
+		arg.CreatedAt,
+		arg.MaxEmojis,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countMessageReaction = `-- name: CountMessageReaction :one
+SELECT count(*)::int
+FROM message_reactions
+WHERE message_id = $1
+  AND emoji = $2
+`
+
+type CountMessageReactionParams struct {
+	MessageID uuid.UUID `db:"message_id" json:"messageId"`
+	Emoji     string    `db:"emoji" json:"emoji"`
+}
+
+func (q *Queries) CountMessageReaction(ctx context.Context, arg CountMessageReactionParams) (int32, error) {
+	row := q.db.QueryRow(ctx, countMessageReaction, arg.MessageID, arg.Emoji)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const listMessagesReactions = `-- name: ListMessagesReactions :many
+SELECT message_id,
+       emoji,
+       count(*)::int                                AS count,
+       bool_or(user_id = $1::uuid)::boolean AS reacted_by_me
+FROM message_reactions
+WHERE message_id = ANY ($2::uuid[])
+GROUP BY message_id, emoji
+ORDER BY message_id, min(created_at)
+`
+
+type ListMessagesReactionsParams struct {
+	ViewerID   uuid.UUID   `db:"viewer_id" json:"viewerId"`
+	MessageIds []uuid.UUID `db:"message_ids" json:"messageIds"`
+}
+
+type ListMessagesReactionsRow struct {
+	MessageID   uuid.UUID `db:"message_id" json:"messageId"`
+	Emoji       string    `db:"emoji" json:"emoji"`
+	Count       int32     `db:"count" json:"count"`
+	ReactedByMe bool      `db:"reacted_by_me" json:"reactedByMe"`
+}
+
+func (q *Queries) ListMessagesReactions(ctx context.Context, arg ListMessagesReactionsParams) ([]ListMessagesReactionsRow, error) {
+	rows, err := q.db.Query(ctx, listMessagesReactions, arg.ViewerID, arg.MessageIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMessagesReactionsRow{}
+	for rows.Next() {
+		var i ListMessagesReactionsRow
+		if err := rows.Scan(
+			&i.MessageID,
+			&i.Emoji,
+			&i.Count,
+			&i.ReactedByMe,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeMessageReaction = `-- name: RemoveMessageReaction :execrows
+DELETE
+FROM message_reactions
+WHERE message_id = $1
+  AND user_id = $2
+  AND emoji = $3
+`
+
+type RemoveMessageReactionParams struct {
+	MessageID uuid.UUID `db:"message_id" json:"messageId"`
+	UserID    uuid.UUID `db:"user_id" json:"userId"`
+	Emoji     string    `db:"emoji" json:"emoji"`
+}
+
+func (q *Queries) RemoveMessageReaction(ctx context.Context, arg RemoveMessageReactionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeMessageReaction, arg.MessageID, arg.UserID, arg.Emoji)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const userReactionExists = `-- name: UserReactionExists :one
+SELECT EXISTS (SELECT 1
+               FROM message_reactions
+               WHERE message_id = $1
+                 AND user_id = $2
+                 AND emoji = $3)
+`
+
+type UserReactionExistsParams struct {
+	MessageID uuid.UUID `db:"message_id" json:"messageId"`
+	UserID    uuid.UUID `db:"user_id" json:"userId"`
+	Emoji     string    `db:"emoji" json:"emoji"`
+}
+
+func (q *Queries) UserReactionExists(ctx context.Context, arg UserReactionExistsParams) (bool, error) {
+	row := q.db.QueryRow(ctx, userReactionExists, arg.MessageID, arg.UserID, arg.Emoji)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
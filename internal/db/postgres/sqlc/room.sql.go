@@ -13,8 +13,8 @@ import (
 )
 
 const addRoomMember = `-- name: AddRoomMember :exec
-INSERT INTO room_members (id, room_id, user_id, joined_at)
-VALUES ($1, $2, $3, $4)
+INSERT INTO room_members (id, room_id, user_id, role, joined_at)
+VALUES ($1, $2, $3, $4, $5)
 ON CONFLICT (room_id, user_id) DO NOTHING
 `
 
@@ -22,6 +22,7 @@ type AddRoomMemberParams struct {
 	ID       uuid.UUID          `db:"id" json:"id"`
 	RoomID   uuid.UUID          `db:"room_id" json:"roomId"`
 	UserID   uuid.UUID          `db:"user_id" json:"userId"`
+	Role     string             `db:"role" json:"role"`
 	JoinedAt pgtype.Timestamptz `db:"joined_at" json:"joinedAt"`
 }
 
@@ -30,22 +31,54 @@ func (q *Queries) AddRoomMember(ctx context.Context, arg AddRoomMemberParams) er
 		arg.ID,
 		arg.RoomID,
 		arg.UserID,
+		arg.Role,
 		arg.JoinedAt,
 	)
 	return err
 }
 
+const advanceReadMarker = `-- name: AdvanceReadMarker :execrows
+UPDATE room_members
+SET last_read_message_id = $1::uuid,
+    last_read_at         = $2::timestamptz
+WHERE room_id = $3::uuid
+  AND user_id = $4::uuid
+  AND (last_read_at IS NULL
+    OR (last_read_at, last_read_message_id) < ($2::timestamptz, $1::uuid))
+`
+
+type AdvanceReadMarkerParams struct {
+	MessageID        uuid.UUID          `db:"message_id" json:"messageId"`
+	MessageCreatedAt pgtype.Timestamptz `db:"message_created_at" json:"messageCreatedAt"`
+	RoomID           uuid.UUID          `db:"room_id" json:"roomId"`
+	UserID           uuid.UUID          `db:"user_id" json:"userId"`
+}
+
+func (q *Queries) AdvanceReadMarker(ctx context.Context, arg AdvanceReadMarkerParams) (int64, error) {
+	result, err := q.db.Exec(ctx, advanceReadMarker,
+		arg.MessageID,
+		arg.MessageCreatedAt,
+		arg.RoomID,
+		arg.UserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createRoom = `-- name: CreateRoom :one
-INSERT INTO rooms (id, name, slug, created_at)
-VALUES ($1, $2, $3, $4)
-RETURNING id, name, slug, created_at
+INSERT INTO rooms (id, name, slug, visibility, created_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, slug, created_at, visibility, max_pins
 `
 
 type CreateRoomParams struct {
-	ID        uuid.UUID          `db:"id" json:"id"`
-	Name      pgtype.Text        `db:"name" json:"name"`
-	Slug      string             `db:"slug" json:"slug"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	ID         uuid.UUID          `db:"id" json:"id"`
+	Name       pgtype.Text        `db:"name" json:"name"`
+	Slug       string             `db:"slug" json:"slug"`
+	Visibility string             `db:"visibility" json:"visibility"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"createdAt"`
 }
 
 func (q *Queries) CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, error) {
@@ -53,6 +86,7 @@ func (q *Queries) CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, e
 		arg.ID,
 		arg.Name,
 		arg.Slug,
+		arg.Visibility,
 		arg.CreatedAt,
 	)
 	var i Room
@@ -61,12 +95,14 @@ func (q *Queries) CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, e
 		&i.Name,
 		&i.Slug,
 		&i.CreatedAt,
+		&i.Visibility,
+		&i.MaxPins,
 	)
 	return i, err
 }
 
 const getRoom = `-- name: GetRoom :one
-SELECT id, name, slug, created_at
+SELECT id, name, slug, created_at, visibility, max_pins
 FROM rooms
 WHERE id = $1
 `
@@ -79,12 +115,14 @@ func (q *Queries) GetRoom(ctx context.Context, id uuid.UUID) (Room, error) {
 		&i.Name,
 		&i.Slug,
 		&i.CreatedAt,
+		&i.Visibility,
+		&i.MaxPins,
 	)
 	return i, err
 }
 
 const getRoomBySlug = `-- name: GetRoomBySlug :one
-SELECT id, name, slug, created_at
+SELECT id, name, slug, created_at, visibility, max_pins
 FROM rooms
 WHERE slug = $1
 LIMIT 1
@@ -98,12 +136,78 @@ func (q *Queries) GetRoomBySlug(ctx context.Context, slug string) (Room, error)
 		&i.Name,
 		&i.Slug,
 		&i.CreatedAt,
+		&i.Visibility,
+		&i.MaxPins,
 	)
 	return i, err
 }
 
+const getRoomMemberRole = `-- name: GetRoomMemberRole :one
+SELECT role
+FROM room_members
+WHERE room_id = $1
+  AND user_id = $2
+`
+
+type GetRoomMemberRoleParams struct {
+	RoomID uuid.UUID `db:"room_id" json:"roomId"`
+	UserID uuid.UUID `db:"user_id" json:"userId"`
+}
+
+func (q *Queries) GetRoomMemberRole(ctx context.Context, arg GetRoomMemberRoleParams) (string, error) {
+	row := q.db.QueryRow(ctx, getRoomMemberRole, arg.RoomID, arg.UserID)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getRoomReadState = `-- name: GetRoomReadState :one
+SELECT rm.last_read_message_id,
+       (SELECT count(*)
+        FROM messages m
+        WHERE m.room_id = rm.room_id
+          AND m.sender_id <> rm.user_id
+          AND m.deleted_at IS NULL
+          AND m.thread_id IS NULL
+          AND (m.created_at, m.id) > (COALESCE(rm.last_read_at, rm.joined_at),
+                                      COALESCE(rm.last_read_message_id, '00000000-0000-0000-0000-000000000000'::uuid))
+       )::int AS unread_count,
+       (SELECT count(*)
+        FROM messages m
+        WHERE m.room_id = rm.room_id
+          AND m.sender_id <> rm.user_id
+          AND m.deleted_at IS NULL
+          AND m.thread_id IS NULL
+          AND (m.created_at, m.id) > (COALESCE(rm.last_read_at, rm.joined_at),
+                                      COALESCE(rm.last_read_message_id, '00000000-0000-0000-0000-000000000000'::uuid))
+          AND m.content ~* ('(^|\s)@' || regexp_replace(u.username, '([.^$*+?()\[\]{}|\\])', '\\\1', 'g') || '\M')
+       )::int AS mention_count
+FROM room_members rm
+         JOIN users u ON u.id = rm.user_id
+WHERE rm.room_id = $1
+  AND rm.user_id = $2
+`
+
+type GetRoomReadStateParams struct {
+	RoomID uuid.UUID `db:"room_id" json:"roomId"`
+	UserID uuid.UUID `db:"user_id" json:"userId"`
+}
+
+type GetRoomReadStateRow struct {
+	LastReadMessageID pgtype.UUID `db:"last_read_message_id" json:"lastReadMessageId"`
+	UnreadCount       int32       `db:"unread_count" json:"unreadCount"`
+	MentionCount      int32       `db:"mention_count" json:"mentionCount"`
+}
+
+func (q *Queries) GetRoomReadState(ctx context.Context, arg GetRoomReadStateParams) (GetRoomReadStateRow, error) {
+	row := q.db.QueryRow(ctx, getRoomReadState, arg.RoomID, arg.UserID)
+	var i GetRoomReadStateRow
+	err := row.Scan(&i.LastReadMessageID, &i.UnreadCount, &i.MentionCount)
+	return i, err
+}
+
 const getUserRooms = `-- name: GetUserRooms :many
-SELECT r.id, r.name, r.slug, r.created_at
+SELECT r.id, r.name, r.slug, r.created_at, r.visibility, r.max_pins
 FROM rooms r
          JOIN room_members rm ON rm.room_id = r.id
 WHERE rm.user_id = $1
@@ -123,6 +227,81 @@ func (q *Queries) GetUserRooms(ctx context.Context, userID uuid.UUID) ([]Room, e
 			&i.Name,
 			&i.Slug,
 			&i.CreatedAt,
+			&i.Visibility,
+			&i.MaxPins,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserRoomsWithReadState = `-- name: GetUserRoomsWithReadState :many
+SELECT r.id, r.name, r.slug, r.created_at, r.visibility, r.max_pins,
+       rm.role,
+       rm.last_read_message_id,
+       (SELECT count(*)
+        FROM messages m
+        WHERE m.room_id = r.id
+          AND m.sender_id <> rm.user_id
+          AND m.deleted_at IS NULL
+          AND m.thread_id IS NULL
+          AND (m.created_at, m.id) > (COALESCE(rm.last_read_at, rm.joined_at),
+                                      COALESCE(rm.last_read_message_id, '00000000-0000-0000-0000-000000000000'::uuid))
+       )::int AS unread_count,
+       (SELECT count(*)
+        FROM messages m
+        WHERE m.room_id = r.id
+          AND m.sender_id <> rm.user_id
+          AND m.deleted_at IS NULL
+          AND m.thread_id IS NULL
+          AND (m.created_at, m.id) > (COALESCE(rm.last_read_at, rm.joined_at),
+                                      COALESCE(rm.last_read_message_id, '00000000-0000-0000-0000-000000000000'::uuid))
+          AND m.content ~* ('(^|\s)@' || regexp_replace(u.username, '([.^$*+?()\[\]{}|\\])', '\\\1', 'g') || '\M')
+       )::int AS mention_count
+FROM rooms r
+         JOIN room_members rm ON rm.room_id = r.id
+         JOIN users u ON u.id = rm.user_id
+WHERE rm.user_id = $1
+`
+
+type GetUserRoomsWithReadStateRow struct {
+	ID                uuid.UUID          `db:"id" json:"id"`
+	Name              pgtype.Text        `db:"name" json:"name"`
+	Slug              string             `db:"slug" json:"slug"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	Visibility        string             `db:"visibility" json:"visibility"`
+	MaxPins           pgtype.Int4        `db:"max_pins" json:"maxPins"`
+	Role              string             `db:"role" json:"role"`
+	LastReadMessageID pgtype.UUID        `db:"last_read_message_id" json:"lastReadMessageId"`
+	UnreadCount       int32              `db:"unread_count" json:"unreadCount"`
+	MentionCount      int32              `db:"mention_count" json:"mentionCount"`
+}
+
+func (q *Queries) GetUserRoomsWithReadState(ctx context.Context, userID uuid.UUID) ([]GetUserRoomsWithReadStateRow, error) {
+	rows, err := q.db.Query(ctx, getUserRoomsWithReadState, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUserRoomsWithReadStateRow{}
+	for rows.Next() {
+		var i GetUserRoomsWithReadStateRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Slug,
+			&i.CreatedAt,
+			&i.Visibility,
+			&i.MaxPins,
+			&i.Role,
+			&i.LastReadMessageID,
+			&i.UnreadCount,
+			&i.MentionCount,
 		); err != nil {
 			return nil, err
 		}
@@ -153,6 +332,129 @@ func (q *Queries) IsUserRoomMember(ctx context.Context, arg IsUserRoomMemberPara
 	return exists, err
 }
 
+const listRoomMemberIDs = `-- name: ListRoomMemberIDs :many
+SELECT user_id
+FROM room_members
+WHERE room_id = $1
+ORDER BY joined_at
+`
+
+func (q *Queries) ListRoomMemberIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listRoomMemberIDs, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoomMembers = `-- name: ListRoomMembers :many
+SELECT rm.user_id, rm.role, rm.joined_at, u.username, u.avatar_url
+FROM room_members rm
+         JOIN users u ON u.id = rm.user_id
+WHERE rm.room_id = $1::uuid
+  AND lower(u.username) LIKE $2::text
+  AND (
+      $3::timestamptz IS NULL
+      OR
+      (rm.joined_at, rm.user_id) > ($3::timestamptz, $4::uuid)
+      )
+ORDER BY rm.joined_at, rm.user_id
+LIMIT $5
+`
+
+type ListRoomMembersParams struct {
+	RoomID          uuid.UUID          `db:"room_id" json:"roomId"`
+	UsernamePattern string             `db:"username_pattern" json:"usernamePattern"`
+	CursorJoinedAt  pgtype.Timestamptz `db:"cursor_joined_at" json:"cursorJoinedAt"`
+	CursorUserID    uuid.UUID          `db:"cursor_user_id" json:"cursorUserId"`
+	Limit           int32              `db:"limit_" json:"limit"`
+}
+
+type ListRoomMembersRow struct {
+	UserID    uuid.UUID          `db:"user_id" json:"userId"`
+	Role      string             `db:"role" json:"role"`
+	JoinedAt  pgtype.Timestamptz `db:"joined_at" json:"joinedAt"`
+	Username  string             `db:"username" json:"username"`
+	AvatarUrl pgtype.Text        `db:"avatar_url" json:"avatarUrl"`
+}
+
+func (q *Queries) ListRoomMembers(ctx context.Context, arg ListRoomMembersParams) ([]ListRoomMembersRow, error) {
+	rows, err := q.db.Query(ctx, listRoomMembers,
+		arg.RoomID,
+		arg.UsernamePattern,
+		arg.CursorJoinedAt,
+		arg.CursorUserID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRoomMembersRow{}
+	for rows.Next() {
+		var i ListRoomMembersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Role,
+			&i.JoinedAt,
+			&i.Username,
+			&i.AvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockRoom = `-- name: LockRoom :one
+SELECT id
+FROM rooms
+WHERE id = $1
+    FOR UPDATE
+`
+
+func (q *Queries) LockRoom(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, lockRoom, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const removeRoomMember = `-- name: RemoveRoomMember :execrows
+DELETE
+FROM room_members
+WHERE room_id = $1
+  AND user_id = $2
+`
+
+type RemoveRoomMemberParams struct {
+	RoomID uuid.UUID `db:"room_id" json:"roomId"`
+	UserID uuid.UUID `db:"user_id" json:"userId"`
+}
+
+func (q *Queries) RemoveRoomMember(ctx context.Context, arg RemoveRoomMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeRoomMember, arg.RoomID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const roomExists = `-- name: RoomExists :one
 SELECT EXISTS (SELECT 1
                FROM rooms
@@ -165,3 +467,59 @@ func (q *Queries) RoomExists(ctx context.Context, id uuid.UUID) (bool, error) {
 	err := row.Scan(&exists)
 	return exists, err
 }
+
+const updateRoom = `-- name: UpdateRoom :one
+UPDATE rooms
+SET name       = $2,
+    visibility = $3,
+    max_pins   = $4
+WHERE id = $1
+RETURNING id, name, slug, created_at, visibility, max_pins
+`
+
+type UpdateRoomParams struct {
+	ID         uuid.UUID   `db:"id" json:"id"`
+	Name       pgtype.Text `db:"name" json:"name"`
+	Visibility string      `db:"visibility" json:"visibility"`
+	MaxPins    pgtype.Int4 `db:"max_pins" json:"maxPins"`
+}
+
+func (q *Queries) UpdateRoom(ctx context.Context, arg UpdateRoomParams) (Room, error) {
+	row := q.db.QueryRow(ctx, updateRoom,
+		arg.ID,
+		arg.Name,
+		arg.Visibility,
+		arg.MaxPins,
+	)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Slug,
+		&i.CreatedAt,
+		&i.Visibility,
+		&i.MaxPins,
+	)
+	return i, err
+}
+
+const updateRoomMemberRole = `-- name: UpdateRoomMemberRole :execrows
+UPDATE room_members
+SET role = $3
+WHERE room_id = $1
+  AND user_id = $2
+`
+
+type UpdateRoomMemberRoleParams struct {
+	RoomID uuid.UUID `db:"room_id" json:"roomId"`
+	UserID uuid.UUID `db:"user_id" json:"userId"`
+	Role   string    `db:"role" json:"role"`
+}
+
+func (q *Queries) UpdateRoomMemberRole(ctx context.Context, arg UpdateRoomMemberRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateRoomMemberRole, arg.RoomID, arg.UserID, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,125 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: search.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const searchMessages = `-- name: SearchMessages :many
+SELECT found.id, found.room_id, found.content, found.created_at, found.edited_at, found.thread_id, found.room_slug, found.room_name, found.sender_id, found.sender_username, found.sender_avatar_url, found.rank,
+       ts_headline('simple', found.content, websearch_to_tsquery('simple', $1::text),
+                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20')::text AS highlight
+FROM (SELECT m.id,
+             m.room_id,
+             m.content,
+             m.created_at,
+             m.edited_at,
+             m.thread_id,
+             r.slug                                                                                 AS room_slug,
+             r.name                                                                                 AS room_name,
+             u.id                                                                                   AS sender_id,
+             u.username                                                                             AS sender_username,
+             u.avatar_url                                                                           AS sender_avatar_url,
+             ts_rank(to_tsvector('simple', m.content), websearch_to_tsquery('simple', $1::text)) AS rank
+      FROM messages m
+               JOIN room_members rm ON rm.room_id = m.room_id AND rm.user_id = $2::uuid
+               JOIN rooms r ON r.id = m.room_id
+               JOIN users u ON u.id = m.sender_id
+      WHERE to_tsvector('simple', m.content) @@ websearch_to_tsquery('simple', $1::text)
+        AND m.deleted_at IS NULL
+        AND ($3::uuid IS NULL OR m.room_id = $3::uuid)
+        AND ($4::uuid IS NULL OR m.sender_id = $4::uuid)
+        AND ($5::timestamptz IS NULL OR m.created_at >= $5::timestamptz)
+        AND ($6::timestamptz IS NULL OR m.created_at < $6::timestamptz)
+        AND ($7::boolean IS NULL OR
+             $7::boolean =
+             EXISTS (SELECT 1 FROM attachments a WHERE a.message_id = m.id))) found
+WHERE $8::real IS NULL
+   OR (found.rank, found.created_at, found.id) <
+      ($8::real, $9::timestamptz, $10::uuid)
+ORDER BY found.rank DESC, found.created_at DESC, found.id DESC
+LIMIT $11
+`
+
+type SearchMessagesParams struct {
+	Query           string             `db:"query" json:"query"`
+	UserID          uuid.UUID          `db:"user_id" json:"userId"`
+	RoomID          pgtype.UUID        `db:"room_id" json:"roomId"`
+	SenderID        pgtype.UUID        `db:"sender_id" json:"senderId"`
+	SentAfter       pgtype.Timestamptz `db:"sent_after" json:"sentAfter"`
+	SentBefore      pgtype.Timestamptz `db:"sent_before" json:"sentBefore"`
+	HasAttachment   pgtype.Bool        `db:"has_attachment" json:"hasAttachment"`
+	CursorRank      pgtype.Float4      `db:"cursor_rank" json:"cursorRank"`
+	CursorCreatedAt pgtype.Timestamptz `db:"cursor_created_at" json:"cursorCreatedAt"`
+	CursorID        uuid.UUID          `db:"cursor_id" json:"cursorId"`
+	Limit           int32              `db:"limit_" json:"limit"`
+}
+
+type SearchMessagesRow struct {
+	ID              uuid.UUID          `db:"id" json:"id"`
+	RoomID          uuid.UUID          `db:"room_id" json:"roomId"`
+	Content         string             `db:"content" json:"content"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	EditedAt        pgtype.Timestamptz `db:"edited_at" json:"editedAt"`
+	ThreadID        pgtype.UUID        `db:"thread_id" json:"threadId"`
+	RoomSlug        string             `db:"room_slug" json:"roomSlug"`
+	RoomName        pgtype.Text        `db:"room_name" json:"roomName"`
+	SenderID        uuid.UUID          `db:"sender_id" json:"senderId"`
+	SenderUsername  string             `db:"sender_username" json:"senderUsername"`
+	SenderAvatarUrl pgtype.Text        `db:"sender_avatar_url" json:"senderAvatarUrl"`
+	Rank            float32            `db:"rank" json:"rank"`
+	Highlight       string             `db:"highlight" json:"highlight"`
+}
+
+func (q *Queries) SearchMessages(ctx context.Context, arg SearchMessagesParams) ([]SearchMessagesRow, error) {
+	rows, err := q.db.Query(ctx, searchMessages,
+		arg.Query,
+		arg.UserID,
+		arg.RoomID,
+		arg.SenderID,
+		arg.SentAfter,
+		arg.SentBefore,
+		arg.HasAttachment,
+		arg.CursorRank,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchMessagesRow{}
+	for rows.Next() {
+		var i SearchMessagesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.ThreadID,
+			&i.RoomSlug,
+			&i.RoomName,
+			&i.SenderID,
+			&i.SenderUsername,
+			&i.SenderAvatarUrl,
+			&i.Rank,
+			&i.Highlight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,188 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: thread.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const autoFollowThread = `-- name: AutoFollowThread :exec
+INSERT INTO thread_follows (thread_id, user_id, following, updated_at)
+VALUES ($1, $2, TRUE, $3)
+ON CONFLICT DO NOTHING
+`
+
+type AutoFollowThreadParams struct {
+	ThreadID  uuid.UUID          `db:"thread_id" json:"threadId"`
+	UserID    uuid.UUID          `db:"user_id" json:"userId"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updatedAt"`
+}
+
+func (q *Queries) AutoFollowThread(ctx context.Context, arg AutoFollowThreadParams) error {
+	_, err := q.db.Exec(ctx, autoFollowThread, arg.ThreadID, arg.UserID, arg.UpdatedAt)
+	return err
+}
+
+const listThreadFollowerIDs = `-- name: ListThreadFollowerIDs :many
+SELECT user_id
+FROM thread_follows
+WHERE thread_id = $1
+  AND following
+`
+
+func (q *Queries) ListThreadFollowerIDs(ctx context.Context, threadID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listThreadFollowerIDs, threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listThreadParticipants = `-- name: ListThreadParticipants :many
+SELECT t.thread_id::uuid AS thread_id,
+       u.id,
+       u.username,
+       u.avatar_url
+FROM (SELECT m.thread_id,
+             m.sender_id,
+             row_number() OVER (PARTITION BY m.thread_id ORDER BY max(m.created_at) DESC) AS rank
+      FROM messages m
+      WHERE m.thread_id = ANY ($1::uuid[])
+        AND m.deleted_at IS NULL
+      GROUP BY m.thread_id, m.sender_id) t
+         JOIN users u ON u.id = t.sender_id
+WHERE t.rank <= $2::int
+ORDER BY t.thread_id, t.rank
+`
+
+type ListThreadParticipantsParams struct {
+	ThreadIds       []uuid.UUID `db:"thread_ids" json:"threadIds"`
+	MaxParticipants int32       `db:"max_participants" json:"maxParticipants"`
+}
+
+type ListThreadParticipantsRow struct {
+	ThreadID  uuid.UUID   `db:"thread_id" json:"threadId"`
+	ID        uuid.UUID   `db:"id" json:"id"`
+	Username  string      `db:"username" json:"username"`
+	AvatarUrl pgtype.Text `db:"avatar_url" json:"avatarUrl"`
+}
+
+func (q *Queries) ListThreadParticipants(ctx context.Context, arg ListThreadParticipantsParams) ([]ListThreadParticipantsRow, error) {
+	rows, err := q.db.Query(ctx, listThreadParticipants, arg.ThreadIds, arg.MaxParticipants)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListThreadParticipantsRow{}
+	for rows.Next() {
+		var i ListThreadParticipantsRow
+		if err := rows.Scan(
+			&i.ThreadID,
+			&i.ID,
+			&i.Username,
+			&i.AvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listThreadSummaries = `-- name: ListThreadSummaries :many
+SELECT m.thread_id::uuid                  AS thread_id,
+       count(*)::int                      AS reply_count,
+       max(m.created_at)::timestamptz     AS last_reply_at,
+       EXISTS (SELECT 1
+               FROM thread_follows f
+               WHERE f.thread_id = m.thread_id
+                 AND f.user_id = $1::uuid
+                 AND f.following)::boolean AS following
+FROM messages m
+WHERE m.thread_id = ANY ($2::uuid[])
+  AND m.deleted_at IS NULL
+GROUP BY m.thread_id
+`
+
+type ListThreadSummariesParams struct {
+	ViewerID  uuid.UUID   `db:"viewer_id" json:"viewerId"`
+	ThreadIds []uuid.UUID `db:"thread_ids" json:"threadIds"`
+}
+
+type ListThreadSummariesRow struct {
+	ThreadID    uuid.UUID          `db:"thread_id" json:"threadId"`
+	ReplyCount  int32              `db:"reply_count" json:"replyCount"`
+	LastReplyAt pgtype.Timestamptz `db:"last_reply_at" json:"lastReplyAt"`
+	Following   bool               `db:"following" json:"following"`
+}
+
+func (q *Queries) ListThreadSummaries(ctx context.Context, arg ListThreadSummariesParams) ([]ListThreadSummariesRow, error) {
+	rows, err := q.db.Query(ctx, listThreadSummaries, arg.ViewerID, arg.ThreadIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListThreadSummariesRow{}
+	for rows.Next() {
+		var i ListThreadSummariesRow
+		if err := rows.Scan(
+			&i.ThreadID,
+			&i.ReplyCount,
+			&i.LastReplyAt,
+			&i.Following,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setThreadFollow = `-- name: SetThreadFollow :exec
+INSERT INTO thread_follows (thread_id, user_id, following, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (thread_id, user_id) DO UPDATE
+    SET following  = excluded.following,
+        updated_at = excluded.updated_at
+`
+
+type SetThreadFollowParams struct {
+	ThreadID  uuid.UUID          `db:"thread_id" json:"threadId"`
+	UserID    uuid.UUID          `db:"user_id" json:"userId"`
+	Following bool               `db:"following" json:"following"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updatedAt"`
+}
+
+func (q *Queries) SetThreadFollow(ctx context.Context, arg SetThreadFollowParams) error {
+	_, err := q.db.Exec(ctx, setThreadFollow,
+		arg.ThreadID,
+		arg.UserID,
+		arg.Following,
+		arg.UpdatedAt,
+	)
+	return err
+}
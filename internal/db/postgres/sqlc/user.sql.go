@@ -14,7 +14,7 @@ import (
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, username, email, password_hash, created_at, avatar_url, email_verified)
-VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, username, email, email_verified, password_hash, created_at, avatar_url
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, username, email, email_verified, password_hash, created_at, avatar_url, share_read_receipts
 `
 
 type CreateUserParams struct {
@@ -46,6 +46,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.AvatarUrl,
+		&i.ShareReadReceipts,
 	)
 	return i, err
 }
@@ -101,7 +102,7 @@ func (q *Queries) GetEmailVerificationCodeByEmail(ctx context.Context, pendingEm
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, username, email, email_verified, password_hash, created_at, avatar_url
+SELECT id, username, email, email_verified, password_hash, created_at, avatar_url, share_read_receipts
 FROM users
 WHERE id = $1 LIMIT 1
 `
@@ -117,12 +118,13 @@ func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.AvatarUrl,
+		&i.ShareReadReceipts,
 	)
 	return i, err
 }
 
 const getUserByLogin = `-- name: GetUserByLogin :one
-SELECT id, username, email, email_verified, password_hash, created_at, avatar_url
+SELECT id, username, email, email_verified, password_hash, created_at, avatar_url, share_read_receipts
 FROM users
 WHERE username = $1
    OR email = $1 LIMIT 1
@@ -139,6 +141,7 @@ func (q *Queries) GetUserByLogin(ctx context.Context, login string) (User, error
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.AvatarUrl,
+		&i.ShareReadReceipts,
 	)
 	return i, err
 }
@@ -213,6 +216,22 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 	return err
 }
 
+const updateUserShareReadReceipts = `-- name: UpdateUserShareReadReceipts :exec
+UPDATE users
+SET share_read_receipts = $1
+WHERE id = $2
+`
+
+type UpdateUserShareReadReceiptsParams struct {
+	ShareReadReceipts bool      `db:"share_read_receipts" json:"shareReadReceipts"`
+	ID                uuid.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateUserShareReadReceipts(ctx context.Context, arg UpdateUserShareReadReceiptsParams) error {
+	_, err := q.db.Exec(ctx, updateUserShareReadReceipts, arg.ShareReadReceipts, arg.ID)
+	return err
+}
+
 const upsertEmailVerificationCode = `-- name: UpsertEmailVerificationCode :exec
 INSERT INTO email_verification_codes (user_id, code_hash, pending_email, expires_at, attempts, created_at)
 VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (user_id) DO
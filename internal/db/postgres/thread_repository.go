@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	db "lunar/internal/db/postgres/sqlc"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxThreadParticipants is how many repliers a thread summary shows.
+const maxThreadParticipants = 3
+
+type ThreadRepository struct {
+	queries db.Querier
+}
+
+func NewThreadRepository(queries db.Querier) repository.ThreadRepository {
+	return &ThreadRepository{queries}
+}
+
+func (r *ThreadRepository) GetSummaries(ctx context.Context, threadIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID]model.ThreadSummary, error) {
+	rows, err := r.queries.ListThreadSummaries(ctx, db.ListThreadSummariesParams{
+		ViewerID:  viewerID,
+		ThreadIds: threadIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	participants, err := r.queries.ListThreadParticipants(ctx, db.ListThreadParticipantsParams{
+		ThreadIds:       threadIDs,
+		MaxParticipants: maxThreadParticipants,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	senders := make(map[uuid.UUID][]model.MessageSender)
+	for _, p := range participants {
+		senders[p.ThreadID] = append(senders[p.ThreadID], model.MessageSender{
+			ID:        p.ID,
+			Username:  p.Username,
+			AvatarURL: textOrEmpty(p.AvatarUrl),
+		})
+	}
+
+	summaries := make(map[uuid.UUID]model.ThreadSummary, len(rows))
+	for _, row := range rows {
+		summaries[row.ThreadID] = model.ThreadSummary{
+			ReplyCount:   int(row.ReplyCount),
+			LastReplyAt:  row.LastReplyAt.Time,
+			Participants: senders[row.ThreadID],
+			Following:    row.Following,
+		}
+	}
+	return summaries, nil
+}
+
+func (r *ThreadRepository) AutoFollow(ctx context.Context, threadID uuid.UUID, userIDs ...uuid.UUID) error {
+	now := timestampFromTime(time.Now())
+	for _, userID := range userIDs {
+		if err := r.queries.AutoFollowThread(ctx, db.AutoFollowThreadParams{
+			ThreadID:  threadID,
+			UserID:    userID,
+			UpdatedAt: now,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ThreadRepository) SetFollowing(ctx context.Context, threadID uuid.UUID, userID uuid.UUID, following bool) error {
+	return r.queries.SetThreadFollow(ctx, db.SetThreadFollowParams{
+		ThreadID:  threadID,
+		UserID:    userID,
+		Following: following,
+		UpdatedAt: timestampFromTime(time.Now()),
+	})
+}
+
+func (r *ThreadRepository) ListFollowerIDs(ctx context.Context, threadID uuid.UUID) ([]uuid.UUID, error) {
+	return r.queries.ListThreadFollowerIDs(ctx, threadID)
+}
@@ -24,12 +24,13 @@ func NewUserRepository(queries db.Querier) repository.UserRepository {
 
 func mapUser(user db.User) model.User {
 	return model.User{
-		ID:            user.ID,
-		Username:      user.Username,
-		PasswordHash:  user.PasswordHash.String,
-		Email:         user.Email,
-		AvatarURL:     user.AvatarUrl.String,
-		EmailVerified: user.EmailVerified,
+		ID:                user.ID,
+		Username:          user.Username,
+		PasswordHash:      user.PasswordHash.String,
+		Email:             user.Email,
+		AvatarURL:         user.AvatarUrl.String,
+		EmailVerified:     user.EmailVerified,
+		ShareReadReceipts: user.ShareReadReceipts,
 	}
 }
 
@@ -193,3 +194,10 @@ func (r *UserRepository) IncrementVerificationAttempts(ctx context.Context, user
 func (r *UserRepository) DeleteVerificationCode(ctx context.Context, userID uuid.UUID) error {
 	return r.queries.DeleteEmailVerificationCode(ctx, userID)
 }
+
+func (r *UserRepository) UpdateShareReadReceipts(ctx context.Context, id uuid.UUID, share bool) error {
+	return r.queries.UpdateUserShareReadReceipts(ctx, db.UpdateUserShareReadReceiptsParams{
+		ID:                id,
+		ShareReadReceipts: share,
+	})
+}
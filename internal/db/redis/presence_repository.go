@@ -0,0 +1,198 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// presenceScript applies a session change and reports the user's status
+// before and after it. Sessions live in a sorted set scored by expiry time as
+// "<session>:<status>"; the status before the change still counts expired
+// sessions so that reaping a crashed session is reported as going offline.
+//
+// KEYS: sessions, last seen, expiry index
+// ARGV: now, mode (set, remove or reap), session, status, session TTL,
+// last seen TTL, user
+var presenceScript = redis.NewScript(`
+local function aggregate(key)
+  local members = redis.call('ZRANGE', key, 0, -1)
+  if #members == 0 then return 'offline' end
+  for _, m in ipairs(members) do
+    if string.sub(m, -7) == ':online' then return 'online' end
+  end
+  return 'idle'
+end
+
+local now = tonumber(ARGV[1])
+local before = aggregate(KEYS[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if ARGV[2] ~= 'reap' then
+  redis.call('ZREM', KEYS[1], ARGV[3] .. ':online', ARGV[3] .. ':idle')
+  redis.call('SET', KEYS[2], now, 'PX', ARGV[6])
+end
+if ARGV[2] == 'set' then
+  redis.call('ZADD', KEYS[1], now + tonumber(ARGV[5]), ARGV[3] .. ':' .. ARGV[4])
+end
+
+local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+if #last == 0 then
+  redis.call('DEL', KEYS[1])
+  redis.call('ZREM', KEYS[3], ARGV[7])
+else
+  redis.call('PEXPIREAT', KEYS[1], tonumber(last[2]) + tonumber(ARGV[5]))
+  redis.call('ZADD', KEYS[3], last[2], ARGV[7])
+end
+
+return {before, aggregate(KEYS[1]), redis.call('GET', KEYS[2])}
+`)
+
+type PresenceRepository struct {
+	rdb         *redis.Client
+	keyPrefix   string
+	sessionTTL  time.Duration
+	lastSeenTTL time.Duration
+}
+
+func NewPresenceRepository(rdb *redis.Client, keyPrefix string, sessionTTL, lastSeenTTL time.Duration) repository.PresenceRepository {
+	return &PresenceRepository{
+		rdb:         rdb,
+		keyPrefix:   keyPrefix,
+		sessionTTL:  sessionTTL,
+		lastSeenTTL: lastSeenTTL,
+	}
+}
+
+func (r *PresenceRepository) SetSession(ctx context.Context, userID, sessionID uuid.UUID, status model.PresenceStatus) (model.Presence, bool, error) {
+	return r.run(ctx, "set", userID, sessionID, status)
+}
+
+func (r *PresenceRepository) RemoveSession(ctx context.Context, userID, sessionID uuid.UUID) (model.Presence, bool, error) {
+	return r.run(ctx, "remove", userID, sessionID, model.PresenceOffline)
+}
+
+func (r *PresenceRepository) ReapExpired(ctx context.Context, limit int) ([]model.Presence, error) {
+	ids, err := r.rdb.ZRangeByScore(ctx, r.expiryKey(), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []model.Presence
+	for _, id := range ids {
+		userID, err := uuid.Parse(id)
+		if err != nil {
+			r.rdb.ZRem(ctx, r.expiryKey(), id)
+			continue
+		}
+
+		presence, changed, err := r.run(ctx, "reap", userID, uuid.Nil, model.PresenceOffline)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			changes = append(changes, presence)
+		}
+	}
+	return changes, nil
+}
+
+func (r *PresenceRepository) GetPresences(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]model.Presence, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	pipe := r.rdb.Pipeline()
+	sessions := make([]*redis.StringSliceCmd, len(userIDs))
+	lastSeen := make([]*redis.StringCmd, len(userIDs))
+	for i, userID := range userIDs {
+		sessions[i] = pipe.ZRangeByScore(ctx, r.sessionsKey(userID), &redis.ZRangeBy{Min: "(" + now, Max: "+inf"})
+		lastSeen[i] = pipe.Get(ctx, r.lastSeenKey(userID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	presences := make(map[uuid.UUID]model.Presence, len(userIDs))
+	for i, userID := range userIDs {
+		presence := model.Presence{
+			UserID: userID,
+			Status: aggregateStatus(sessions[i].Val()),
+		}
+		if ms, err := lastSeen[i].Int64(); err == nil {
+			presence.LastSeenAt = timeFromMillis(ms)
+		}
+		presences[userID] = presence
+	}
+	return presences, nil
+}
+
+func (r *PresenceRepository) run(ctx context.Context, mode string, userID, sessionID uuid.UUID, status model.PresenceStatus) (model.Presence, bool, error) {
+	keys := []string{r.sessionsKey(userID), r.lastSeenKey(userID), r.expiryKey()}
+	args := []any{
+		time.Now().UnixMilli(),
+		mode,
+		sessionID.String(),
+		string(status),
+		r.sessionTTL.Milliseconds(),
+		r.lastSeenTTL.Milliseconds(),
+		userID.String(),
+	}
+
+	result, err := presenceScript.Run(ctx, r.rdb, keys, args...).Slice()
+	if err != nil {
+		return model.Presence{}, false, err
+	}
+
+	before, _ := result[0].(string)
+	after, _ := result[1].(string)
+
+	presence := model.Presence{
+		UserID: userID,
+		Status: model.PresenceStatus(after),
+	}
+	if lastSeen, ok := result[2].(string); ok {
+		if ms, err := strconv.ParseInt(lastSeen, 10, 64); err == nil {
+			presence.LastSeenAt = timeFromMillis(ms)
+		}
+	}
+
+	return presence, before != after, nil
+}
+
+func (r *PresenceRepository) sessionsKey(userID uuid.UUID) string {
+	return r.keyPrefix + "sessions:" + userID.String()
+}
+
+func (r *PresenceRepository) lastSeenKey(userID uuid.UUID) string {
+	return r.keyPrefix + "last_seen:" + userID.String()
+}
+
+func (r *PresenceRepository) expiryKey() string {
+	return r.keyPrefix + "expiry"
+}
+
+func aggregateStatus(sessions []string) model.PresenceStatus {
+	if len(sessions) == 0 {
+		return model.PresenceOffline
+	}
+	for _, session := range sessions {
+		if strings.HasSuffix(session, ":"+string(model.PresenceOnline)) {
+			return model.PresenceOnline
+		}
+	}
+	return model.PresenceIdle
+}
+
+func timeFromMillis(ms int64) *time.Time {
+	t := time.UnixMilli(ms)
+	return &t
+}
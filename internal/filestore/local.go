@@ -0,0 +1,121 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LocalStore keeps files on the local disk under a root directory. Its signed
+// URLs point at the store itself, served by ServeHTTP under publicURL.
+type LocalStore struct {
+	root   string
+	signer urlSigner
+}
+
+func NewLocalStore(root string, publicURL string, signingKey []byte) *LocalStore {
+	return &LocalStore{
+		root:   root,
+		signer: newURLSigner(publicURL, signingKey),
+	}
+}
+
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(cleanKey(key)))
+}
+
+func (s *LocalStore) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	filePath := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+
+	// Write next to the target and rename so readers never see a partial
+	// file.
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+func (s *LocalStore) Get(_ context.Context, key string) (*Object, error) {
+	f, err := s.open(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Object{
+		ReadCloser:  f,
+		Size:        info.Size(),
+		ContentType: contentTypeByKey(key),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+func (s *LocalStore) open(key string) (*os.File, error) {
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) SignedURL(_ context.Context, key string, ttl time.Duration, opts URLOptions) (string, error) {
+	return s.signer.signedURL(key, ttl, opts), nil
+}
+
+// ServeHTTP serves the files of signed URLs.
+func (s *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, opts, ok := s.signer.verify(r)
+	if !ok {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	f, err := s.open(key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	serveSigned(w, r, key, opts, info.ModTime(), f)
+}
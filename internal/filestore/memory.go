@@ -0,0 +1,95 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps files in memory. It is meant for tests and local runs
+// without a disk or bucket. Its signed URLs are served by ServeHTTP under
+// publicURL like those of LocalStore.
+type MemoryStore struct {
+	signer urlSigner
+
+	mu    sync.RWMutex
+	files map[string]memoryFile
+}
+
+type memoryFile struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+func NewMemoryStore(publicURL string, signingKey []byte) *MemoryStore {
+	return &MemoryStore{
+		signer: newURLSigner(publicURL, signingKey),
+		files:  make(map[string]memoryFile),
+	}
+}
+
+func (s *MemoryStore) Put(_ context.Context, key string, r io.Reader, _ int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[cleanKey(key)] = memoryFile{data: data, contentType: contentType, modTime: time.Now()}
+	return nil
+}
+
+func (s *MemoryStore) get(key string) (memoryFile, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	file, ok := s.files[cleanKey(key)]
+	return file, ok
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (*Object, error) {
+	file, ok := s.get(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &Object{
+		ReadCloser:  io.NopCloser(bytes.NewReader(file.data)),
+		Size:        int64(len(file.data)),
+		ContentType: file.contentType,
+		ModTime:     file.modTime,
+	}, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, cleanKey(key))
+	return nil
+}
+
+func (s *MemoryStore) SignedURL(_ context.Context, key string, ttl time.Duration, opts URLOptions) (string, error) {
+	return s.signer.signedURL(key, ttl, opts), nil
+}
+
+// ServeHTTP serves the files of signed URLs.
+func (s *MemoryStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, opts, ok := s.signer.verify(r)
+	if !ok {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	file, ok := s.get(key)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if opts.ContentType == "" {
+		opts.ContentType = file.contentType
+	}
+	serveSigned(w, r, key, opts, file.modTime, bytes.NewReader(file.data))
+}
@@ -0,0 +1,116 @@
+package filestore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signedRequest turns a signed URL into the request the store's route would
+// pass on, with the key in the "*" path value.
+func signedRequest(t *testing.T, signedURL string) *http.Request {
+	t.Helper()
+	u, err := url.Parse(signedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodGet, signedURL, nil)
+	r.SetPathValue("*", strings.TrimPrefix(u.Path, "/files/"))
+	return r
+}
+
+func TestURLSignerVerify(t *testing.T) {
+	signer := newURLSigner("https://example.com/files/", []byte("secret"))
+	opts := URLOptions{ContentType: "image/png", ContentDisposition: "inline"}
+
+	tests := []struct {
+		name   string
+		ttl    time.Duration
+		tamper func(r *http.Request)
+		want   bool
+	}{
+		{"valid", time.Minute, func(*http.Request) {}, true},
+		{"expired", -time.Minute, func(*http.Request) {}, false},
+		{"other key", time.Minute, func(r *http.Request) {
+			r.SetPathValue("*", "rooms/other.png")
+		}, false},
+		{"other content type", time.Minute, func(r *http.Request) {
+			query := r.URL.Query()
+			query.Set("type", "text/html")
+			r.URL.RawQuery = query.Encode()
+		}, false},
+		{"later expiry", time.Minute, func(r *http.Request) {
+			query := r.URL.Query()
+			query.Set("expires", "9999999999")
+			r.URL.RawQuery = query.Encode()
+		}, false},
+		{"bad signature", time.Minute, func(r *http.Request) {
+			query := r.URL.Query()
+			query.Set("signature", strings.Repeat("0", 64))
+			r.URL.RawQuery = query.Encode()
+		}, false},
+		{"no signature", time.Minute, func(r *http.Request) {
+			query := r.URL.Query()
+			query.Del("signature")
+			r.URL.RawQuery = query.Encode()
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := signedRequest(t, signer.signedURL("rooms/image.png", tt.ttl, opts))
+			tt.tamper(r)
+
+			key, gotOpts, ok := signer.verify(r)
+			if ok != tt.want {
+				t.Fatalf("verify = %t, want %t", ok, tt.want)
+			}
+			if ok && (key != "rooms/image.png" || gotOpts != opts) {
+				t.Errorf("verify = %q %+v, want %q %+v", key, gotOpts, "rooms/image.png", opts)
+			}
+		})
+	}
+}
+
+func TestURLSignerRejectsOtherSigningKey(t *testing.T) {
+	signer := newURLSigner("https://example.com/files", []byte("secret"))
+	other := newURLSigner("https://example.com/files", []byte("other secret"))
+
+	r := signedRequest(t, other.signedURL("rooms/image.png", time.Minute, URLOptions{}))
+	if _, _, ok := signer.verify(r); ok {
+		t.Error("verify accepted a URL signed with another key")
+	}
+}
+
+func TestMemoryStoreServesSignedURL(t *testing.T) {
+	store := NewMemoryStore("https://example.com/files", []byte("secret"))
+	ctx := context.Background()
+	if err := store.Put(ctx, "rooms/note.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	signedURL, err := store.SignedURL(ctx, "rooms/note.txt", time.Minute, URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	store.ServeHTTP(w, signedRequest(t, signedURL))
+	body, _ := io.ReadAll(w.Result().Body)
+	if w.Code != http.StatusOK || string(body) != "hello" {
+		t.Errorf("ServeHTTP = %d %q, want 200 %q", w.Code, body, "hello")
+	}
+
+	expiredURL, err := store.SignedURL(ctx, "rooms/note.txt", -time.Minute, URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	store.ServeHTTP(w, signedRequest(t, expiredURL))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("ServeHTTP of an expired URL = %d, want 403", w.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+// Store keeps files under slash-separated keys. Implementations are safe for
+// concurrent use.
+type Store interface {
+	// Put stores size bytes read from r under key, replacing any file there.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the file under key. It fails with ErrNotFound if there is
+	// none. The caller closes the object.
+	Get(ctx context.Context, key string) (*Object, error)
+	// Delete removes the file under key. Removing a missing file is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL the file can be downloaded from without further
+	// authorization until ttl has passed.
+	SignedURL(ctx context.Context, key string, ttl time.Duration, opts URLOptions) (string, error)
+}
+
+// Object is an open file of a store.
+type Object struct {
+	io.ReadCloser
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// URLOptions override the headers a signed URL is served with.
+type URLOptions struct {
+	ContentType        string
+	ContentDisposition string
+}
+
+// WithPrefix scopes a store to the keys under prefix, so that for example
+// avatars and attachments can share one bucket.
+func WithPrefix(store Store, prefix string) Store {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" || prefix == "." {
+		return store
+	}
+	return &prefixedStore{store, prefix}
+}
+
+type prefixedStore struct {
+	store  Store
+	prefix string
+}
+
+func (s *prefixedStore) key(key string) string {
+	return path.Join(s.prefix, key)
+}
+
+func (s *prefixedStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return s.store.Put(ctx, s.key(key), r, size, contentType)
+}
+
+func (s *prefixedStore) Get(ctx context.Context, key string) (*Object, error) {
+	return s.store.Get(ctx, s.key(key))
+}
+
+func (s *prefixedStore) Delete(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, s.key(key))
+}
+
+func (s *prefixedStore) SignedURL(ctx context.Context, key string, ttl time.Duration, opts URLOptions) (string, error) {
+	return s.store.SignedURL(ctx, s.key(key), ttl, opts)
+}
+
+// cleanKey resolves key against the store root so it can't point outside it.
+func cleanKey(key string) string {
+	return strings.TrimPrefix(path.Clean("/"+key), "/")
+}
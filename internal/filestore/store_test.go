@@ -0,0 +1,40 @@
+package filestore
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"rooms/image.png", "rooms/image.png"},
+		{"/rooms/image.png", "rooms/image.png"},
+		{"rooms//./image.png", "rooms/image.png"},
+		{"rooms/../image.png", "image.png"},
+		{"../etc/passwd", "etc/passwd"},
+		{"rooms/../../../etc/passwd", "etc/passwd"},
+		{"..", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := cleanKey(tt.key); got != tt.want {
+			t.Errorf("cleanKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLocalStorePathStaysUnderRoot(t *testing.T) {
+	root := t.TempDir()
+	store := NewLocalStore(root, "https://example.com/files", []byte("secret"))
+
+	for _, key := range []string{"../secret", "a/../../secret", "/../../secret", "..\\secret"} {
+		rel, err := filepath.Rel(root, store.path(key))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			t.Errorf("path(%q) = %q is outside the root", key, store.path(key))
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package dto
 
 type FriendWithInfo struct {
-	ID        string `json:"id"`
-	Username  string `json:"username"`
-	AvatarURL string `json:"avatarUrl"`
+	ID         string  `json:"id"`
+	Username   string  `json:"username"`
+	AvatarURL  string  `json:"avatarUrl"`
+	Status     string  `json:"status,omitempty"`
+	LastSeenAt *string `json:"lastSeenAt,omitempty"`
 }
 
 type FriendRequestWithInfo struct {
@@ -3,6 +3,9 @@ package friendship
 import (
 	db "lunar/internal/db/postgres/sqlc"
 	"lunar/internal/friendship/dto"
+	"lunar/internal/model"
+
+	"github.com/google/uuid"
 )
 
 func mapFriendWithInfo(row db.ListFriendsWithUsersRow) dto.FriendWithInfo {
@@ -13,10 +16,19 @@ func mapFriendWithInfo(row db.ListFriendsWithUsersRow) dto.FriendWithInfo {
 	}
 }
 
-func mapFriendsWithInfo(rows []db.ListFriendsWithUsersRow) []dto.FriendWithInfo {
+func mapFriendsWithPresence(rows []db.ListFriendsWithUsersRow, presences map[uuid.UUID]model.Presence) []dto.FriendWithInfo {
 	friends := make([]dto.FriendWithInfo, 0, len(rows))
 	for _, row := range rows {
-		friends = append(friends, mapFriendWithInfo(row))
+		friend := mapFriendWithInfo(row)
+
+		presence := presences[row.FriendUserID]
+		friend.Status = string(presence.Status)
+		if presence.LastSeenAt != nil {
+			lastSeenAt := presence.LastSeenAt.Format("2006-01-02T15:04:05Z07:00")
+			friend.LastSeenAt = &lastSeenAt
+		}
+
+		friends = append(friends, friend)
 	}
 	return friends
 }
@@ -20,14 +20,16 @@ var (
 )
 
 type FriendshipService struct {
-	repo     repository.FriendshipRepository
-	userRepo repository.UserRepository
+	repo         repository.FriendshipRepository
+	userRepo     repository.UserRepository
+	presenceRepo repository.PresenceRepository
 }
 
-func NewFriendshipService(repo repository.FriendshipRepository, userRepo repository.UserRepository) *FriendshipService {
+func NewFriendshipService(repo repository.FriendshipRepository, userRepo repository.UserRepository, presenceRepo repository.PresenceRepository) *FriendshipService {
 	return &FriendshipService{
-		repo:     repo,
-		userRepo: userRepo,
+		repo:         repo,
+		userRepo:     userRepo,
+		presenceRepo: presenceRepo,
 	}
 }
 
@@ -116,7 +118,18 @@ func (s *FriendshipService) ListFriendsWithInfo(ctx context.Context, userID uuid
 	if err != nil {
 		return nil, err
 	}
-	return mapFriendsWithInfo(rows), nil
+
+	friendIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		friendIDs = append(friendIDs, row.FriendUserID)
+	}
+
+	presences, err := s.presenceRepo.GetPresences(ctx, friendIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapFriendsWithPresence(rows, presences), nil
 }
 
 func (s *FriendshipService) ListIncomingRequestsWithInfo(ctx context.Context, userID uuid.UUID) ([]dto.FriendRequestWithInfo, error) {
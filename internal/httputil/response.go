@@ -100,6 +100,13 @@ func Conflict(w http.ResponseWriter, message string) {
 	Error(w, http.StatusConflict, "conflict", message)
 }
 
+func Gone(w http.ResponseWriter, message string) {
+	if message == "" {
+		message = "Resource gone"
+	}
+	Error(w, http.StatusGone, "gone", message)
+}
+
 func InternalError(w http.ResponseWriter, r *http.Request, err error) {
 	slog.ErrorContext(
 		r.Context(),
@@ -1,6 +1,7 @@
 package livekit
 
 import (
+	"errors"
 	"lunar/internal/httputil"
 	"net/http"
 )
@@ -15,21 +16,32 @@ func NewHandler(service *Service) *Handler {
 
 // Token godoc
 //
-//	@Summary	Get livekit access token
-//	@Tags		livekit
-//	@Produce	json
-//	@Security	BearerAuth
-//	@Param		roomSlug	path		string	true	"Room Slug"
-//	@Success	200			{object}	TokenResponse
-//	@Failure	400			{object}	httputil.ErrorResponse
-//	@Failure	500			{object}	httputil.ErrorResponse
-//	@Router		/livekit/token/{roomSlug} [get]
+//	@Summary		Get livekit access token
+//	@Tags			livekit
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Description	Only members of the room get a token. Moderators and above may also mute and remove participants of the call.
+//	@Success		200	{object}	TokenResponse
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/livekit/token/{roomSlug} [get]
 func (h *Handler) Token(w http.ResponseWriter, r *http.Request) {
 	user := httputil.UserFromRequest(r)
 	roomSlug := r.PathValue("roomSlug")
 
-	token, err := h.service.GenerateToken(roomSlug, user.ID)
+	token, err := h.service.GenerateToken(r.Context(), roomSlug, user.ID)
 	if err != nil {
+		switch {
+		case errors.Is(err, ErrRoomNotFound):
+			httputil.NotFound(w, "Room not found")
+			return
+		case errors.Is(err, ErrNotRoomMember):
+			httputil.Forbidden(w, "You are not a member of this room")
+			return
+		}
 		httputil.InternalError(w, r, err)
 		return
 	}
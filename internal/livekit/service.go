@@ -1,27 +1,44 @@
 package livekit
 
 import (
+	"context"
+	"lunar/internal/access"
+	"lunar/internal/model"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/livekit/protocol/auth"
 )
 
+var (
+	ErrRoomNotFound  = access.ErrRoomNotFound
+	ErrNotRoomMember = access.ErrNotRoomMember
+)
+
 type Service struct {
+	access    *access.Checker
 	apiKey    string
 	apiSecret string
 }
 
-func NewService(apiKey, apiSecret string) *Service {
-	return &Service{apiKey, apiSecret}
+func NewService(access *access.Checker, apiKey, apiSecret string) *Service {
+	return &Service{access, apiKey, apiSecret}
 }
 
-func (s *Service) GenerateToken(roomSlug string, userID uuid.UUID) (string, error) {
+// GenerateToken lets a member join the call of a room. Members who may kick
+// from the room may also mute and remove participants of the call.
+func (s *Service) GenerateToken(ctx context.Context, roomSlug string, userID uuid.UUID) (string, error) {
+	_, role, err := s.access.Member(ctx, userID, roomSlug)
+	if err != nil {
+		return "", err
+	}
+
 	at := auth.NewAccessToken(s.apiKey, s.apiSecret)
 
 	at.AddGrant(&auth.VideoGrant{
-		RoomJoin: true,
-		Room:     roomSlug,
+		RoomJoin:  true,
+		Room:      roomSlug,
+		RoomAdmin: role.Can(model.PermKickMembers),
 	})
 
 	at.SetIdentity(userID.String())
@@ -0,0 +1,301 @@
+package message
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"lunar/internal/filestore"
+	"lunar/internal/model"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+	"github.com/google/uuid"
+)
+
+const (
+	// thumbnailSize bounds the width and height of image thumbnails.
+	thumbnailSize = 320
+	// maxThumbnailPixels keeps huge images from being decoded in full.
+	maxThumbnailPixels = 40_000_000
+)
+
+var ErrInvalidImage = errors.New("invalid image")
+
+// UploadAttachment stores a file uploaded to the room by the user. The type
+// is sniffed from the content rather than trusted from the client. Images get
+// their dimensions recorded and a thumbnail. The attachment stays unsent
+// until it is attached to a message.
+func (s *Service) UploadAttachment(ctx context.Context, userID uuid.UUID, roomSlug string, name string, file io.ReadSeeker, size int64) (model.Attachment, error) {
+	if size > s.maxAttachmentSize {
+		return model.Attachment{}, model.ErrAttachmentTooLarge
+	}
+
+	room, err := s.getMemberRoom(ctx, userID, roomSlug)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+
+	contentType, err := sniffContentType(file)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+
+	attachment, err := model.NewAttachment(room.ID, userID, name, contentType, size)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+
+	if err := s.attachments.Put(ctx, attachment.StorageKey, file, size, contentType); err != nil {
+		return model.Attachment{}, err
+	}
+
+	if attachment.IsImage() {
+		if err := s.processImage(ctx, &attachment, file); err != nil {
+			s.removeFiles(ctx, attachment)
+			return model.Attachment{}, err
+		}
+	}
+
+	created, err := s.attachmentRepo.CreateAttachment(ctx, attachment)
+	if err != nil {
+		s.removeFiles(ctx, attachment)
+		return model.Attachment{}, err
+	}
+	return created, nil
+}
+
+// AttachmentURL returns a short-lived URL to download an attachment of a room
+// the user is a member of. Unsent uploads can only be downloaded by their
+// uploader. Images, audio and video are shown inline, anything else is
+// downloaded.
+func (s *Service) AttachmentURL(ctx context.Context, userID uuid.UUID, roomSlug string, attachmentID uuid.UUID) (string, error) {
+	attachment, err := s.getMemberAttachment(ctx, userID, roomSlug, attachmentID)
+	if err != nil {
+		return "", err
+	}
+
+	disposition := "attachment"
+	if isInlineType(attachment.ContentType) {
+		disposition = "inline"
+	}
+	return s.attachments.SignedURL(ctx, attachment.StorageKey, s.attachmentURLTTL, filestore.URLOptions{
+		ContentType:        attachment.ContentType,
+		ContentDisposition: mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}),
+	})
+}
+
+// ThumbnailURL returns a short-lived URL to the thumbnail of an image
+// attachment like AttachmentURL.
+func (s *Service) ThumbnailURL(ctx context.Context, userID uuid.UUID, roomSlug string, attachmentID uuid.UUID) (string, error) {
+	attachment, err := s.getMemberAttachment(ctx, userID, roomSlug, attachmentID)
+	if err != nil {
+		return "", err
+	}
+	if !attachment.HasThumbnail {
+		return "", ErrAttachmentNotFound
+	}
+
+	return s.attachments.SignedURL(ctx, attachment.ThumbnailKey, s.attachmentURLTTL, filestore.URLOptions{
+		ContentType:        thumbnailContentType(attachment),
+		ContentDisposition: "inline",
+	})
+}
+
+// AttachFiles attaches uploads of the sender to a message about to be sent.
+func (s *Service) AttachFiles(ctx context.Context, msg *model.Message, attachmentIDs []uuid.UUID) error {
+	if len(attachmentIDs) > model.MaxMessageAttachments {
+		return model.ErrTooManyAttachments
+	}
+
+	attachments, err := s.attachmentRepo.ListAttachmentsByIDs(ctx, attachmentIDs)
+	if err != nil {
+		return err
+	}
+	// Missing and duplicate IDs both leave the counts apart.
+	if len(attachments) != len(attachmentIDs) {
+		return model.ErrAttachmentUnavailable
+	}
+
+	byID := make(map[uuid.UUID]model.Attachment, len(attachments))
+	for _, a := range attachments {
+		byID[a.ID] = a
+	}
+	ordered := make([]model.Attachment, len(attachmentIDs))
+	for i, id := range attachmentIDs {
+		ordered[i] = byID[id]
+	}
+	return msg.Attach(ordered)
+}
+
+func (s *Service) attachAttachments(ctx context.Context, messages []model.Message) error {
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, message := range messages {
+		if message.DeletedAt == nil {
+			ids = append(ids, message.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	attachments, err := s.attachmentRepo.ListAttachments(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		if messages[i].DeletedAt == nil {
+			messages[i].Attachments = attachments[messages[i].ID]
+		}
+	}
+	return nil
+}
+
+// getMemberAttachment loads an attachment of a room the user is a member of.
+func (s *Service) getMemberAttachment(ctx context.Context, userID uuid.UUID, roomSlug string, attachmentID uuid.UUID) (model.Attachment, error) {
+	room, err := s.getMemberRoom(ctx, userID, roomSlug)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+
+	attachment, err := s.attachmentRepo.GetAttachment(ctx, attachmentID)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	if attachment.RoomID != room.ID {
+		return model.Attachment{}, ErrAttachmentNotFound
+	}
+	if attachment.MessageID == nil && attachment.UploaderID != userID {
+		return model.Attachment{}, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// processImage records the dimensions of an image attachment and stores its
+// thumbnail.
+func (s *Service) processImage(ctx context.Context, attachment *model.Attachment, file io.ReadSeeker) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		// WebP has no decoder here; such images are kept without a
+		// thumbnail.
+		if errors.Is(err, image.ErrFormat) {
+			return nil
+		}
+		return ErrInvalidImage
+	}
+	attachment.Width, attachment.Height = &config.Width, &config.Height
+	if config.Width*config.Height > maxThumbnailPixels {
+		return nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	img, err := imaging.Decode(file, imaging.AutoOrientation(true))
+	if err != nil {
+		return ErrInvalidImage
+	}
+	// Orientation may have swapped the sides.
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	attachment.Width, attachment.Height = &width, &height
+
+	thumbnail := imaging.Fit(img, thumbnailSize, thumbnailSize, imaging.Lanczos)
+
+	var buf bytes.Buffer
+	key := attachment.StorageKey + "_thumb.jpg"
+	if thumbnailContentType(*attachment) == "image/png" {
+		key = attachment.StorageKey + "_thumb.png"
+		err = png.Encode(&buf, thumbnail)
+	} else {
+		err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 80})
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.attachments.Put(ctx, key, &buf, int64(buf.Len()), thumbnailContentType(*attachment)); err != nil {
+		return err
+	}
+	attachment.ThumbnailKey = key
+	attachment.HasThumbnail = true
+	return nil
+}
+
+// thumbnailContentType tells how the thumbnail of an image is encoded.
+// Thumbnails of PNG and GIF images stay PNG to keep transparency.
+func thumbnailContentType(attachment model.Attachment) string {
+	if attachment.ContentType == "image/jpeg" {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// purgeAttachments removes expired attachments and their files in batches.
+func (s *Service) purgeAttachments(ctx context.Context, unsentBefore, deletedBefore time.Time) {
+	for {
+		attachments, err := s.attachmentRepo.DeleteExpired(ctx, unsentBefore, deletedBefore, purgeBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("purge attachments", "err", err)
+			}
+			return
+		}
+		for _, attachment := range attachments {
+			s.removeFiles(ctx, attachment)
+		}
+		if len(attachments) > 0 {
+			slog.Info("purged attachments", "count", len(attachments))
+		}
+		if len(attachments) < purgeBatchSize {
+			return
+		}
+	}
+}
+
+func (s *Service) removeFiles(ctx context.Context, attachment model.Attachment) {
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.attachments.Delete(ctx, key); err != nil {
+			slog.Warn("remove attachment file", "key", key, "err", err)
+		}
+	}
+}
+
+// isInlineType tells whether browsers can show the content type in place
+// without running anything from it.
+func isInlineType(contentType string) bool {
+	return strings.HasPrefix(contentType, "image/") ||
+		strings.HasPrefix(contentType, "audio/") ||
+		strings.HasPrefix(contentType, "video/")
+}
+
+// sniffContentType detects the media type from the start of the file and
+// rewinds it.
+func sniffContentType(file io.ReadSeeker) (string, error) {
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(header[:n]))
+	if err != nil {
+		return "", model.ErrUnsupportedAttachmentType
+	}
+	return mediaType, nil
+}
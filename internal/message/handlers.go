@@ -1,69 +1,697 @@
 package message
 
 import (
+	"context"
 	"errors"
 	"lunar/internal/httputil"
+	"lunar/internal/model"
 	"lunar/internal/pagination"
+	"lunar/internal/ws"
 	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
 )
 
 type Handler struct {
-	validate *httputil.Validator
-	service  *Service
+	validate  *httputil.Validator
+	service   *Service
+	wsService *ws.Service
 }
 
-func NewHandler(validator *httputil.Validator, service *Service) *Handler {
+func NewHandler(validator *httputil.Validator, service *Service, wsService *ws.Service) *Handler {
 	return &Handler{
-		validate: validator,
-		service:  service,
+		validate:  validator,
+		service:   service,
+		wsService: wsService,
 	}
 }
 
 // ListMessages lists messages in a room
 //
-//	@Summary	List messages in a room
-//	@Tags		message
-//	@Produce	json
-//	@Security	BearerAuth
-//	@Param		roomSlug	path		string	true	"Room Slug"
-//	@Param		limit		query		int		false	"Limit"
-//	@Param		cursor		query		string	false	"Cursor"
-//	@Success	200			{object}	GetPagingResponse
-//	@Failure	400			{object}	httputil.ErrorResponse
-//	@Failure	500			{object}	httputil.ErrorResponse
-//	@Router		/rooms/{roomSlug}/messages [get]
+//	@Summary		List messages in a room
+//	@Tags			message
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			limit		query	int		false	"Limit"
+//	@Param			cursor		query	string	false	"Alias of before"
+//	@Param			before		query	string	false	"List messages older than this cursor"
+//	@Param			after		query	string	false	"List messages newer than this cursor"
+//	@Param			around		query	string	false	"List this message and the messages around it"
+//	@Description	Lists the room timeline newest first. Only members of the room can read it. Without before, after or around the latest messages are listed; at most one of them may be given. nextCursor continues with older messages through before and prevCursor with newer ones through after. Both are empty when there is nothing more on their side.
+//	@Success		200	{object}	GetPagingResponse
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/messages [get]
 func (h *Handler) ListMessages(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	query := r.URL.Query()
 	roomSlug := r.PathValue("roomSlug")
-	limit := normalizeLimit(r.URL.Query().Get("limit"), 100, 32)
+	limit := pagination.NormalizeLimit(query.Get("limit"), 100, 32)
 
-	var cursor *pagination.Cursor
-	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
-		c, err := h.service.ParseCursor(cursorStr)
+	before := query.Get("before")
+	if before == "" {
+		before = query.Get("cursor")
+	}
+	after := query.Get("after")
+	around := query.Get("around")
+
+	given := 0
+	for _, param := range []string{before, after, around} {
+		if param != "" {
+			given++
+		}
+	}
+	if given > 1 {
+		httputil.BadRequest(w, "Only one of before, after and around can be given")
+		return
+	}
+
+	var page Page
+	switch {
+	case before != "":
+		c, err := pagination.ParseCursor(before)
 		if err != nil {
 			httputil.BadRequest(w, "Invalid cursor")
 			return
 		}
-		cursor = &c
+		page.Before = &c
+	case after != "":
+		c, err := pagination.ParseCursor(after)
+		if err != nil {
+			httputil.BadRequest(w, "Invalid cursor")
+			return
+		}
+		page.After = &c
+	case around != "":
+		id, err := uuid.Parse(around)
+		if err != nil {
+			httputil.BadRequest(w, "Invalid message ID")
+			return
+		}
+		page.Around = &id
+	}
+
+	user := httputil.UserFromRequest(r)
+	result, err := h.service.ListMessages(ctx, user.ID, roomSlug, limit, page)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRoomNotFound):
+			httputil.NotFound(w, "Room not found")
+			return
+		case errors.Is(err, ErrNotRoomMember):
+			httputil.Forbidden(w, "You are not a member of this room")
+			return
+		case errors.Is(err, ErrMessageNotFound):
+			httputil.NotFound(w, "Message not found")
+			return
+		case errors.Is(err, ErrMessageInThread):
+			httputil.BadRequest(w, "The message is in a thread")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	response := GetPagingResponse{Messages: result.Messages}
+	if n := len(result.Messages); n > 0 {
+		if result.HasOlder {
+			response.NextCursor = h.service.GenerateCursor(result.Messages[n-1])
+		}
+		if result.HasNewer {
+			response.PrevCursor = h.service.GenerateCursor(result.Messages[0])
+		}
+	}
+
+	httputil.SuccessData(w, response)
+}
+
+// ListReadReceipts lists who has read a message
+//
+//	@Summary		List read receipts of a message
+//	@Tags			message
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			messageId	path	string	true	"Message ID"
+//	@Description	Lists the members who have read up to the message. Members who turned off read receipts are left out.
+//	@Success		200	{object}	ReadReceiptsResponse
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/messages/{messageId}/receipts [get]
+func (h *Handler) ListReadReceipts(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	messageID, err := uuid.Parse(r.PathValue("messageId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid message ID")
+		return
+	}
+
+	receipts, err := h.service.ListReadReceipts(r.Context(), user.ID, roomSlug, messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRoomNotFound):
+			httputil.NotFound(w, "Room not found")
+			return
+		case errors.Is(err, ErrNotRoomMember):
+			httputil.Forbidden(w, "You are not a member of this room")
+			return
+		case errors.Is(err, ErrMessageNotFound):
+			httputil.NotFound(w, "Message not found")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	httputil.SuccessData(w, ReadReceiptsResponse{Receipts: receipts})
+}
+
+// EditMessage edits a message
+//
+//	@Summary		Edit a message
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string				true	"Room Slug"
+//	@Param			messageId	path	string				true	"Message ID"
+//	@Param			input		body	EditMessageRequest	true	"New content"
+//	@Description	Replaces the content of a message sent by the current user. The previous content is kept as a revision and the room receives a message.updated event.
+//	@Success		200	{object}	model.Message
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		422	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/messages/{messageId} [patch]
+func (h *Handler) EditMessage(w http.ResponseWriter, r *http.Request) {
+	messageID, err := uuid.Parse(r.PathValue("messageId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid message ID")
+		return
+	}
+
+	var req EditMessageRequest
+	if err := httputil.Read(r, &req); err != nil {
+		httputil.InvalidRequestBody(w)
+		return
+	}
+
+	if fieldErrs := h.validate.Validate(&req); fieldErrs != nil {
+		httputil.ValidationError(w, fieldErrs)
+		return
+	}
+
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	room, message, err := h.service.EditMessage(r.Context(), user.ID, roomSlug, messageID, req.Content)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRoomNotFound):
+			httputil.NotFound(w, "Room not found")
+			return
+		case errors.Is(err, ErrNotRoomMember):
+			httputil.Forbidden(w, "You are not a member of this room")
+			return
+		case errors.Is(err, ErrMessageNotFound):
+			httputil.NotFound(w, "Message not found")
+			return
+		case errors.Is(err, model.ErrNotMessageAuthor):
+			httputil.Forbidden(w, "Only the author can edit a message")
+			return
+		case errors.Is(err, model.ErrMessageDeleted):
+			httputil.NotFound(w, "Message has been deleted")
+			return
+		case errors.Is(err, model.ErrInvalidMessageContent):
+			httputil.BadRequest(w, "Invalid message content")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	h.wsService.PublishRoomEvent(r.Context(), room, ws.EventMessageUpdated, message)
+
+	httputil.SuccessData(w, message)
+}
+
+// ListRevisions lists the previous versions of a message
+//
+//	@Summary		List the edit history of a message
+//	@Tags			message
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			messageId	path	string	true	"Message ID"
+//	@Description	Lists the content a message had before each edit, oldest first. Only moderators, admins and the owner may see it.
+//	@Success		200	{object}	RevisionsResponse
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/messages/{messageId}/revisions [get]
+func (h *Handler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	messageID, err := uuid.Parse(r.PathValue("messageId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid message ID")
+		return
+	}
+
+	revisions, err := h.service.ListRevisions(r.Context(), user.ID, roomSlug, messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRoomNotFound):
+			httputil.NotFound(w, "Room not found")
+			return
+		case errors.Is(err, ErrNotRoomMember):
+			httputil.Forbidden(w, "You are not a member of this room")
+			return
+		case errors.Is(err, ErrPermissionDenied):
+			httputil.Forbidden(w, "You are not allowed to see the edit history")
+			return
+		case errors.Is(err, ErrMessageNotFound):
+			httputil.NotFound(w, "Message not found")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	httputil.SuccessData(w, RevisionsResponse{Revisions: revisions})
+}
+
+// DeleteMessage deletes a message
+//
+//	@Summary		Delete a message
+//	@Tags			message
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			messageId	path	string	true	"Message ID"
+//	@Description	Replaces a message with a tombstone. Authors can delete their own messages, moderators and above those of members ranked below them. The room receives a message.deleted event.
+//	@Success		200
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/messages/{messageId} [delete]
+func (h *Handler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	messageID, err := uuid.Parse(r.PathValue("messageId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid message ID")
+		return
+	}
+
+	room, message, err := h.service.DeleteMessage(r.Context(), user.ID, roomSlug, messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRoomNotFound):
+			httputil.NotFound(w, "Room not found")
+			return
+		case errors.Is(err, ErrNotRoomMember):
+			httputil.Forbidden(w, "You are not a member of this room")
+			return
+		case errors.Is(err, ErrMessageNotFound), errors.Is(err, model.ErrMessageDeleted):
+			httputil.NotFound(w, "Message not found")
+			return
+		case errors.Is(err, model.ErrNotMessageAuthor):
+			httputil.Forbidden(w, "Only the author or a higher-ranked moderator can delete a message")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	h.wsService.PublishRoomEvent(r.Context(), room, ws.EventMessageDeleted, message)
+
+	httputil.Success(w)
+}
+
+// AddReaction reacts to a message
+//
+//	@Summary		React to a message
+//	@Tags			message
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			messageId	path	string	true	"Message ID"
+//	@Param			emoji		path	string	true	"Emoji"
+//	@Description	Adds a reaction of the current user. Reacting twice with the same emoji has no effect. A message takes at most 20 distinct emojis. The room receives a reaction.added event.
+//	@Success		200	{object}	model.Reaction
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		409	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/messages/{messageId}/reactions/{emoji} [put]
+func (h *Handler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	h.changeReaction(w, r, h.service.AddReaction, ws.EventReactionAdded)
+}
+
+// RemoveReaction takes back a reaction to a message
+//
+//	@Summary		Remove a reaction from a message
+//	@Tags			message
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			messageId	path	string	true	"Message ID"
+//	@Param			emoji		path	string	true	"Emoji"
+//	@Description	Removes a reaction of the current user. The room receives a reaction.removed event.
+//	@Success		200	{object}	model.Reaction
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/messages/{messageId}/reactions/{emoji} [delete]
+func (h *Handler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	h.changeReaction(w, r, h.service.RemoveReaction, ws.EventReactionRemoved)
+}
+
+type reactionChange func(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID, emoji string) (model.Room, model.Reaction, bool, error)
+
+func (h *Handler) changeReaction(w http.ResponseWriter, r *http.Request, change reactionChange, eventType string) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	messageID, err := uuid.Parse(r.PathValue("messageId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid message ID")
+		return
+	}
+
+	emoji, err := url.PathUnescape(r.PathValue("emoji"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid emoji")
+		return
 	}
 
-	messages, err := h.service.ListMessages(ctx, roomSlug, limit, cursor)
+	room, reaction, changed, err := change(r.Context(), user.ID, roomSlug, messageID, emoji)
 	if err != nil {
-		if errors.Is(err, ErrRoomNotFound) {
-			httputil.BadRequest(w, "Chat not found")
+		switch {
+		case errors.Is(err, model.ErrInvalidEmoji):
+			httputil.BadRequest(w, "Invalid emoji")
+			return
+		case errors.Is(err, ErrRoomNotFound):
+			httputil.NotFound(w, "Room not found")
+			return
+		case errors.Is(err, ErrNotRoomMember):
+			httputil.Forbidden(w, "You are not a member of this room")
+			return
+		case errors.Is(err, ErrMessageNotFound), errors.Is(err, model.ErrMessageDeleted):
+			httputil.NotFound(w, "Message not found")
+			return
+		case errors.Is(err, model.ErrTooManyReactions):
+			httputil.Conflict(w, "The message has too many different reactions")
 			return
 		}
 		httputil.InternalError(w, r, err)
 		return
 	}
 
+	if changed {
+		h.wsService.PublishRoomEvent(r.Context(), room, eventType, ws.ReactionEvent{
+			MessageID: messageID,
+			UserID:    user.ID,
+			Emoji:     reaction.Emoji,
+			Count:     reaction.Count,
+		})
+	}
+
+	httputil.SuccessData(w, reaction)
+}
+
+// ListThreadMessages lists the replies in a thread
+//
+//	@Summary		List the replies in a thread
+//	@Tags			message
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			messageId	path	string	true	"Root message ID"
+//	@Param			limit		query	int		false	"Limit"
+//	@Param			cursor		query	string	false	"Cursor"
+//	@Description	Returns the root message with its thread summary and a page of replies, newest first.
+//	@Success		200	{object}	ThreadResponse
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/messages/{messageId}/thread [get]
+func (h *Handler) ListThreadMessages(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+	limit := pagination.NormalizeLimit(r.URL.Query().Get("limit"), 100, 32)
+
+	rootID, err := uuid.Parse(r.PathValue("messageId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid message ID")
+		return
+	}
+
+	var cursor *pagination.Cursor
+	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+		c, err := pagination.ParseCursor(cursorStr)
+		if err != nil {
+			httputil.BadRequest(w, "Invalid cursor")
+			return
+		}
+		cursor = &c
+	}
+
+	page, err := h.service.ListThreadMessages(r.Context(), user.ID, roomSlug, rootID, limit, cursor)
+	if err != nil {
+		h.threadError(w, r, err)
+		return
+	}
+
 	var nextCursor string
-	if len(messages) == limit {
-		nextCursor = h.service.GenerateCursor(messages[len(messages)-1])
+	if page.HasMore {
+		nextCursor = h.service.GenerateCursor(page.Replies[len(page.Replies)-1])
 	}
 
-	httputil.SuccessData(w, GetPagingResponse{
-		Messages:   messages,
+	httputil.SuccessData(w, ThreadResponse{
+		Root:       page.Root,
+		Messages:   page.Replies,
 		NextCursor: nextCursor,
 	})
 }
+
+// FollowThread follows a thread
+//
+//	@Summary		Follow a thread
+//	@Tags			message
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			messageId	path	string	true	"Root message ID"
+//	@Description	The current user gets a thread.activity event on new replies in the thread.
+//	@Success		200
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/messages/{messageId}/thread/follow [put]
+func (h *Handler) FollowThread(w http.ResponseWriter, r *http.Request) {
+	h.setThreadFollowing(w, r, true)
+}
+
+// UnfollowThread unfollows a thread
+//
+//	@Summary		Unfollow a thread
+//	@Tags			message
+//	@Security		BearerAuth
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			messageId	path	string	true	"Root message ID"
+//	@Description	Stops thread.activity events for the thread. Replying to it doesn't follow it again.
+//	@Success		200
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/messages/{messageId}/thread/follow [delete]
+func (h *Handler) UnfollowThread(w http.ResponseWriter, r *http.Request) {
+	h.setThreadFollowing(w, r, false)
+}
+
+func (h *Handler) setThreadFollowing(w http.ResponseWriter, r *http.Request, following bool) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	rootID, err := uuid.Parse(r.PathValue("messageId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid message ID")
+		return
+	}
+
+	if err := h.service.SetThreadFollowing(r.Context(), user.ID, roomSlug, rootID, following); err != nil {
+		h.threadError(w, r, err)
+		return
+	}
+
+	httputil.Success(w)
+}
+
+func (h *Handler) threadError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrRoomNotFound):
+		httputil.NotFound(w, "Room not found")
+	case errors.Is(err, ErrNotRoomMember):
+		httputil.Forbidden(w, "You are not a member of this room")
+	case errors.Is(err, ErrMessageNotFound):
+		httputil.NotFound(w, "Message not found")
+	case errors.Is(err, model.ErrInvalidThreadRoot):
+		httputil.BadRequest(w, "The message is in a thread and can't have one")
+	default:
+		httputil.InternalError(w, r, err)
+	}
+}
+
+// UploadAttachment uploads a file to a room
+//
+//	@Summary		Upload an attachment
+//	@Tags			message
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			roomSlug	path		string	true	"Room Slug"
+//	@Param			file		formData	file	true	"File"
+//	@Description	Stores a file to be sent with a message through the attachmentIds of message.send. Images, PDF, plain text, ZIP and common audio and video files are accepted; the type is detected from the content. Images get a thumbnail. Uploads not sent within a day are removed.
+//	@Success		201	{object}	model.Attachment
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		422	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/attachments [post]
+func (h *Handler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	// Leave room for the multipart framing around the file.
+	r.Body = http.MaxBytesReader(w, r.Body, h.service.maxAttachmentSize+1<<20)
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		httputil.ValidationError(w, httputil.FieldErrors{"file": "file too big"})
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		httputil.ValidationError(w, httputil.FieldErrors{"file": "failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.service.UploadAttachment(r.Context(), user.ID, roomSlug, header.Filename, file, header.Size)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrAttachmentTooLarge), errors.Is(err, model.ErrUnsupportedAttachmentType), errors.Is(err, ErrInvalidImage):
+			httputil.ValidationError(w, httputil.FieldErrors{"file": err.Error()})
+		default:
+			h.attachmentError(w, r, err)
+		}
+		return
+	}
+
+	httputil.Created(w, attachment)
+}
+
+// DownloadAttachment downloads an attachment
+//
+//	@Summary		Download an attachment
+//	@Tags			message
+//	@Security		BearerAuth
+//	@Param			roomSlug		path	string	true	"Room Slug"
+//	@Param			attachmentId	path	string	true	"Attachment ID"
+//	@Description	Redirects to a short-lived signed URL of the file. Only room members can download attachments, and unsent uploads only by their uploader. Images, audio and video are served inline, anything else as a download.
+//	@Success		302
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/attachments/{attachmentId} [get]
+func (h *Handler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	h.redirectToAttachment(w, r, h.service.AttachmentURL)
+}
+
+// DownloadThumbnail downloads the thumbnail of an image attachment
+//
+//	@Summary		Download an attachment thumbnail
+//	@Tags			message
+//	@Security		BearerAuth
+//	@Param			roomSlug		path	string	true	"Room Slug"
+//	@Param			attachmentId	path	string	true	"Attachment ID"
+//	@Description	Redirects to a short-lived signed URL of the thumbnail. Thumbnails fit in 320x320 pixels. Only attachments with hasThumbnail have one.
+//	@Success		302
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/attachments/{attachmentId}/thumbnail [get]
+func (h *Handler) DownloadThumbnail(w http.ResponseWriter, r *http.Request) {
+	h.redirectToAttachment(w, r, h.service.ThumbnailURL)
+}
+
+func (h *Handler) redirectToAttachment(
+	w http.ResponseWriter,
+	r *http.Request,
+	signedURL func(ctx context.Context, userID uuid.UUID, roomSlug string, attachmentID uuid.UUID) (string, error),
+) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	attachmentID, err := uuid.Parse(r.PathValue("attachmentId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid attachment ID")
+		return
+	}
+
+	location, err := signedURL(r.Context(), user.ID, roomSlug, attachmentID)
+	if err != nil {
+		h.attachmentError(w, r, err)
+		return
+	}
+
+	// The signed URL expires, so the redirect must not be cached.
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, location, http.StatusFound)
+}
+
+func (h *Handler) attachmentError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrRoomNotFound):
+		httputil.NotFound(w, "Room not found")
+	case errors.Is(err, ErrNotRoomMember):
+		httputil.Forbidden(w, "You are not a member of this room")
+	case errors.Is(err, ErrAttachmentNotFound):
+		httputil.NotFound(w, "Attachment not found")
+	default:
+		httputil.InternalError(w, r, err)
+	}
+}
@@ -2,79 +2,527 @@ package message
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"errors"
+	"log/slog"
+	"lunar/internal/access"
+	"lunar/internal/filestore"
 	"lunar/internal/model"
 	"lunar/internal/pagination"
 	"lunar/internal/repository"
-	"strconv"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// purgeBatchSize bounds how many deleted messages one purge query handles.
+const purgeBatchSize = 500
+
 type Service struct {
-	roomRepo    repository.RoomRepository
-	messageRepo repository.MessageRepository
+	access         *access.Checker
+	messageRepo    repository.MessageRepository
+	reactionRepo   repository.ReactionRepository
+	threadRepo     repository.ThreadRepository
+	attachmentRepo repository.AttachmentRepository
+	// attachments keeps the files of attachments and their thumbnails.
+	attachments       filestore.Store
+	attachmentURLTTL  time.Duration
+	maxAttachmentSize int64
 }
 
 var (
-	ErrRoomNotFound = errors.New("room not found")
+	ErrRoomNotFound     = access.ErrRoomNotFound
+	ErrNotRoomMember    = access.ErrNotRoomMember
+	ErrPermissionDenied = access.ErrPermissionDenied
+	ErrMessageInThread  = errors.New("message is in a thread")
+	// ErrMessageNotFound is the repository error so callers outside this
+	// package, like the socket, can match it too.
+	ErrMessageNotFound    = repository.ErrMessageNotFound
+	ErrAttachmentNotFound = repository.ErrAttachmentNotFound
 )
 
-func NewService(roomRepo repository.RoomRepository, messageRepo repository.MessageRepository) *Service {
-	return &Service{roomRepo, messageRepo}
+func NewService(
+	access *access.Checker,
+	messageRepo repository.MessageRepository,
+	reactionRepo repository.ReactionRepository,
+	threadRepo repository.ThreadRepository,
+	attachmentRepo repository.AttachmentRepository,
+	attachments filestore.Store,
+	attachmentURLTTL time.Duration,
+	maxAttachmentSize int64,
+) *Service {
+	return &Service{
+		access,
+		messageRepo,
+		reactionRepo,
+		threadRepo,
+		attachmentRepo,
+		attachments,
+		attachmentURLTTL,
+		maxAttachmentSize,
+	}
+}
+
+// Page selects the part of the room timeline to list. At most one field is
+// set; without any the latest messages are listed.
+type Page struct {
+	// Before lists the messages older than the cursor.
+	Before *pagination.Cursor
+	// After lists the messages newer than the cursor.
+	After *pagination.Cursor
+	// Around lists the message with this ID and the messages on both sides
+	// of it.
+	Around *uuid.UUID
+}
+
+// PageResult is a page of the room timeline, newest first. HasOlder and
+// HasNewer tell whether there is more to list on either side.
+type PageResult struct {
+	Messages []model.Message
+	HasOlder bool
+	HasNewer bool
+}
+
+// ListMessages returns a page of the timeline of a room the user is a member
+// of with reactions, thread summaries and attachments as seen by the user.
+func (s *Service) ListMessages(ctx context.Context, userID uuid.UUID, roomSlug string, limit int, page Page) (PageResult, error) {
+	room, err := s.getMemberRoom(ctx, userID, roomSlug)
+	if err != nil {
+		return PageResult{}, err
+	}
+
+	var result PageResult
+	switch {
+	case page.Around != nil:
+		result, err = s.listAround(ctx, room.ID, limit, *page.Around)
+	case page.After != nil:
+		result, err = s.listAfter(ctx, room.ID, limit, *page.After)
+	default:
+		result, err = s.listBefore(ctx, room.ID, limit, page.Before)
+	}
+	if err != nil {
+		return PageResult{}, err
+	}
+
+	if err := s.attachReactions(ctx, userID, result.Messages); err != nil {
+		return PageResult{}, err
+	}
+	if err := s.attachThreads(ctx, userID, result.Messages); err != nil {
+		return PageResult{}, err
+	}
+	if err := s.attachAttachments(ctx, result.Messages); err != nil {
+		return PageResult{}, err
+	}
+	return result, nil
+}
+
+// listBefore lists up to limit messages older than the cursor, or the latest
+// ones without it. One extra message is fetched to tell if there are more, so
+// a limit of zero lists nothing but still reports it.
+func (s *Service) listBefore(ctx context.Context, roomID uuid.UUID, limit int, cursor *pagination.Cursor) (PageResult, error) {
+	limit = max(limit, 0)
+	messages, err := s.messageRepo.ListMessages(ctx, roomID, limit+1, cursor)
+	if err != nil {
+		return PageResult{}, err
+	}
+
+	hasOlder := len(messages) > limit
+	if hasOlder {
+		messages = messages[:limit]
+	}
+	return PageResult{Messages: messages, HasOlder: hasOlder, HasNewer: cursor != nil}, nil
+}
+
+func (s *Service) listAfter(ctx context.Context, roomID uuid.UUID, limit int, cursor pagination.Cursor) (PageResult, error) {
+	limit = max(limit, 0)
+	messages, err := s.messageRepo.ListMessagesAfter(ctx, roomID, limit+1, cursor)
+	if err != nil {
+		return PageResult{}, err
+	}
+
+	hasNewer := len(messages) > limit
+	if hasNewer {
+		messages = messages[:limit]
+	}
+	slices.Reverse(messages)
+	return PageResult{Messages: messages, HasOlder: true, HasNewer: hasNewer}, nil
+}
+
+// listAround lists the anchor message with about half of the remaining limit
+// on each side of it. The anchor is listed even if limit is below one.
+func (s *Service) listAround(ctx context.Context, roomID uuid.UUID, limit int, anchorID uuid.UUID) (PageResult, error) {
+	anchor, err := s.messageRepo.GetMessage(ctx, anchorID)
+	if err != nil {
+		return PageResult{}, err
+	}
+	if anchor.RoomID != roomID {
+		return PageResult{}, ErrMessageNotFound
+	}
+	if anchor.ThreadID != nil {
+		return PageResult{}, ErrMessageInThread
+	}
+
+	cursor := pagination.Cursor{ID: anchor.ID, CreatedAt: anchor.CreatedAt}
+	limit = max(limit, 1)
+	olderLimit := (limit - 1) / 2
+	newerLimit := limit - 1 - olderLimit
+
+	older, err := s.listBefore(ctx, roomID, olderLimit, &cursor)
+	if err != nil {
+		return PageResult{}, err
+	}
+	newer, err := s.listAfter(ctx, roomID, newerLimit, cursor)
+	if err != nil {
+		return PageResult{}, err
+	}
+
+	messages := make([]model.Message, 0, len(newer.Messages)+1+len(older.Messages))
+	messages = append(messages, newer.Messages...)
+	messages = append(messages, anchor)
+	messages = append(messages, older.Messages...)
+	return PageResult{Messages: messages, HasOlder: older.HasOlder, HasNewer: newer.HasNewer}, nil
+}
+
+// ThreadPage is the root of a thread and a page of its replies, newest first.
+// HasMore tells whether there are older replies to list.
+type ThreadPage struct {
+	Root    model.Message
+	Replies []model.Message
+	HasMore bool
+}
+
+// ListThreadMessages returns the root of a thread and a page of its replies.
+func (s *Service) ListThreadMessages(ctx context.Context, userID uuid.UUID, roomSlug string, rootID uuid.UUID, limit int, cursor *pagination.Cursor) (ThreadPage, error) {
+	_, root, err := s.getMemberMessage(ctx, userID, roomSlug, rootID)
+	if err != nil {
+		return ThreadPage{}, err
+	}
+	if root.ThreadID != nil {
+		return ThreadPage{}, model.ErrInvalidThreadRoot
+	}
+
+	replies, hasMore, err := s.listReplies(ctx, root.ID, limit, cursor)
+	if err != nil {
+		return ThreadPage{}, err
+	}
+
+	messages := append([]model.Message{root}, replies...)
+	if err := s.attachReactions(ctx, userID, messages); err != nil {
+		return ThreadPage{}, err
+	}
+	if err := s.attachThreads(ctx, userID, messages[:1]); err != nil {
+		return ThreadPage{}, err
+	}
+	if err := s.attachAttachments(ctx, messages); err != nil {
+		return ThreadPage{}, err
+	}
+	return ThreadPage{Root: messages[0], Replies: messages[1:], HasMore: hasMore}, nil
+}
+
+// listReplies lists up to limit replies older than the cursor, fetching one
+// extra to tell if there are more.
+func (s *Service) listReplies(ctx context.Context, rootID uuid.UUID, limit int, cursor *pagination.Cursor) ([]model.Message, bool, error) {
+	limit = max(limit, 0)
+	replies, err := s.messageRepo.ListThreadMessages(ctx, rootID, limit+1, cursor)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(replies) > limit
+	if hasMore {
+		replies = replies[:limit]
+	}
+	return replies, hasMore, nil
+}
+
+// SetThreadFollowing makes the user follow or unfollow the thread under a
+// root message. Followers are notified of new replies.
+func (s *Service) SetThreadFollowing(ctx context.Context, userID uuid.UUID, roomSlug string, rootID uuid.UUID, following bool) error {
+	_, root, err := s.getMemberMessage(ctx, userID, roomSlug, rootID)
+	if err != nil {
+		return err
+	}
+	if root.ThreadID != nil {
+		return model.ErrInvalidThreadRoot
+	}
+
+	return s.threadRepo.SetFollowing(ctx, root.ID, userID, following)
+}
+
+// RecordThreadReply makes the author of a new thread reply and the author of
+// the root follow the thread, unless they unfollowed it before. It returns
+// the updated thread summary and the followers to notify.
+func (s *Service) RecordThreadReply(ctx context.Context, reply model.Message) (model.ThreadSummary, []uuid.UUID, error) {
+	root, err := s.messageRepo.GetMessage(ctx, *reply.ThreadID)
+	if err != nil {
+		return model.ThreadSummary{}, nil, err
+	}
+
+	if err := s.threadRepo.AutoFollow(ctx, root.ID, root.Sender.ID, reply.Sender.ID); err != nil {
+		return model.ThreadSummary{}, nil, err
+	}
+
+	summaries, err := s.threadRepo.GetSummaries(ctx, []uuid.UUID{root.ID}, uuid.Nil)
+	if err != nil {
+		return model.ThreadSummary{}, nil, err
+	}
+
+	followerIDs, err := s.threadRepo.ListFollowerIDs(ctx, root.ID)
+	if err != nil {
+		return model.ThreadSummary{}, nil, err
+	}
+
+	notify := make([]uuid.UUID, 0, len(followerIDs))
+	for _, id := range followerIDs {
+		if id != reply.Sender.ID {
+			notify = append(notify, id)
+		}
+	}
+	return summaries[root.ID], notify, nil
+}
+
+func (s *Service) attachReactions(ctx context.Context, userID uuid.UUID, messages []model.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+
+	reactions, err := s.reactionRepo.ListReactions(ctx, ids, userID)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		messages[i].Reactions = reactions[messages[i].ID]
+	}
+	return nil
 }
 
-func (s *Service) ListMessages(ctx context.Context, roomSlug string, limit int, cursor *pagination.Cursor) ([]model.Message, error) {
-	room, err := s.roomRepo.GetBySlug(ctx, roomSlug)
+func (s *Service) attachThreads(ctx context.Context, userID uuid.UUID, messages []model.Message) error {
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, message := range messages {
+		if message.ThreadID == nil {
+			ids = append(ids, message.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	summaries, err := s.threadRepo.GetSummaries(ctx, ids, userID)
 	if err != nil {
-		if errors.Is(err, repository.ErrRoomNotFound) {
-			return nil, ErrRoomNotFound
+		return err
+	}
+	for i := range messages {
+		if summary, ok := summaries[messages[i].ID]; ok {
+			messages[i].Thread = &summary
 		}
+	}
+	return nil
+}
+
+func (s *Service) ListReadReceipts(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID) ([]model.ReadReceipt, error) {
+	_, message, err := s.getMemberMessage(ctx, userID, roomSlug, messageID)
+	if err != nil {
 		return nil, err
 	}
 
-	return s.messageRepo.ListMessages(ctx, room.ID, limit, cursor)
+	return s.messageRepo.ListReadReceipts(ctx, message)
 }
 
-func (s *Service) GenerateCursor(message model.Message) string {
-	c := pagination.Cursor{
-		ID:        message.ID,
-		CreatedAt: message.CreatedAt,
+// EditMessage replaces the content of a message sent by the user. The
+// previous content is kept as a revision. The room is returned so the caller
+// can broadcast the change.
+func (s *Service) EditMessage(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID, content string) (model.Room, model.Message, error) {
+	room, message, err := s.getMemberMessage(ctx, userID, roomSlug, messageID)
+	if err != nil {
+		return model.Room{}, model.Message{}, err
+	}
+
+	if err := message.Edit(userID, content); err != nil {
+		return model.Room{}, model.Message{}, err
+	}
+
+	updated, err := s.messageRepo.UpdateMessage(ctx, message)
+	if err != nil {
+		return model.Room{}, model.Message{}, err
+	}
+
+	messages := []model.Message{updated}
+	if err := s.attachAttachments(ctx, messages); err != nil {
+		return model.Room{}, model.Message{}, err
+	}
+	return room, messages[0], nil
+}
+
+// ListRevisions returns the previous versions of a message, oldest first.
+// Only members with PermViewRevisions may see them.
+func (s *Service) ListRevisions(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID) ([]model.MessageRevision, error) {
+	room, _, err := s.access.Require(ctx, userID, roomSlug, model.PermViewRevisions)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.messageRepo.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.RoomID != room.ID {
+		return nil, ErrMessageNotFound
+	}
+
+	return s.messageRepo.ListRevisions(ctx, message.ID)
+}
+
+// DeleteMessage leaves a tombstone in place of a message. Authors may delete
+// their own messages and members with PermDeleteMessages those of members
+// ranked below them. The room is returned so the caller can broadcast the
+// change.
+func (s *Service) DeleteMessage(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID) (model.Room, model.Message, error) {
+	room, role, err := s.access.Member(ctx, userID, roomSlug)
+	if err != nil {
+		return model.Room{}, model.Message{}, err
+	}
+
+	message, err := s.messageRepo.GetMessage(ctx, messageID)
+	if err != nil {
+		return model.Room{}, model.Message{}, err
+	}
+	if message.RoomID != room.ID {
+		return model.Room{}, model.Message{}, ErrMessageNotFound
+	}
+
+	var authorRole model.RoomRole
+	if message.Sender.ID != userID {
+		authorRole, err = s.access.RoleOf(ctx, room.ID, message.Sender.ID)
+		if err != nil {
+			return model.Room{}, model.Message{}, err
+		}
+	}
+
+	if err := message.Delete(userID, role, authorRole); err != nil {
+		return model.Room{}, model.Message{}, err
+	}
+
+	if err := s.messageRepo.DeleteMessage(ctx, message, userID); err != nil {
+		return model.Room{}, model.Message{}, err
+	}
+	return room, message, nil
+}
+
+// AddReaction reacts to a message on behalf of the user. changed is false if
+// the user had already reacted with the emoji. The room is returned so the
+// caller can broadcast the change.
+func (s *Service) AddReaction(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID, emoji string) (room model.Room, reaction model.Reaction, changed bool, err error) {
+	if err := model.ValidateEmoji(emoji); err != nil {
+		return model.Room{}, model.Reaction{}, false, err
+	}
+
+	room, message, err := s.getMemberMessage(ctx, userID, roomSlug, messageID)
+	if err != nil {
+		return model.Room{}, model.Reaction{}, false, err
+	}
+	if message.DeletedAt != nil {
+		return model.Room{}, model.Reaction{}, false, model.ErrMessageDeleted
 	}
 
-	b, _ := json.Marshal(c)
+	changed, err = s.reactionRepo.AddReaction(ctx, message.ID, userID, emoji, model.MaxMessageReactions)
+	if err != nil {
+		return model.Room{}, model.Reaction{}, false, err
+	}
 
-	return base64.StdEncoding.EncodeToString(b)
+	count, err := s.reactionRepo.CountReaction(ctx, message.ID, emoji)
+	if err != nil {
+		return model.Room{}, model.Reaction{}, false, err
+	}
+	return room, model.Reaction{Emoji: emoji, Count: count, ReactedByMe: true}, changed, nil
 }
 
-func (s *Service) ParseCursor(cursorEncoded string) (pagination.Cursor, error) {
-	var cursor pagination.Cursor
+// RemoveReaction takes back the user's reaction to a message. changed is
+// false if there was no such reaction.
+func (s *Service) RemoveReaction(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID, emoji string) (room model.Room, reaction model.Reaction, changed bool, err error) {
+	room, message, err := s.getMemberMessage(ctx, userID, roomSlug, messageID)
+	if err != nil {
+		return model.Room{}, model.Reaction{}, false, err
+	}
 
-	decoded, err := base64.StdEncoding.DecodeString(cursorEncoded)
+	changed, err = s.reactionRepo.RemoveReaction(ctx, message.ID, userID, emoji)
 	if err != nil {
-		return cursor, err
+		return model.Room{}, model.Reaction{}, false, err
 	}
 
-	if err := json.Unmarshal(decoded, &cursor); err != nil {
-		return cursor, err
+	count, err := s.reactionRepo.CountReaction(ctx, message.ID, emoji)
+	if err != nil {
+		return model.Room{}, model.Reaction{}, false, err
 	}
+	return room, model.Reaction{Emoji: emoji, Count: count, ReactedByMe: false}, changed, nil
+}
 
-	return cursor, nil
+// getMemberRoom loads a room the user is a member of.
+func (s *Service) getMemberRoom(ctx context.Context, userID uuid.UUID, roomSlug string) (model.Room, error) {
+	room, _, err := s.access.Member(ctx, userID, roomSlug)
+	return room, err
 }
 
-func normalizeLimit(limit string, max int, fallback int) int {
-	if limit == "" {
-		return fallback
+// getMemberMessage loads a message of a room the user is a member of.
+func (s *Service) getMemberMessage(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID) (model.Room, model.Message, error) {
+	room, err := s.getMemberRoom(ctx, userID, roomSlug)
+	if err != nil {
+		return model.Room{}, model.Message{}, err
 	}
 
-	result, err := strconv.Atoi(limit)
+	message, err := s.messageRepo.GetMessage(ctx, messageID)
 	if err != nil {
-		return fallback
+		return model.Room{}, model.Message{}, err
+	}
+	if message.RoomID != room.ID {
+		return model.Room{}, model.Message{}, ErrMessageNotFound
 	}
+	return room, message, nil
+}
+
+// PurgeDeleted periodically erases the content and edit history of messages
+// deleted more than retention ago. The tombstones stay, but their
+// attachments are removed along with uploads never sent within
+// unsentRetention. It returns when ctx is done.
+func (s *Service) PurgeDeleted(ctx context.Context, interval, retention, unsentRetention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deletedBefore := time.Now().Add(-retention)
+			for {
+				purged, err := s.messageRepo.PurgeDeleted(ctx, deletedBefore, purgeBatchSize)
+				if err != nil {
+					if ctx.Err() == nil {
+						slog.Error("purge deleted messages", "err", err)
+					}
+					break
+				}
+				if purged > 0 {
+					slog.Info("purged deleted messages", "count", purged)
+				}
+				if purged < purgeBatchSize {
+					break
+				}
+			}
+			s.purgeAttachments(ctx, time.Now().Add(-unsentRetention), deletedBefore)
+		}
+	}
+}
 
-	if result < 0 || result > max {
-		return fallback
+func (s *Service) GenerateCursor(message model.Message) string {
+	c := pagination.Cursor{
+		ID:        message.ID,
+		CreatedAt: message.CreatedAt,
 	}
 
-	return result
+	return c.Encode()
 }
@@ -0,0 +1,256 @@
+package message
+
+import (
+	"context"
+	"lunar/internal/model"
+	"lunar/internal/pagination"
+	"lunar/internal/repository"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeMessages is a room timeline kept oldest first.
+type fakeMessages struct {
+	repository.MessageRepository
+	messages []model.Message
+}
+
+func newFakeMessages(roomID uuid.UUID, n int) *fakeMessages {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeMessages{}
+	for i := range n {
+		repo.messages = append(repo.messages, model.Message{
+			ID:        uuid.New(),
+			RoomID:    roomID,
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	return repo
+}
+
+func (r *fakeMessages) GetMessage(_ context.Context, id uuid.UUID) (model.Message, error) {
+	for _, message := range r.messages {
+		if message.ID == id {
+			return message, nil
+		}
+	}
+	return model.Message{}, repository.ErrMessageNotFound
+}
+
+func (r *fakeMessages) ListMessages(_ context.Context, _ uuid.UUID, limit int, cursor *pagination.Cursor) ([]model.Message, error) {
+	if limit < 1 {
+		panic("ListMessages with a non-positive limit")
+	}
+	var result []model.Message
+	for _, message := range slices.Backward(r.messages) {
+		if cursor != nil && !message.CreatedAt.Before(cursor.CreatedAt) {
+			continue
+		}
+		if len(result) == limit {
+			break
+		}
+		result = append(result, message)
+	}
+	return result, nil
+}
+
+func (r *fakeMessages) ListMessagesAfter(_ context.Context, _ uuid.UUID, limit int, cursor pagination.Cursor) ([]model.Message, error) {
+	if limit < 1 {
+		panic("ListMessagesAfter with a non-positive limit")
+	}
+	var result []model.Message
+	for _, message := range r.messages {
+		if !message.CreatedAt.After(cursor.CreatedAt) {
+			continue
+		}
+		if len(result) == limit {
+			break
+		}
+		result = append(result, message)
+	}
+	return result, nil
+}
+
+func (r *fakeMessages) ListThreadMessages(_ context.Context, threadID uuid.UUID, limit int, cursor *pagination.Cursor) ([]model.Message, error) {
+	if limit < 1 {
+		panic("ListThreadMessages with a non-positive limit")
+	}
+	var result []model.Message
+	for _, message := range slices.Backward(r.messages) {
+		if message.ThreadID == nil || *message.ThreadID != threadID {
+			continue
+		}
+		if cursor != nil && !message.CreatedAt.Before(cursor.CreatedAt) {
+			continue
+		}
+		if len(result) == limit {
+			break
+		}
+		result = append(result, message)
+	}
+	return result, nil
+}
+
+func TestListAround(t *testing.T) {
+	roomID := uuid.New()
+	repo := newFakeMessages(roomID, 10)
+	s := &Service{messageRepo: repo}
+	anchor := repo.messages[5]
+
+	tests := []struct {
+		limit     int
+		wantLen   int
+		wantOlder bool
+		wantNewer bool
+	}{
+		{-1, 1, true, true},
+		{0, 1, true, true},
+		{1, 1, true, true},
+		{2, 2, true, true},
+		{3, 3, true, true},
+		{10, 9, true, false},
+		{11, 10, false, false},
+		{50, 10, false, false},
+	}
+	for _, tt := range tests {
+		result, err := s.listAround(context.Background(), roomID, tt.limit, anchor.ID)
+		if err != nil {
+			t.Fatalf("listAround(%d): %v", tt.limit, err)
+		}
+		if len(result.Messages) != tt.wantLen {
+			t.Errorf("listAround(%d) listed %d messages, want %d", tt.limit, len(result.Messages), tt.wantLen)
+		}
+		if result.HasOlder != tt.wantOlder || result.HasNewer != tt.wantNewer {
+			t.Errorf("listAround(%d) has older %t and newer %t, want %t and %t",
+				tt.limit, result.HasOlder, result.HasNewer, tt.wantOlder, tt.wantNewer)
+		}
+		if !slices.ContainsFunc(result.Messages, func(m model.Message) bool { return m.ID == anchor.ID }) {
+			t.Errorf("listAround(%d) left out the anchor", tt.limit)
+		}
+		if !slices.IsSortedFunc(result.Messages, func(a, b model.Message) int { return b.CreatedAt.Compare(a.CreatedAt) }) {
+			t.Errorf("listAround(%d) is not newest first", tt.limit)
+		}
+	}
+}
+
+func TestListAroundOtherRoom(t *testing.T) {
+	repo := newFakeMessages(uuid.New(), 3)
+	s := &Service{messageRepo: repo}
+
+	_, err := s.listAround(context.Background(), uuid.New(), 10, repo.messages[1].ID)
+	if err != ErrMessageNotFound {
+		t.Fatalf("listAround = %v, want %v", err, ErrMessageNotFound)
+	}
+}
+
+func TestListBeforeAndAfterLimits(t *testing.T) {
+	roomID := uuid.New()
+	repo := newFakeMessages(roomID, 5)
+	s := &Service{messageRepo: repo}
+	middle := pagination.Cursor{ID: repo.messages[2].ID, CreatedAt: repo.messages[2].CreatedAt}
+
+	for _, limit := range []int{-5, 0} {
+		before, err := s.listBefore(context.Background(), roomID, limit, &middle)
+		if err != nil {
+			t.Fatalf("listBefore(%d): %v", limit, err)
+		}
+		if len(before.Messages) != 0 || !before.HasOlder {
+			t.Errorf("listBefore(%d) = %d messages, has older %t", limit, len(before.Messages), before.HasOlder)
+		}
+
+		after, err := s.listAfter(context.Background(), roomID, limit, middle)
+		if err != nil {
+			t.Fatalf("listAfter(%d): %v", limit, err)
+		}
+		if len(after.Messages) != 0 || !after.HasNewer {
+			t.Errorf("listAfter(%d) = %d messages, has newer %t", limit, len(after.Messages), after.HasNewer)
+		}
+	}
+
+	latest, err := s.listBefore(context.Background(), roomID, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latest.Messages) != 5 || latest.HasOlder || latest.HasNewer {
+		t.Errorf("listBefore = %d messages, has older %t, has newer %t", len(latest.Messages), latest.HasOlder, latest.HasNewer)
+	}
+
+	after, err := s.listAfter(context.Background(), roomID, 2, middle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after.Messages) != 2 || after.HasNewer || after.Messages[0].ID != repo.messages[4].ID {
+		t.Errorf("listAfter = %d messages, has newer %t", len(after.Messages), after.HasNewer)
+	}
+}
+
+// newFakeThread makes the first message of the timeline the root of a thread
+// holding the rest.
+func newFakeThread(replies int) (*fakeMessages, model.Message) {
+	repo := newFakeMessages(uuid.New(), replies+1)
+	root := repo.messages[0]
+	for i := range repo.messages[1:] {
+		repo.messages[i+1].ThreadID = &root.ID
+	}
+	return repo, root
+}
+
+func TestListReplies(t *testing.T) {
+	repo, root := newFakeThread(5)
+	s := &Service{messageRepo: repo}
+
+	tests := []struct {
+		limit       int
+		wantLen     int
+		wantHasMore bool
+	}{
+		{-1, 0, true},
+		{0, 0, true},
+		{1, 1, true},
+		{4, 4, true},
+		{5, 5, false},
+		{10, 5, false},
+	}
+	for _, tt := range tests {
+		replies, hasMore, err := s.listReplies(context.Background(), root.ID, tt.limit, nil)
+		if err != nil {
+			t.Fatalf("listReplies(%d): %v", tt.limit, err)
+		}
+		if len(replies) != tt.wantLen || hasMore != tt.wantHasMore {
+			t.Errorf("listReplies(%d) = %d replies, has more %t, want %d and %t",
+				tt.limit, len(replies), hasMore, tt.wantLen, tt.wantHasMore)
+		}
+	}
+}
+
+func TestListRepliesPages(t *testing.T) {
+	repo, root := newFakeThread(6)
+	s := &Service{messageRepo: repo}
+
+	var (
+		listed []model.Message
+		cursor *pagination.Cursor
+	)
+	for page := 0; ; page++ {
+		if page == 3 {
+			t.Fatal("listReplies did not reach the last page")
+		}
+		replies, hasMore, err := s.listReplies(context.Background(), root.ID, 3, cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		listed = append(listed, replies...)
+		if !hasMore {
+			break
+		}
+		last := replies[len(replies)-1]
+		cursor = &pagination.Cursor{ID: last.ID, CreatedAt: last.CreatedAt}
+	}
+
+	if len(listed) != 6 {
+		t.Errorf("listed %d replies over all pages, want 6", len(listed))
+	}
+}
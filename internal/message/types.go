@@ -4,7 +4,28 @@ import (
 	"lunar/internal/model"
 )
 
+type EditMessageRequest struct {
+	Content string `json:"content" validate:"required,max=5000"`
+}
+
+type RevisionsResponse struct {
+	Revisions []model.MessageRevision `json:"revisions" binding:"required"`
+}
+
+type ReadReceiptsResponse struct {
+	Receipts []model.ReadReceipt `json:"receipts" binding:"required"`
+}
+
+// GetPagingResponse lists messages newest first. NextCursor continues with
+// older messages through before and PrevCursor with newer ones through after.
 type GetPagingResponse struct {
 	Messages   []model.Message `json:"messages"`
 	NextCursor string          `json:"nextCursor"`
+	PrevCursor string          `json:"prevCursor"`
+}
+
+type ThreadResponse struct {
+	Root       model.Message   `json:"root" binding:"required"`
+	Messages   []model.Message `json:"messages" binding:"required"`
+	NextCursor string          `json:"nextCursor"`
 }
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxAttachmentNameLength = 255
+	// MaxMessageAttachments caps the files a message can carry.
+	MaxMessageAttachments = 10
+)
+
+var (
+	ErrUnsupportedAttachmentType = errors.New("unsupported file type")
+	ErrAttachmentTooLarge        = errors.New("file too large")
+	ErrTooManyAttachments        = errors.New("too many attachments")
+	// ErrAttachmentUnavailable means an attachment doesn't exist, belongs to
+	// another user or room, or was already sent with a message.
+	ErrAttachmentUnavailable = errors.New("attachment unavailable")
+)
+
+// attachmentTypes are the content types that can be uploaded, as sniffed
+// from the file itself.
+var attachmentTypes = map[string]bool{
+	"image/jpeg":         true,
+	"image/png":          true,
+	"image/gif":          true,
+	"image/webp":         true,
+	"application/pdf":    true,
+	"application/zip":    true,
+	"application/ogg":    true,
+	"text/plain":         true,
+	"audio/mpeg":         true,
+	"audio/wave":         true,
+	"video/mp4":          true,
+	"video/webm":         true,
+	"application/x-gzip": true,
+}
+
+// Attachment is a file uploaded to a room. It belongs to a message once it
+// has been sent with one. Width and Height are set for images.
+type Attachment struct {
+	ID           uuid.UUID  `json:"id" binding:"required"`
+	RoomID       uuid.UUID  `json:"-"`
+	UploaderID   uuid.UUID  `json:"uploaderId" binding:"required"`
+	MessageID    *uuid.UUID `json:"-"`
+	Name         string     `json:"name" binding:"required"`
+	ContentType  string     `json:"contentType" binding:"required"`
+	Size         int64      `json:"size" binding:"required"`
+	Width        *int       `json:"width,omitempty"`
+	Height       *int       `json:"height,omitempty"`
+	HasThumbnail bool       `json:"hasThumbnail" binding:"required"`
+	CreatedAt    time.Time  `json:"createdAt" binding:"required"`
+	// StorageKey and ThumbnailKey locate the file and its thumbnail in the
+	// file store.
+	StorageKey   string `json:"-"`
+	ThumbnailKey string `json:"-"`
+}
+
+func NewAttachment(roomID uuid.UUID, uploaderID uuid.UUID, name string, contentType string, size int64) (Attachment, error) {
+	if !attachmentTypes[contentType] {
+		return Attachment{}, ErrUnsupportedAttachmentType
+	}
+
+	id := uuid.Must(uuid.NewV7())
+	return Attachment{
+		ID:          id,
+		RoomID:      roomID,
+		UploaderID:  uploaderID,
+		Name:        attachmentName(name),
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   time.Now(),
+		StorageKey:  id.String(),
+	}, nil
+}
+
+// IsImage tells whether the attachment can be shown inline as a picture.
+func (a *Attachment) IsImage() bool {
+	return strings.HasPrefix(a.ContentType, "image/")
+}
+
+// attachmentName strips the directories and control characters a client may
+// send along with the file name.
+func attachmentName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if utf8.RuneCountInString(name) > maxAttachmentNameLength {
+		name = string([]rune(name)[:maxAttachmentNameLength])
+	}
+	return name
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrBannedFromRoom means a banned user tried to join the room again.
+var ErrBannedFromRoom = errors.New("banned from room")
+
+// RoomBan keeps a user out of a room until ExpiresAt, or for good if it is
+// nil. BannedBy is nil once the user who banned is gone.
+type RoomBan struct {
+	RoomID    uuid.UUID  `json:"-"`
+	UserID    uuid.UUID  `json:"userId" binding:"required"`
+	BannedBy  *uuid.UUID `json:"bannedBy,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt" binding:"required"`
+}
+
+func NewRoomBan(roomID uuid.UUID, userID uuid.UUID, bannedBy uuid.UUID, reason string, expiresAt *time.Time) RoomBan {
+	return RoomBan{
+		RoomID:    roomID,
+		UserID:    userID,
+		BannedBy:  &bannedBy,
+		Reason:    reason,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+}
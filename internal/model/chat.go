@@ -1,31 +1,66 @@
 package model
 
 import (
+	"errors"
 	"lunar/internal/util"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrTooManyPins = errors.New("too many pinned messages")
+	// ErrInvalidPinLimit means a room pin limit below zero was to be set.
+	ErrInvalidPinLimit = errors.New("invalid pin limit")
+	// ErrRoomPrivate means a private room was to be joined without an
+	// invite.
+	ErrRoomPrivate = errors.New("room is private")
+)
+
+// RoomVisibility tells who can join a room. Anyone can join a public room by
+// its slug; private rooms are joined through invites.
+type RoomVisibility string
+
+const (
+	RoomPublic  RoomVisibility = "public"
+	RoomPrivate RoomVisibility = "private"
+)
+
 type Room struct {
-	ID        uuid.UUID    `json:"id" binding:"required"`
-	Name      string       `json:"name,omitempty"`
-	Slug      string       `json:"slug" binding:"required"`
-	Members   []RoomMember `json:"members,omitempty"`
-	CreatedAt time.Time    `json:"-"`
+	ID         uuid.UUID      `json:"id" binding:"required"`
+	Name       string         `json:"name,omitempty"`
+	Slug       string         `json:"slug" binding:"required"`
+	Visibility RoomVisibility `json:"visibility" binding:"required"`
+	// MaxPins overrides the instance-wide limit of pinned messages.
+	MaxPins   *int           `json:"maxPins,omitempty"`
+	ReadState *RoomReadState `json:"readState,omitempty"`
+	// Role is the role of the current user in the room, when listing the
+	// rooms of a user.
+	Role      RoomRole  `json:"role,omitempty"`
+	CreatedAt time.Time `json:"-"`
 }
 
-func NewRoom(name string) (Room, error) {
+// RoomReadState is what a member has read in a room. Without a read marker,
+// everything sent since the member joined counts as unread.
+type RoomReadState struct {
+	RoomID            uuid.UUID  `json:"roomId" binding:"required"`
+	LastReadMessageID *uuid.UUID `json:"lastReadMessageId,omitempty"`
+	UnreadCount       int        `json:"unreadCount" binding:"required"`
+	MentionCount      int        `json:"mentionCount" binding:"required"`
+}
+
+func NewRoom(name string, visibility RoomVisibility) (Room, error) {
 	slug, err := util.GenerateRoomSlug()
 	if err != nil {
 		return Room{}, err
 	}
 
 	return Room{
-		ID:        uuid.Must(uuid.NewV7()),
-		Name:      name,
-		Slug:      slug,
-		CreatedAt: time.Now(),
+		ID:         uuid.Must(uuid.NewV7()),
+		Name:       name,
+		Slug:       slug,
+		Visibility: visibility,
+		CreatedAt:  time.Now(),
 	}, err
 }
 
@@ -33,14 +68,35 @@ type RoomMember struct {
 	ID       uuid.UUID `json:"id" binding:"required"`
 	UserID   uuid.UUID `json:"userID" binding:"required"`
 	RoomID   uuid.UUID `json:"roomID" binding:"required"`
+	Role     RoomRole  `json:"role" binding:"required"`
 	JoinedAt time.Time `json:"-"`
 }
 
-func NewRoomMember(userID uuid.UUID, roomID uuid.UUID) RoomMember {
+// RoomMemberProfile is a member as listed to the other members of a room.
+type RoomMemberProfile struct {
+	UserID     uuid.UUID      `json:"userId" binding:"required"`
+	Username   string         `json:"username" binding:"required"`
+	AvatarURL  string         `json:"avatarUrl"`
+	Role       RoomRole       `json:"role" binding:"required"`
+	JoinedAt   time.Time      `json:"joinedAt" binding:"required"`
+	Status     PresenceStatus `json:"status" binding:"required" enums:"online,idle,offline"`
+	LastSeenAt *time.Time     `json:"lastSeenAt,omitempty"`
+}
+
+func NewRoomMember(userID uuid.UUID, roomID uuid.UUID, role RoomRole) RoomMember {
 	return RoomMember{
 		ID:       uuid.Must(uuid.NewV7()),
 		UserID:   userID,
 		RoomID:   roomID,
+		Role:     role,
 		JoinedAt: time.Now(),
 	}
 }
+
+// RoomPin is a message pinned in a room. PinnedBy is nil once the user who
+// pinned it is gone.
+type RoomPin struct {
+	Message  Message        `json:"message" binding:"required"`
+	PinnedBy *MessageSender `json:"pinnedBy,omitempty"`
+	PinnedAt time.Time      `json:"pinnedAt" binding:"required"`
+}
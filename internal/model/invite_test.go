@@ -0,0 +1,61 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewRoomInviteCapsTTL(t *testing.T) {
+	tests := []struct {
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{time.Hour, time.Hour},
+		{DefaultInviteTTL, DefaultInviteTTL},
+		{MaxInviteTTL, MaxInviteTTL},
+		{2 * MaxInviteTTL, MaxInviteTTL},
+	}
+	for _, tt := range tests {
+		invite, err := NewRoomInvite(uuid.New(), uuid.New(), tt.ttl, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := invite.ExpiresAt.Sub(invite.CreatedAt); got != tt.want {
+			t.Errorf("NewRoomInvite(%s) lasts %s, want %s", tt.ttl, got, tt.want)
+		}
+		if invite.Code == "" || invite.Uses != 0 {
+			t.Errorf("NewRoomInvite(%s) = code %q with %d uses", tt.ttl, invite.Code, invite.Uses)
+		}
+	}
+}
+
+func TestRoomInviteUsable(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	two := 2
+
+	tests := []struct {
+		name   string
+		invite RoomInvite
+		want   error
+	}{
+		{"fresh", RoomInvite{ExpiresAt: now.Add(time.Hour)}, nil},
+		{"uses left", RoomInvite{ExpiresAt: now.Add(time.Hour), MaxUses: &two, Uses: 1}, nil},
+		{"unlimited", RoomInvite{ExpiresAt: now.Add(time.Hour), Uses: 1000}, nil},
+		{"used up", RoomInvite{ExpiresAt: now.Add(time.Hour), MaxUses: &two, Uses: 2}, ErrInviteUsedUp},
+		{"expiring now", RoomInvite{ExpiresAt: now}, ErrInviteExpired},
+		{"expired", RoomInvite{ExpiresAt: past}, ErrInviteExpired},
+		{"revoked", RoomInvite{ExpiresAt: now.Add(time.Hour), RevokedAt: &past}, ErrInviteRevoked},
+		{"revoked and expired", RoomInvite{ExpiresAt: past, RevokedAt: &past}, ErrInviteRevoked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.invite.Usable(now); !errors.Is(err, tt.want) {
+				t.Errorf("Usable = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,29 +1,139 @@
 package model
 
 import (
-	"fmt"
+	"errors"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
+const (
+	maxMessageContentLength = 5000
+	maxEmojiLength          = 64
+	// replyExcerptLength is how many characters of the parent's content a
+	// reply quotes.
+	replyExcerptLength = 120
+	// MaxMessageReactions caps the distinct emojis a message can be reacted
+	// with.
+	MaxMessageReactions = 20
+)
+
+var (
+	ErrInvalidMessageContent = errors.New("invalid content length")
+	ErrNotMessageAuthor      = errors.New("not the message author")
+	ErrMessageDeleted        = errors.New("message deleted")
+	ErrInvalidEmoji          = errors.New("invalid emoji")
+	ErrTooManyReactions      = errors.New("too many distinct reactions")
+	ErrInvalidReplyParent    = errors.New("reply parent is not in the same room or thread")
+	ErrInvalidThreadRoot     = errors.New("invalid thread root")
+)
+
 type MessageSender struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
 	AvatarURL string    `json:"avatarUrl"`
 }
 
+// ReadReceipt tells that a room member has read up to MessageID.
+type ReadReceipt struct {
+	UserID    uuid.UUID `json:"userId" binding:"required"`
+	Username  string    `json:"username" binding:"required"`
+	AvatarURL string    `json:"avatarUrl"`
+	MessageID uuid.UUID `json:"messageId" binding:"required"`
+}
+
 type Message struct {
 	ID        uuid.UUID     `json:"id" binding:"required"`
 	RoomID    uuid.UUID     `json:"roomID" binding:"required"`
 	Content   string        `json:"content" binding:"required"`
 	Sender    MessageSender `json:"sender" binding:"required"`
 	CreatedAt time.Time     `json:"createdAt" binding:"required"`
+	// EditedAt is set once the message has been edited.
+	EditedAt *time.Time `json:"editedAt,omitempty"`
+	// DeletedAt is set on the tombstone left by a deleted message. A
+	// tombstone has no content.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	Reactions []Reaction `json:"reactions,omitempty"`
+	// Attachments are the files sent with the message, in upload order.
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// ReplyTo quotes the parent if the message is a reply.
+	ReplyTo *MessageReply `json:"replyTo,omitempty"`
+	// ThreadID is the root message of the thread the message was posted in.
+	// Thread messages are left out of the room timeline.
+	ThreadID *uuid.UUID `json:"threadId,omitempty"`
+	// Thread sums up the thread started by the message, if any.
+	Thread *ThreadSummary `json:"thread,omitempty"`
+}
+
+// ThreadSummary describes the thread under a root message. Participants are
+// the most recent repliers, latest first. Following is relative to the user
+// the message was loaded for.
+type ThreadSummary struct {
+	ReplyCount   int             `json:"replyCount" binding:"required"`
+	LastReplyAt  time.Time       `json:"lastReplyAt" binding:"required"`
+	Participants []MessageSender `json:"participants" binding:"required"`
+	Following    bool            `json:"following" binding:"required"`
+}
+
+// MessageReply quotes the message a reply refers to. Once the parent is
+// deleted only its ID is kept and Deleted is set.
+type MessageReply struct {
+	ID      uuid.UUID      `json:"id" binding:"required"`
+	Sender  *MessageSender `json:"sender,omitempty"`
+	Excerpt string         `json:"excerpt,omitempty"`
+	Deleted bool           `json:"deleted" binding:"required"`
+}
+
+func NewMessageReply(parent Message) MessageReply {
+	if parent.DeletedAt != nil {
+		return MessageReply{ID: parent.ID, Deleted: true}
+	}
+
+	sender := parent.Sender
+	return MessageReply{
+		ID:      parent.ID,
+		Sender:  &sender,
+		Excerpt: excerpt(parent.Content, replyExcerptLength),
+	}
+}
+
+func excerpt(content string, length int) string {
+	if utf8.RuneCountInString(content) <= length {
+		return content
+	}
+	runes := []rune(content)
+	return string(runes[:length]) + "…"
+}
+
+// Reaction sums up the reactions with one emoji on a message. ReactedByMe is
+// relative to the user the message was loaded for.
+type Reaction struct {
+	Emoji       string `json:"emoji" binding:"required"`
+	Count       int    `json:"count" binding:"required"`
+	ReactedByMe bool   `json:"reactedByMe" binding:"required"`
+}
+
+func ValidateEmoji(emoji string) error {
+	if emoji == "" || len(emoji) > maxEmojiLength || !utf8.ValidString(emoji) || strings.ContainsAny(emoji, " \t\r\n") {
+		return ErrInvalidEmoji
+	}
+	return nil
+}
+
+// MessageRevision is a previous version of an edited message's content.
+// EditedAt is when that content was replaced.
+type MessageRevision struct {
+	ID        uuid.UUID `json:"id" binding:"required"`
+	MessageID uuid.UUID `json:"messageId" binding:"required"`
+	Content   string    `json:"content" binding:"required"`
+	EditedAt  time.Time `json:"editedAt" binding:"required"`
 }
 
 func NewMessage(roomID uuid.UUID, content string, sender User) (Message, error) {
-	if len(content) > 5000 {
-		return Message{}, fmt.Errorf("invalid content length")
+	if len(content) > maxMessageContentLength {
+		return Message{}, ErrInvalidMessageContent
 	}
 	return Message{
 		ID:      uuid.Must(uuid.NewV7()),
@@ -37,3 +147,100 @@ func NewMessage(roomID uuid.UUID, content string, sender User) (Message, error)
 		CreatedAt: time.Now(),
 	}, nil
 }
+
+// PostInThread makes the message a reply in the thread under root. Threads
+// don't nest, so root can't be a thread message itself.
+func (m *Message) PostInThread(root Message) error {
+	if root.RoomID != m.RoomID || root.ThreadID != nil {
+		return ErrInvalidThreadRoot
+	}
+	if root.DeletedAt != nil {
+		return ErrMessageDeleted
+	}
+
+	m.ThreadID = &root.ID
+	return nil
+}
+
+// ReplyToMessage makes the message a reply to parent, which must be a message
+// of the same room and thread that is not deleted. Call it after PostInThread.
+func (m *Message) ReplyToMessage(parent Message) error {
+	if parent.RoomID != m.RoomID || !m.inThreadOf(parent) {
+		return ErrInvalidReplyParent
+	}
+	if parent.DeletedAt != nil {
+		return ErrMessageDeleted
+	}
+
+	reply := NewMessageReply(parent)
+	m.ReplyTo = &reply
+	return nil
+}
+
+// inThreadOf tells whether parent is in the same thread as the message or
+// is its root.
+func (m *Message) inThreadOf(parent Message) bool {
+	if m.ThreadID == nil {
+		return parent.ThreadID == nil
+	}
+	if parent.ThreadID == nil {
+		return parent.ID == *m.ThreadID
+	}
+	return *parent.ThreadID == *m.ThreadID
+}
+
+// Attach sends uploads with the message. They must have been uploaded to the
+// same room by the sender and not been sent yet.
+func (m *Message) Attach(attachments []Attachment) error {
+	if len(attachments) > MaxMessageAttachments {
+		return ErrTooManyAttachments
+	}
+	for _, a := range attachments {
+		if a.RoomID != m.RoomID || a.UploaderID != m.Sender.ID || a.MessageID != nil {
+			return ErrAttachmentUnavailable
+		}
+	}
+
+	m.Attachments = make([]Attachment, len(attachments))
+	for i, a := range attachments {
+		a.MessageID = &m.ID
+		m.Attachments[i] = a
+	}
+	return nil
+}
+
+// Edit replaces the content of the message. Only its author may edit it.
+func (m *Message) Edit(editorID uuid.UUID, content string) error {
+	if m.DeletedAt != nil {
+		return ErrMessageDeleted
+	}
+	if editorID != m.Sender.ID {
+		return ErrNotMessageAuthor
+	}
+	if content == "" || len(content) > maxMessageContentLength {
+		return ErrInvalidMessageContent
+	}
+
+	now := time.Now()
+	m.Content = content
+	m.EditedAt = &now
+	return nil
+}
+
+// Delete turns the message into a tombstone. Authors may delete their own
+// messages and members with PermDeleteMessages those of members ranked below
+// them; role is the role of the deleting user in the room and authorRole that
+// of the author.
+func (m *Message) Delete(userID uuid.UUID, role RoomRole, authorRole RoomRole) error {
+	if m.DeletedAt != nil {
+		return ErrMessageDeleted
+	}
+	if userID != m.Sender.ID && (!role.Can(PermDeleteMessages) || !role.Outranks(authorRole)) {
+		return ErrNotMessageAuthor
+	}
+
+	now := time.Now()
+	m.Content = ""
+	m.DeletedAt = &now
+	return nil
+}
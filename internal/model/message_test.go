@@ -0,0 +1,61 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMessageDelete(t *testing.T) {
+	author := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		role       RoomRole
+		authorRole RoomRole
+		want       error
+	}{
+		{"author as member", author, RoomRoleMember, RoomRoleMember, nil},
+		{"author as owner", author, RoomRoleOwner, RoomRoleOwner, nil},
+		{"member", other, RoomRoleMember, RoomRoleMember, ErrNotMessageAuthor},
+		{"moderator over member", other, RoomRoleModerator, RoomRoleMember, nil},
+		{"moderator over former member", other, RoomRoleModerator, "", nil},
+		{"moderator over moderator", other, RoomRoleModerator, RoomRoleModerator, ErrNotMessageAuthor},
+		{"moderator over admin", other, RoomRoleModerator, RoomRoleAdmin, ErrNotMessageAuthor},
+		{"moderator over owner", other, RoomRoleModerator, RoomRoleOwner, ErrNotMessageAuthor},
+		{"admin over moderator", other, RoomRoleAdmin, RoomRoleModerator, nil},
+		{"admin over owner", other, RoomRoleAdmin, RoomRoleOwner, ErrNotMessageAuthor},
+		{"owner over admin", other, RoomRoleOwner, RoomRoleAdmin, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Message{ID: uuid.New(), Content: "hello", Sender: MessageSender{ID: author}}
+
+			err := m.Delete(tt.userID, tt.role, tt.authorRole)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Delete = %v, want %v", err, tt.want)
+			}
+			if err == nil && (m.DeletedAt == nil || m.Content != "") {
+				t.Errorf("message was not turned into a tombstone: %+v", m)
+			}
+			if err != nil && m.DeletedAt != nil {
+				t.Error("refused delete still marked the message deleted")
+			}
+		})
+	}
+}
+
+func TestMessageDeleteTwice(t *testing.T) {
+	author := uuid.New()
+	m := Message{ID: uuid.New(), Content: "hello", Sender: MessageSender{ID: author}}
+
+	if err := m.Delete(author, RoomRoleMember, RoomRoleMember); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete(author, RoomRoleMember, RoomRoleMember); !errors.Is(err, ErrMessageDeleted) {
+		t.Errorf("second Delete = %v, want ErrMessageDeleted", err)
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PresenceStatus string
+
+const (
+	PresenceOnline  PresenceStatus = "online"
+	PresenceIdle    PresenceStatus = "idle"
+	PresenceOffline PresenceStatus = "offline"
+)
+
+type Presence struct {
+	UserID     uuid.UUID      `json:"userId" binding:"required"`
+	Status     PresenceStatus `json:"status" binding:"required" enums:"online,idle,offline"`
+	LastSeenAt *time.Time     `json:"lastSeenAt,omitempty"`
+}
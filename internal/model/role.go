@@ -0,0 +1,101 @@
+package model
+
+import "errors"
+
+var (
+	// ErrAlreadyRoomOwner means ownership was to be transferred to the
+	// current owner.
+	ErrAlreadyRoomOwner = errors.New("already the room owner")
+	// ErrOwnerCannotLeave means the owner tried to leave without handing the
+	// room over first.
+	ErrOwnerCannotLeave = errors.New("owner cannot leave the room")
+)
+
+// RoomRole is the role of a member within a room. Every room has exactly one
+// owner; the other roles are handed out by the owner and admins.
+type RoomRole string
+
+const (
+	RoomRoleOwner     RoomRole = "owner"
+	RoomRoleAdmin     RoomRole = "admin"
+	RoomRoleModerator RoomRole = "moderator"
+	RoomRoleMember    RoomRole = "member"
+)
+
+// RoomPermission is an action in a room that not every member may take.
+type RoomPermission string
+
+const (
+	// PermDeleteMessages allows deleting other members' messages.
+	PermDeleteMessages RoomPermission = "delete_messages"
+	// PermViewRevisions allows reading the previous versions of edited
+	// messages.
+	PermViewRevisions     RoomPermission = "view_revisions"
+	PermPinMessages       RoomPermission = "pin_messages"
+	PermKickMembers       RoomPermission = "kick_members"
+	PermBanMembers        RoomPermission = "ban_members"
+	PermRenameRoom        RoomPermission = "rename_room"
+	PermSetPinLimit       RoomPermission = "set_pin_limit"
+	PermManageInvites     RoomPermission = "manage_invites"
+	PermManageRoles       RoomPermission = "manage_roles"
+	PermTransferOwnership RoomPermission = "transfer_ownership"
+)
+
+var moderatorPermissions = []RoomPermission{
+	PermDeleteMessages,
+	PermViewRevisions,
+	PermPinMessages,
+	PermKickMembers,
+}
+
+var adminPermissions = append([]RoomPermission{
+	PermBanMembers,
+	PermRenameRoom,
+	PermSetPinLimit,
+	PermManageInvites,
+	PermManageRoles,
+}, moderatorPermissions...)
+
+var ownerPermissions = append([]RoomPermission{
+	PermTransferOwnership,
+}, adminPermissions...)
+
+var rolePermissions = map[RoomRole]map[RoomPermission]bool{
+	RoomRoleOwner:     permissionSet(ownerPermissions),
+	RoomRoleAdmin:     permissionSet(adminPermissions),
+	RoomRoleModerator: permissionSet(moderatorPermissions),
+	RoomRoleMember:    {},
+}
+
+func permissionSet(permissions []RoomPermission) map[RoomPermission]bool {
+	set := make(map[RoomPermission]bool, len(permissions))
+	for _, p := range permissions {
+		set[p] = true
+	}
+	return set
+}
+
+// roleRanks orders the roles; a member can only act on members ranked below.
+var roleRanks = map[RoomRole]int{
+	RoomRoleMember:    1,
+	RoomRoleModerator: 2,
+	RoomRoleAdmin:     3,
+	RoomRoleOwner:     4,
+}
+
+// Can tells whether the role grants the permission.
+func (r RoomRole) Can(p RoomPermission) bool {
+	return rolePermissions[r][p]
+}
+
+// Outranks tells whether the role is above other.
+func (r RoomRole) Outranks(other RoomRole) bool {
+	return roleRanks[r] > roleRanks[other]
+}
+
+// CanAssign tells whether the role may change a member's role from current to
+// role. Both have to be below the role; the owner is only ever changed by
+// transferring ownership.
+func (r RoomRole) CanAssign(current RoomRole, role RoomRole) bool {
+	return r.Can(PermManageRoles) && role != RoomRoleOwner && r.Outranks(current) && r.Outranks(role)
+}
@@ -0,0 +1,76 @@
+package model
+
+import "testing"
+
+func TestRoomRoleCan(t *testing.T) {
+	roles := []RoomRole{RoomRoleMember, RoomRoleModerator, RoomRoleAdmin, RoomRoleOwner}
+	// lowest is the lowest role granted each permission.
+	lowest := map[RoomPermission]RoomRole{
+		PermDeleteMessages:    RoomRoleModerator,
+		PermViewRevisions:     RoomRoleModerator,
+		PermPinMessages:       RoomRoleModerator,
+		PermKickMembers:       RoomRoleModerator,
+		PermBanMembers:        RoomRoleAdmin,
+		PermRenameRoom:        RoomRoleAdmin,
+		PermSetPinLimit:       RoomRoleAdmin,
+		PermManageInvites:     RoomRoleAdmin,
+		PermManageRoles:       RoomRoleAdmin,
+		PermTransferOwnership: RoomRoleOwner,
+	}
+	for permission, lowestRole := range lowest {
+		for _, role := range roles {
+			want := roleRanks[role] >= roleRanks[lowestRole]
+			if got := role.Can(permission); got != want {
+				t.Errorf("%s.Can(%s) = %t, want %t", role, permission, got, want)
+			}
+		}
+		if RoomRole("").Can(permission) {
+			t.Errorf("no role can %s", permission)
+		}
+	}
+}
+
+func TestRoomRoleOutranks(t *testing.T) {
+	tests := []struct {
+		role, other RoomRole
+		want        bool
+	}{
+		{RoomRoleOwner, RoomRoleAdmin, true},
+		{RoomRoleAdmin, RoomRoleModerator, true},
+		{RoomRoleModerator, RoomRoleMember, true},
+		{RoomRoleMember, "", true},
+		{RoomRoleMember, RoomRoleMember, false},
+		{RoomRoleAdmin, RoomRoleAdmin, false},
+		{RoomRoleModerator, RoomRoleAdmin, false},
+		{RoomRoleAdmin, RoomRoleOwner, false},
+		{"", RoomRoleMember, false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.Outranks(tt.other); got != tt.want {
+			t.Errorf("%q.Outranks(%q) = %t, want %t", tt.role, tt.other, got, tt.want)
+		}
+	}
+}
+
+func TestRoomRoleCanAssign(t *testing.T) {
+	tests := []struct {
+		role, current, assigned RoomRole
+		want                    bool
+	}{
+		{RoomRoleOwner, RoomRoleMember, RoomRoleAdmin, true},
+		{RoomRoleOwner, RoomRoleAdmin, RoomRoleMember, true},
+		{RoomRoleOwner, RoomRoleAdmin, RoomRoleOwner, false},
+		{RoomRoleAdmin, RoomRoleMember, RoomRoleModerator, true},
+		{RoomRoleAdmin, RoomRoleModerator, RoomRoleMember, true},
+		{RoomRoleAdmin, RoomRoleMember, RoomRoleAdmin, false},
+		{RoomRoleAdmin, RoomRoleAdmin, RoomRoleMember, false},
+		{RoomRoleAdmin, RoomRoleOwner, RoomRoleMember, false},
+		{RoomRoleModerator, RoomRoleMember, RoomRoleMember, false},
+		{RoomRoleMember, RoomRoleMember, RoomRoleMember, false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.CanAssign(tt.current, tt.assigned); got != tt.want {
+			t.Errorf("%s.CanAssign(%s, %s) = %t, want %t", tt.role, tt.current, tt.assigned, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package model
+
+import "github.com/google/uuid"
+
+type SearchRoom struct {
+	ID   uuid.UUID `json:"id" binding:"required"`
+	Slug string    `json:"slug" binding:"required"`
+	Name string    `json:"name,omitempty"`
+}
+
+// MessageSearchResult is a message matching a search. Highlight is an excerpt
+// of the content with the matches wrapped in <mark> tags; the rest of it is
+// not escaped.
+type MessageSearchResult struct {
+	Message   Message    `json:"message" binding:"required"`
+	Room      SearchRoom `json:"room" binding:"required"`
+	Highlight string     `json:"highlight" binding:"required"`
+	Rank      float32    `json:"-"`
+}
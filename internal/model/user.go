@@ -8,13 +8,14 @@ import (
 )
 
 type User struct {
-	ID            uuid.UUID `json:"id" binding:"required"`
-	Username      string    `json:"username" binding:"required"`
-	Email         string    `json:"email" binding:"required"`
-	PasswordHash  string    `json:"-"`
-	AvatarURL     string    `json:"avatarUrl"`
-	EmailVerified bool      `json:"emailVerified" binding:"required"`
-	CreatedAt     time.Time `json:"-" `
+	ID                uuid.UUID `json:"id" binding:"required"`
+	Username          string    `json:"username" binding:"required"`
+	Email             string    `json:"email" binding:"required"`
+	PasswordHash      string    `json:"-"`
+	AvatarURL         string    `json:"avatarUrl"`
+	EmailVerified     bool      `json:"emailVerified" binding:"required"`
+	ShareReadReceipts bool      `json:"shareReadReceipts" binding:"required"`
+	CreatedAt         time.Time `json:"-" `
 }
 
 func NewUser(username, email, password string, emailVerified bool) (User, error) {
@@ -24,12 +25,13 @@ func NewUser(username, email, password string, emailVerified bool) (User, error)
 	}
 
 	return User{
-		ID:            uuid.Must(uuid.NewV7()),
-		Username:      username,
-		Email:         email,
-		PasswordHash:  string(passwordHash),
-		EmailVerified: emailVerified,
-		CreatedAt:     time.Now(),
+		ID:                uuid.Must(uuid.NewV7()),
+		Username:          username,
+		Email:             email,
+		PasswordHash:      string(passwordHash),
+		EmailVerified:     emailVerified,
+		ShareReadReceipts: true,
+		CreatedAt:         time.Now(),
 	}, nil
 }
 
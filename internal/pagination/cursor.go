@@ -1,6 +1,7 @@
 package pagination
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"time"
 
@@ -12,6 +13,12 @@ type Cursor struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// RankedCursor positions a page of results ordered by rank first.
+type RankedCursor struct {
+	Rank   float32 `json:"rank"`
+	Cursor Cursor  `json:"cursor"`
+}
+
 type cursorUnmapped struct {
 	ID        string `json:"id"`
 	CreatedAt string `json:"createdAt"`
@@ -49,3 +56,42 @@ func (c *Cursor) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// Encode turns the cursor into the opaque string handed out to clients.
+func (c Cursor) Encode() string {
+	return encode(c)
+}
+
+// ParseCursor parses a cursor made by Cursor.Encode.
+func ParseCursor(encoded string) (Cursor, error) {
+	var cursor Cursor
+	err := decode(encoded, &cursor)
+	return cursor, err
+}
+
+// Encode turns the cursor into the opaque string handed out to clients.
+func (c RankedCursor) Encode() string {
+	return encode(c)
+}
+
+// ParseRankedCursor parses a cursor made by RankedCursor.Encode.
+func ParseRankedCursor(encoded string) (RankedCursor, error) {
+	var cursor RankedCursor
+	err := decode(encoded, &cursor)
+	return cursor, err
+}
+
+func encode(cursor any) string {
+	b, _ := json.Marshal(cursor)
+
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func decode(encoded string, cursor any) error {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(decoded, cursor)
+}
@@ -0,0 +1,52 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{
+		ID:        uuid.New(),
+		CreatedAt: time.Date(2026, 3, 4, 5, 6, 7, 891011, time.UTC),
+	}
+
+	got, err := ParseCursor(c.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != c.ID || !got.CreatedAt.Equal(c.CreatedAt) {
+		t.Errorf("ParseCursor = %+v, want %+v", got, c)
+	}
+}
+
+func TestRankedCursorRoundTrip(t *testing.T) {
+	c := RankedCursor{
+		Rank:   0.25,
+		Cursor: Cursor{ID: uuid.New(), CreatedAt: time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)},
+	}
+
+	got, err := ParseRankedCursor(c.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Rank != c.Rank || got.Cursor.ID != c.Cursor.ID || !got.Cursor.CreatedAt.Equal(c.Cursor.CreatedAt) {
+		t.Errorf("ParseRankedCursor = %+v, want %+v", got, c)
+	}
+}
+
+func TestParseCursorInvalid(t *testing.T) {
+	tests := []string{
+		"not base64!",
+		"bm90IGpzb24=", // "not json"
+		"eyJpZCI6IjEyMyIsImNyZWF0ZWRBdCI6IjIwMjYtMDEtMDFUMDA6MDA6MDBaIn0=",                     // bad id
+		"eyJpZCI6IjAxOTAwMDAwLTAwMDAtNzAwMC04MDAwLTAwMDAwMDAwMDAwMCIsImNyZWF0ZWRBdCI6InllcyJ9", // bad time
+	}
+	for _, encoded := range tests {
+		if _, err := ParseCursor(encoded); err == nil {
+			t.Errorf("ParseCursor(%q) succeeded", encoded)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package pagination
+
+import "strconv"
+
+// NormalizeLimit parses the page size asked for by a client. Anything that is
+// not a number from 1 to max gives fallback.
+func NormalizeLimit(limit string, max int, fallback int) int {
+	if limit == "" {
+		return fallback
+	}
+
+	result, err := strconv.Atoi(limit)
+	if err != nil {
+		return fallback
+	}
+
+	if result < 1 || result > max {
+		return fallback
+	}
+
+	return result
+}
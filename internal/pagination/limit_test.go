@@ -0,0 +1,23 @@
+package pagination
+
+import "testing"
+
+func TestNormalizeLimit(t *testing.T) {
+	tests := []struct {
+		limit string
+		want  int
+	}{
+		{"", 32},
+		{"abc", 32},
+		{"-1", 32},
+		{"0", 32},
+		{"1", 1},
+		{"100", 100},
+		{"101", 32},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLimit(tt.limit, 100, 32); got != tt.want {
+			t.Errorf("NormalizeLimit(%q) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
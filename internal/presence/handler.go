@@ -0,0 +1,49 @@
+package presence
+
+import (
+	"errors"
+	"lunar/internal/httputil"
+	"net/http"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service}
+}
+
+// ListRoomPresence godoc
+//
+//	@Summary	List the presence of room members
+//	@Tags		presence
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		roomSlug	path		string	true	"Room Slug"
+//	@Success	200			{object}	ListResponse
+//	@Failure	401			{object}	httputil.ErrorResponse
+//	@Failure	403			{object}	httputil.ErrorResponse
+//	@Failure	404			{object}	httputil.ErrorResponse
+//	@Failure	500			{object}	httputil.ErrorResponse
+//	@Router		/rooms/{roomSlug}/presence [get]
+func (h *Handler) ListRoomPresence(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	presences, err := h.service.ListRoomPresence(r.Context(), user.ID, roomSlug)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRoomNotFound):
+			httputil.NotFound(w, "Room not found")
+			return
+		case errors.Is(err, ErrNotRoomMember):
+			httputil.Forbidden(w, "You are not a member of this room")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	httputil.SuccessData(w, ListResponse{Presences: presences})
+}
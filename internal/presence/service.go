@@ -0,0 +1,94 @@
+package presence
+
+import (
+	"context"
+	"lunar/internal/access"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// reapBatch caps how many users a single sweep reaps.
+const reapBatch = 500
+
+var (
+	ErrRoomNotFound  = access.ErrRoomNotFound
+	ErrNotRoomMember = access.ErrNotRoomMember
+)
+
+type Service struct {
+	repo           repository.PresenceRepository
+	access         *access.Checker
+	roomRepo       repository.RoomRepository
+	friendshipRepo repository.FriendshipRepository
+}
+
+func NewService(repo repository.PresenceRepository, access *access.Checker, roomRepo repository.RoomRepository, friendshipRepo repository.FriendshipRepository) *Service {
+	return &Service{
+		repo:           repo,
+		access:         access,
+		roomRepo:       roomRepo,
+		friendshipRepo: friendshipRepo,
+	}
+}
+
+// SetStatus registers or refreshes a session of the user. It doubles as the
+// heartbeat that keeps the session from expiring.
+func (s *Service) SetStatus(ctx context.Context, userID, sessionID uuid.UUID, status model.PresenceStatus) (model.Presence, bool, error) {
+	return s.repo.SetSession(ctx, userID, sessionID, status)
+}
+
+func (s *Service) Disconnect(ctx context.Context, userID, sessionID uuid.UUID) (model.Presence, bool, error) {
+	return s.repo.RemoveSession(ctx, userID, sessionID)
+}
+
+// ReapExpired expires the sessions of clients that stopped sending
+// heartbeats, e.g. because their instance crashed.
+func (s *Service) ReapExpired(ctx context.Context) ([]model.Presence, error) {
+	return s.repo.ReapExpired(ctx, reapBatch)
+}
+
+// Watchers returns who is told about the user's presence changes: the rooms
+// the user is in and the user's friends.
+func (s *Service) Watchers(ctx context.Context, userID uuid.UUID) ([]model.Room, []uuid.UUID, error) {
+	rooms, err := s.roomRepo.ListUserRooms(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	friends, err := s.friendshipRepo.ListFriendsWithUsers(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	friendIDs := make([]uuid.UUID, 0, len(friends))
+	for _, friend := range friends {
+		friendIDs = append(friendIDs, friend.FriendUserID)
+	}
+
+	return rooms, friendIDs, nil
+}
+
+func (s *Service) ListRoomPresence(ctx context.Context, userID uuid.UUID, roomSlug string) ([]model.Presence, error) {
+	room, _, err := s.access.Member(ctx, userID, roomSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	memberIDs, err := s.roomRepo.ListMemberIDs(ctx, room.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	presences, err := s.repo.GetPresences(ctx, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.Presence, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		result = append(result, presences[memberID])
+	}
+	return result, nil
+}
@@ -0,0 +1,7 @@
+package presence
+
+import "lunar/internal/model"
+
+type ListResponse struct {
+	Presences []model.Presence `json:"presences" binding:"required"`
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"lunar/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment model.Attachment) (model.Attachment, error)
+	// GetAttachment fails with ErrAttachmentNotFound for the attachments of
+	// deleted messages too.
+	GetAttachment(ctx context.Context, id uuid.UUID) (model.Attachment, error)
+	ListAttachmentsByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Attachment, error)
+	// ListAttachments returns the attachments of each of the messages.
+	// Messages without attachments are left out.
+	ListAttachments(ctx context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]model.Attachment, error)
+	// DeleteExpired deletes up to limit attachments that were never sent and
+	// uploaded before unsentBefore, or whose message was deleted before
+	// deletedBefore. The deleted attachments are returned so their files can
+	// be removed.
+	DeleteExpired(ctx context.Context, unsentBefore time.Time, deletedBefore time.Time, limit int) ([]model.Attachment, error)
+}
@@ -4,16 +4,65 @@ import (
 	"context"
 	"errors"
 	"lunar/internal/model"
+	"lunar/internal/pagination"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-var ErrRoomNotFound = errors.New("room not found")
+var (
+	ErrRoomNotFound       = errors.New("room not found")
+	ErrRoomMemberNotFound = errors.New("room member not found")
+	ErrPinNotFound        = errors.New("pin not found")
+	ErrBanNotFound        = errors.New("ban not found")
+)
 
 type RoomRepository interface {
 	ListUserRooms(ctx context.Context, userID uuid.UUID) ([]model.Room, error)
-	Create(ctx context.Context, room model.Room) (model.Room, error)
+	ListUserRoomsWithReadState(ctx context.Context, userID uuid.UUID) ([]model.Room, error)
+	GetReadState(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (model.RoomReadState, error)
+	// AdvanceReadMarker moves the member's read marker to the cursor and
+	// reports false if it already was at or past it.
+	AdvanceReadMarker(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, cursor pagination.Cursor) (bool, error)
+	// Create creates the room with ownerID as its owner.
+	Create(ctx context.Context, room model.Room, ownerID uuid.UUID) (model.Room, error)
+	// Update saves the name and visibility of the room.
+	Update(ctx context.Context, room model.Room) (model.Room, error)
+	// AddMember adds the user as a plain member. It does nothing if the user
+	// already is a member.
 	AddMember(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) error
 	RoomExists(ctx context.Context, id uuid.UUID) (bool, error)
+	Get(ctx context.Context, id uuid.UUID) (model.Room, error)
 	GetBySlug(ctx context.Context, slug string) (model.Room, error)
+	IsMember(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (bool, error)
+	// RemoveMember fails with ErrRoomMemberNotFound if the user is not a
+	// member.
+	RemoveMember(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) error
+	// BanMember removes the user from the room if they are a member and
+	// bans them, replacing any earlier ban. It fails with ErrUserNotFound if
+	// there is no such user.
+	BanMember(ctx context.Context, ban model.RoomBan) error
+	// GetActiveBan fails with ErrBanNotFound unless the user has a ban in
+	// the room that lasts past now.
+	GetActiveBan(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, now time.Time) (model.RoomBan, error)
+	// Unban fails with ErrBanNotFound if the user is not banned.
+	Unban(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) error
+	GetMemberRole(ctx context.Context, roomID uuid.UUID, userID uuid.UUID) (model.RoomRole, error)
+	SetMemberRole(ctx context.Context, roomID uuid.UUID, userID uuid.UUID, role model.RoomRole) error
+	// TransferOwnership makes newOwnerID the owner of the room and the
+	// current owner an admin.
+	TransferOwnership(ctx context.Context, roomID uuid.UUID, ownerID uuid.UUID, newOwnerID uuid.UUID) error
+	ListMemberIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error)
+	// ListMembers pages through the members of the room in the order they
+	// joined, only those whose username starts with usernamePrefix if it is
+	// set. Their presence is left for the caller to fill in.
+	ListMembers(ctx context.Context, roomID uuid.UUID, usernamePrefix string, limit int, cursor *pagination.Cursor) ([]model.RoomMemberProfile, error)
+	// PinMessage reports false if the message already was pinned. It fails
+	// with model.ErrTooManyPins if the room has maxPins pins.
+	PinMessage(ctx context.Context, roomID uuid.UUID, messageID uuid.UUID, pinnedBy uuid.UUID, maxPins int) (bool, error)
+	// UnpinMessage reports false if the message was not pinned.
+	UnpinMessage(ctx context.Context, roomID uuid.UUID, messageID uuid.UUID) (bool, error)
+	GetPin(ctx context.Context, roomID uuid.UUID, messageID uuid.UUID) (model.RoomPin, error)
+	// ListPins returns the pins of the room in the order they were pinned.
+	ListPins(ctx context.Context, roomID uuid.UUID) ([]model.RoomPin, error)
 }
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"lunar/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInviteNotFound = errors.New("invite not found")
+
+type InviteRepository interface {
+	CreateInvite(ctx context.Context, invite model.RoomInvite) (model.RoomInvite, error)
+	// ListActiveInvites returns the invites of the room that can still be
+	// used, newest first.
+	ListActiveInvites(ctx context.Context, roomID uuid.UUID, now time.Time) ([]model.RoomInvite, error)
+	// RevokeInvite fails with ErrInviteNotFound if the room has no such
+	// invite or it was already revoked.
+	RevokeInvite(ctx context.Context, roomID uuid.UUID, inviteID uuid.UUID, at time.Time) error
+	// RedeemInvite adds the user to the room of the invite and counts the
+	// use. Members of the room don't use the invite up again. It fails with
+	// the reason from model.RoomInvite.Usable if the invite can't be used
+	// and with model.ErrBannedFromRoom if the user is banned from the room.
+	RedeemInvite(ctx context.Context, code string, userID uuid.UUID, now time.Time) (model.RoomInvite, error)
+}
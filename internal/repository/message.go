@@ -2,13 +2,47 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"lunar/internal/model"
 	"lunar/internal/pagination"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+var ErrMessageNotFound = errors.New("message not found")
+
+// MessageSearch narrows a message search. Nil fields don't filter.
+type MessageSearch struct {
+	Query         string
+	RoomID        *uuid.UUID
+	SenderID      *uuid.UUID
+	SentAfter     *time.Time
+	SentBefore    *time.Time
+	HasAttachment *bool
+}
+
 type MessageRepository interface {
+	GetMessage(ctx context.Context, id uuid.UUID) (model.Message, error)
 	ListMessages(ctx context.Context, roomID uuid.UUID, limit int, cursor *pagination.Cursor) ([]model.Message, error)
+	// ListThreadMessages pages through the replies of a thread, newest first.
+	ListThreadMessages(ctx context.Context, threadID uuid.UUID, limit int, cursor *pagination.Cursor) ([]model.Message, error)
+	ListMessagesAfter(ctx context.Context, roomID uuid.UUID, limit int, cursor pagination.Cursor) ([]model.Message, error)
 	CreateMessage(ctx context.Context, msg model.Message) (model.Message, error)
+	// UpdateMessage saves the edited content of the message and keeps the
+	// content it replaces as a revision.
+	UpdateMessage(ctx context.Context, msg model.Message) (model.Message, error)
+	ListRevisions(ctx context.Context, messageID uuid.UUID) ([]model.MessageRevision, error)
+	// DeleteMessage stores the tombstone of a message deleted by deletedBy.
+	// It fails with model.ErrMessageDeleted if the message already was.
+	DeleteMessage(ctx context.Context, msg model.Message, deletedBy uuid.UUID) error
+	// SearchMessages finds messages of the rooms userID is a member of, best
+	// matches first.
+	SearchMessages(ctx context.Context, userID uuid.UUID, search MessageSearch, limit int, cursor *pagination.RankedCursor) ([]model.MessageSearchResult, error)
+	// PurgeDeleted erases the content and edit history of up to limit
+	// messages deleted before deletedBefore and returns how many it purged.
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
+	// ListReadReceipts returns the members other than the sender who have
+	// read up to the message and share their read receipts.
+	ListReadReceipts(ctx context.Context, msg model.Message) ([]model.ReadReceipt, error)
 }
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"lunar/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// PresenceRepository tracks the sessions of connected users. A user is online
+// while any of their sessions is online, idle while all of them are idle and
+// offline once the last one is removed or expires. Methods that change a
+// session report whether the user's aggregated status changed.
+type PresenceRepository interface {
+	SetSession(ctx context.Context, userID, sessionID uuid.UUID, status model.PresenceStatus) (model.Presence, bool, error)
+	RemoveSession(ctx context.Context, userID, sessionID uuid.UUID) (model.Presence, bool, error)
+	// ReapExpired removes sessions whose heartbeats stopped and returns the
+	// users whose status changed because of it.
+	ReapExpired(ctx context.Context, limit int) ([]model.Presence, error)
+	GetPresences(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]model.Presence, error)
+}
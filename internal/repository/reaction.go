@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"lunar/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type ReactionRepository interface {
+	// AddReaction reports false if the user already reacted with the emoji.
+	// It fails with model.ErrTooManyReactions if the emoji is new to the
+	// message and maxEmojis distinct emojis are already there.
+	AddReaction(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string, maxEmojis int) (bool, error)
+	// RemoveReaction reports false if the user had not reacted with the emoji.
+	RemoveReaction(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) (bool, error)
+	CountReaction(ctx context.Context, messageID uuid.UUID, emoji string) (int, error)
+	// ListReactions returns the reactions of each of the messages, seen by
+	// viewerID. Messages without reactions are left out.
+	ListReactions(ctx context.Context, messageIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID][]model.Reaction, error)
+}
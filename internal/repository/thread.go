@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"lunar/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type ThreadRepository interface {
+	// GetSummaries sums up the threads under the root messages, seen by
+	// viewerID. Roots without replies are left out.
+	GetSummaries(ctx context.Context, threadIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID]model.ThreadSummary, error)
+	// AutoFollow makes the users follow the thread unless they chose
+	// otherwise before.
+	AutoFollow(ctx context.Context, threadID uuid.UUID, userIDs ...uuid.UUID) error
+	SetFollowing(ctx context.Context, threadID uuid.UUID, userID uuid.UUID, following bool) error
+	ListFollowerIDs(ctx context.Context, threadID uuid.UUID) ([]uuid.UUID, error)
+}
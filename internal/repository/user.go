@@ -18,6 +18,7 @@ type UserRepository interface {
 	ChangeAvatar(ctx context.Context, id uuid.UUID, url string) error
 	UpdateEmail(ctx context.Context, id uuid.UUID, email string) error
 	UpdatePassword(ctx context.Context, id uuid.UUID, newPasswordHash string) error
+	UpdateShareReadReceipts(ctx context.Context, id uuid.UUID, share bool) error
 	CheckUsernameExists(ctx context.Context, username string) (bool, error)
 	CheckEmailExists(ctx context.Context, email string) (bool, error)
 	Create(ctx context.Context, u model.User) (model.User, error)
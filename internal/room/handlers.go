@@ -1,10 +1,19 @@
 package room
 
 import (
+	"errors"
 	"log/slog"
 	"lunar/internal/httputil"
+	"lunar/internal/model"
+	"lunar/internal/pagination"
+	"lunar/internal/repository"
 	"lunar/internal/ws"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Handler struct {
@@ -44,17 +53,19 @@ func (h *Handler) ListRooms(w http.ResponseWriter, r *http.Request) {
 
 // CreateRoom godoc
 //
-//	@Summary	Create a new room
-//	@Tags		room
-//	@Accept		json
-//	@Produce	json
-//	@Security	BearerAuth
-//	@Param		input	body		CreateRequest	true	"Room creation params"
-//	@Success	201		{object}	CreateResponse
-//	@Failure	400		{object}	httputil.ErrorResponse
-//	@Failure	401		{object}	httputil.ErrorResponse
-//	@Failure	500		{object}	httputil.ErrorResponse
-//	@Router		/rooms [post]
+//	@Summary		Create a new room
+//	@Tags			room
+//	@Description	The current user becomes the owner of the room. Rooms are public unless created private.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			input	body		CreateRequest	true	"Room creation params"
+//	@Success		201		{object}	CreateResponse
+//	@Failure		400		{object}	httputil.ErrorResponse
+//	@Failure		401		{object}	httputil.ErrorResponse
+//	@Failure		422		{object}	httputil.ErrorResponse
+//	@Failure		500		{object}	httputil.ErrorResponse
+//	@Router			/rooms [post]
 func (h *Handler) CreateRoom(w http.ResponseWriter, r *http.Request) {
 	var params CreateRequest
 
@@ -63,7 +74,19 @@ func (h *Handler) CreateRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	created, err := h.service.CreateRoom(r.Context(), params.Name)
+	if fieldErrs := h.validator.Validate(&params); fieldErrs != nil {
+		httputil.ValidationError(w, fieldErrs)
+		return
+	}
+
+	visibility := params.Visibility
+	if visibility == "" {
+		visibility = model.RoomPublic
+	}
+
+	user := httputil.UserFromRequest(r)
+
+	created, err := h.service.CreateRoom(r.Context(), user.ID, params.Name, visibility)
 	if err != nil {
 		httputil.InternalError(w, r, err)
 		return
@@ -74,48 +97,834 @@ func (h *Handler) CreateRoom(w http.ResponseWriter, r *http.Request) {
 
 // JoinCurrentUser godoc
 //
-//	@Summary	Join current user to room
-//	@Tags		room
-//	@Param		roomSlug	path	string	true	"Room Slug"
-//	@Security	BearerAuth
-//	@Success	200
-//	@Failure	401	{object}	httputil.ErrorResponse
-//	@Failure	500	{object}	httputil.ErrorResponse
-//	@Router		/rooms/{roomSlug} [post]
+//	@Summary		Join current user to room
+//	@Tags			room
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Security		BearerAuth
+//	@Description	Joins a public room. Private rooms are joined with an invite instead. Banned users can't join.
+//	@Success		200
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug} [post]
 func (h *Handler) JoinCurrentUser(w http.ResponseWriter, r *http.Request) {
 	user := httputil.UserFromRequest(r)
 	roomSlug := r.PathValue("roomSlug")
 
 	if _, err := h.service.JoinUserToRoom(r.Context(), user.ID, roomSlug); err != nil {
+		h.joinError(w, r, err)
+		return
+	}
+
+	httputil.Success(w)
+}
+
+// JoinWithInvite godoc
+//
+//	@Summary		Join a room with an invite
+//	@Tags			room
+//	@Produce		json
+//	@Param			code	path	string	true	"Invite code"
+//	@Security		BearerAuth
+//	@Description	Joins the room of the invite, public or private. Members of the room don't use the invite up.
+//	@Success		200	{object}	model.Room
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		410	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/invites/{code} [post]
+func (h *Handler) JoinWithInvite(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	code := r.PathValue("code")
+
+	room, err := h.service.JoinWithInvite(r.Context(), user.ID, code)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInviteNotFound):
+			httputil.NotFound(w, "Invite not found")
+		case errors.Is(err, model.ErrInviteExpired):
+			httputil.Gone(w, "The invite has expired")
+		case errors.Is(err, model.ErrInviteRevoked):
+			httputil.Gone(w, "The invite has been revoked")
+		case errors.Is(err, model.ErrInviteUsedUp):
+			httputil.Gone(w, "The invite has been used up")
+		case errors.Is(err, model.ErrBannedFromRoom):
+			httputil.Forbidden(w, "You are banned from this room")
+		default:
+			httputil.InternalError(w, r, err)
+		}
+		return
+	}
+
+	httputil.SuccessData(w, room)
+}
+
+func (h *Handler) joinError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, repository.ErrRoomNotFound):
+		httputil.NotFound(w, "Room not found")
+	case errors.Is(err, model.ErrRoomPrivate):
+		httputil.Forbidden(w, "This room is private, join it with an invite")
+	case errors.Is(err, model.ErrBannedFromRoom):
+		httputil.Forbidden(w, "You are banned from this room")
+	default:
 		httputil.InternalError(w, r, err)
+	}
+}
+
+// UpdateRoom godoc
+//
+//	@Summary		Update the settings of a room
+//	@Tags			room
+//	@Accept			json
+//	@Produce		json
+//	@Param			roomSlug	path	string			true	"Room Slug"
+//	@Param			input		body	UpdateRequest	true	"Settings to change"
+//	@Security		BearerAuth
+//	@Description	Renames the room, makes it public or private or sets its pin limit. Only admins and the owner can do so. The room receives a room.updated event.
+//	@Success		200	{object}	model.Room
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		422	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug} [patch]
+func (h *Handler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
+	var req UpdateRequest
+	if err := httputil.Read(r, &req); err != nil {
+		httputil.InvalidRequestBody(w)
+		return
+	}
+
+	if fieldErrs := h.validator.Validate(&req); fieldErrs != nil {
+		httputil.ValidationError(w, fieldErrs)
+		return
+	}
+
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	room, err := h.service.UpdateRoom(r.Context(), user.ID, roomSlug, RoomUpdate{
+		Name:       req.Name,
+		Visibility: req.Visibility,
+		MaxPins:    req.MaxPins,
+	})
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidPinLimit) {
+			httputil.BadRequest(w, "The pin limit must not be negative")
+			return
+		}
+		h.roleError(w, r, err, "You are not allowed to change this room")
+		return
+	}
+
+	h.wsService.PublishRoomEvent(r.Context(), room, ws.EventRoomUpdated, room)
+
+	httputil.SuccessData(w, room)
+}
+
+// CreateInvite godoc
+//
+//	@Summary		Create an invite to a room
+//	@Tags			room
+//	@Accept			json
+//	@Produce		json
+//	@Param			roomSlug	path	string				true	"Room Slug"
+//	@Param			input		body	CreateInviteRequest	true	"Invite limits"
+//	@Security		BearerAuth
+//	@Description	Only admins and the owner can create invites. An invite expires after 7 days unless set otherwise, at most after 30 days.
+//	@Success		201	{object}	model.RoomInvite
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		422	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/invites [post]
+func (h *Handler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	var req CreateInviteRequest
+	if err := httputil.Read(r, &req); err != nil {
+		httputil.InvalidRequestBody(w)
+		return
+	}
+
+	if fieldErrs := h.validator.Validate(&req); fieldErrs != nil {
+		httputil.ValidationError(w, fieldErrs)
+		return
+	}
+
+	ttl := model.DefaultInviteTTL
+	if req.ExpiresIn > 0 {
+		ttl = time.Duration(req.ExpiresIn) * time.Second
+	}
+
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	invite, err := h.service.CreateInvite(r.Context(), user.ID, roomSlug, ttl, req.MaxUses)
+	if err != nil {
+		h.roleError(w, r, err, "You are not allowed to manage invites of this room")
+		return
+	}
+
+	httputil.Created(w, invite)
+}
+
+// ListInvites godoc
+//
+//	@Summary		List the invites of a room
+//	@Tags			room
+//	@Produce		json
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Security		BearerAuth
+//	@Description	Lists the invites that can still be used, newest first. Only admins and the owner can see them.
+//	@Success		200	{object}	InvitesResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/invites [get]
+func (h *Handler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	invites, err := h.service.ListInvites(r.Context(), user.ID, roomSlug)
+	if err != nil {
+		h.roleError(w, r, err, "You are not allowed to manage invites of this room")
+		return
+	}
+
+	httputil.SuccessData(w, InvitesResponse{Invites: invites})
+}
+
+// RevokeInvite godoc
+//
+//	@Summary		Revoke an invite to a room
+//	@Tags			room
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			inviteId	path	string	true	"Invite ID"
+//	@Security		BearerAuth
+//	@Description	Only admins and the owner can revoke invites. Members who joined with the invite stay.
+//	@Success		200
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/invites/{inviteId} [delete]
+func (h *Handler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	inviteID, err := uuid.Parse(r.PathValue("inviteId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid invite ID")
+		return
+	}
+
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	if err := h.service.RevokeInvite(r.Context(), user.ID, roomSlug, inviteID); err != nil {
+		if errors.Is(err, ErrInviteNotFound) {
+			httputil.NotFound(w, "Invite not found")
+			return
+		}
+		h.roleError(w, r, err, "You are not allowed to manage invites of this room")
 		return
 	}
 
 	httputil.Success(w)
 }
 
+// SetMemberRole godoc
+//
+//	@Summary		Change the role of a member
+//	@Tags			room
+//	@Accept			json
+//	@Param			roomSlug	path	string			true	"Room Slug"
+//	@Param			userId		path	string			true	"User ID"
+//	@Param			input		body	SetRoleRequest	true	"New role"
+//	@Security		BearerAuth
+//	@Description	Admins and the owner can change the roles of members ranked below them, up to just below their own. The owner is changed by transferring ownership instead. The room receives a member.role.updated event.
+//	@Success		200
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		422	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/members/{userId}/role [put]
+func (h *Handler) SetMemberRole(w http.ResponseWriter, r *http.Request) {
+	memberID, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	var req SetRoleRequest
+	if err := httputil.Read(r, &req); err != nil {
+		httputil.InvalidRequestBody(w)
+		return
+	}
+
+	if fieldErrs := h.validator.Validate(&req); fieldErrs != nil {
+		httputil.ValidationError(w, fieldErrs)
+		return
+	}
+
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	room, err := h.service.SetMemberRole(r.Context(), user.ID, roomSlug, memberID, req.Role)
+	if err != nil {
+		h.roleError(w, r, err, "You are not allowed to give this member that role")
+		return
+	}
+
+	h.wsService.PublishRoomEvent(r.Context(), room, ws.EventMemberRole, ws.MemberRoleEvent{
+		UserID:    memberID,
+		Role:      req.Role,
+		ChangedBy: user.ID,
+	})
+
+	httputil.Success(w)
+}
+
+// TransferOwnership godoc
+//
+//	@Summary		Transfer ownership of a room
+//	@Tags			room
+//	@Accept			json
+//	@Param			roomSlug	path	string						true	"Room Slug"
+//	@Param			input		body	TransferOwnershipRequest	true	"New owner"
+//	@Security		BearerAuth
+//	@Description	Makes another member the owner. Only the owner can do so and becomes an admin. The room receives a member.role.updated event for both members.
+//	@Success		200
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		422	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/owner [put]
+func (h *Handler) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	var req TransferOwnershipRequest
+	if err := httputil.Read(r, &req); err != nil {
+		httputil.InvalidRequestBody(w)
+		return
+	}
+
+	if fieldErrs := h.validator.Validate(&req); fieldErrs != nil {
+		httputil.ValidationError(w, fieldErrs)
+		return
+	}
+
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	room, err := h.service.TransferOwnership(r.Context(), user.ID, roomSlug, req.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrAlreadyRoomOwner) {
+			httputil.BadRequest(w, "You already own this room")
+			return
+		}
+		h.roleError(w, r, err, "Only the owner can transfer the room")
+		return
+	}
+
+	h.wsService.PublishRoomEvent(r.Context(), room, ws.EventMemberRole, ws.MemberRoleEvent{
+		UserID:    req.UserID,
+		Role:      model.RoomRoleOwner,
+		ChangedBy: user.ID,
+	})
+	h.wsService.PublishRoomEvent(r.Context(), room, ws.EventMemberRole, ws.MemberRoleEvent{
+		UserID:    user.ID,
+		Role:      model.RoomRoleAdmin,
+		ChangedBy: user.ID,
+	})
+
+	httputil.Success(w)
+}
+
+// ListMembers godoc
+//
+//	@Summary		List the members of a room
+//	@Tags			room
+//	@Produce		json
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			q			query	string	false	"Only members whose username starts with this, ignoring case"
+//	@Param			limit		query	int		false	"Limit"
+//	@Param			cursor		query	string	false	"Cursor"
+//	@Security		BearerAuth
+//	@Description	Lists the members of the room with their profile, role and presence in the order they joined. Only members of the room can see them.
+//	@Success		200	{object}	MembersResponse
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/members [get]
+func (h *Handler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+	query := r.URL.Query()
+	limit := pagination.NormalizeLimit(query.Get("limit"), 100, 50)
+
+	var cursor *pagination.Cursor
+	if cursorStr := query.Get("cursor"); cursorStr != "" {
+		c, err := pagination.ParseCursor(cursorStr)
+		if err != nil {
+			httputil.BadRequest(w, "Invalid cursor")
+			return
+		}
+		cursor = &c
+	}
+
+	page, err := h.service.ListMembers(r.Context(), user.ID, roomSlug, strings.TrimSpace(query.Get("q")), limit, cursor)
+	if err != nil {
+		h.roleError(w, r, err, "You are not a member of this room")
+		return
+	}
+
+	var nextCursor string
+	if n := len(page.Members); page.HasMore && n > 0 {
+		nextCursor = h.service.GenerateCursor(page.Members[n-1])
+	}
+
+	httputil.SuccessData(w, MembersResponse{
+		Members:    page.Members,
+		NextCursor: nextCursor,
+	})
+}
+
+// LeaveRoom godoc
+//
+//	@Summary		Leave a room
+//	@Tags			room
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Security		BearerAuth
+//	@Description	Removes the current user from the room. The owner has to transfer the room first. The room receives a member.removed event; the user's per-room sockets are closed with code 4003 and their /ws connections get room.removed.
+//	@Success		200
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		409	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/members/me [delete]
+func (h *Handler) LeaveRoom(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	room, err := h.service.LeaveRoom(r.Context(), user.ID, roomSlug)
+	if err != nil {
+		if errors.Is(err, model.ErrOwnerCannotLeave) {
+			httputil.Conflict(w, "Transfer the room to another member before leaving")
+			return
+		}
+		h.roleError(w, r, err, "You are not allowed to leave this room")
+		return
+	}
+
+	h.wsService.PublishRoomEvent(r.Context(), room, ws.EventMemberRemoved, ws.MemberRemovedEvent{
+		UserID: user.ID,
+		Reason: ws.RemovedLeft,
+	})
+
+	httputil.Success(w)
+}
+
+// KickMember godoc
+//
+//	@Summary		Kick a member from a room
+//	@Tags			room
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			userId		path	string	true	"User ID"
+//	@Security		BearerAuth
+//	@Description	Removes a member ranked below the current user, who has to be at least a moderator. Kicked members can join again. The room receives a member.removed event; the member's per-room sockets are closed with code 4003 and their /ws connections get room.removed.
+//	@Success		200
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/members/{userId} [delete]
+func (h *Handler) KickMember(w http.ResponseWriter, r *http.Request) {
+	memberID, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	room, err := h.service.KickMember(r.Context(), user.ID, roomSlug, memberID)
+	if err != nil {
+		h.roleError(w, r, err, "You are not allowed to kick this member")
+		return
+	}
+
+	h.wsService.PublishRoomEvent(r.Context(), room, ws.EventMemberRemoved, ws.MemberRemovedEvent{
+		UserID: memberID,
+		Reason: ws.RemovedKicked,
+		By:     &user.ID,
+	})
+
+	httputil.Success(w)
+}
+
+// BanMember godoc
+//
+//	@Summary		Ban a member from a room
+//	@Tags			room
+//	@Accept			json
+//	@Produce		json
+//	@Param			roomSlug	path	string		true	"Room Slug"
+//	@Param			userId		path	string		true	"User ID"
+//	@Param			input		body	BanRequest	true	"Ban reason and duration"
+//	@Security		BearerAuth
+//	@Description	Removes a member ranked below the current user, who has to be at least an admin, and keeps them from joining again until the ban expires or is lifted. Users who are not members can be banned too. Bans without expiresIn are permanent. The room receives a member.removed event; the member's per-room sockets are closed with code 4003 and their /ws connections get room.removed.
+//	@Success		200	{object}	model.RoomBan
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		422	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/bans/{userId} [put]
+func (h *Handler) BanMember(w http.ResponseWriter, r *http.Request) {
+	memberID, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	var req BanRequest
+	if err := httputil.Read(r, &req); err != nil {
+		httputil.InvalidRequestBody(w)
+		return
+	}
+
+	if fieldErrs := h.validator.Validate(&req); fieldErrs != nil {
+		httputil.ValidationError(w, fieldErrs)
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresIn > 0 {
+		t := time.Now().Add(time.Duration(req.ExpiresIn) * time.Second)
+		expiresAt = &t
+	}
+
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	room, ban, err := h.service.BanMember(r.Context(), user.ID, roomSlug, memberID, req.Reason, expiresAt)
+	if err != nil {
+		h.roleError(w, r, err, "You are not allowed to ban this member")
+		return
+	}
+
+	h.wsService.PublishRoomEvent(r.Context(), room, ws.EventMemberRemoved, ws.MemberRemovedEvent{
+		UserID:       memberID,
+		Reason:       ws.RemovedBanned,
+		By:           &user.ID,
+		BanReason:    ban.Reason,
+		BanExpiresAt: ban.ExpiresAt,
+	})
+
+	httputil.SuccessData(w, ban)
+}
+
+// UnbanUser godoc
+//
+//	@Summary		Lift a ban from a room
+//	@Tags			room
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			userId		path	string	true	"User ID"
+//	@Security		BearerAuth
+//	@Description	Lets a banned user join the room again. Only admins and the owner can do so.
+//	@Success		200
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/bans/{userId} [delete]
+func (h *Handler) UnbanUser(w http.ResponseWriter, r *http.Request) {
+	bannedID, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	if err := h.service.UnbanUser(r.Context(), user.ID, roomSlug, bannedID); err != nil {
+		if errors.Is(err, ErrBanNotFound) {
+			httputil.NotFound(w, "Ban not found")
+			return
+		}
+		h.roleError(w, r, err, "You are not allowed to lift bans in this room")
+		return
+	}
+
+	httputil.Success(w)
+}
+
+func (h *Handler) roleError(w http.ResponseWriter, r *http.Request, err error, forbidden string) {
+	switch {
+	case errors.Is(err, repository.ErrRoomNotFound):
+		httputil.NotFound(w, "Room not found")
+	case errors.Is(err, ErrNotRoomMember):
+		httputil.Forbidden(w, "You are not a member of this room")
+	case errors.Is(err, ErrPermissionDenied):
+		httputil.Forbidden(w, forbidden)
+	case errors.Is(err, repository.ErrRoomMemberNotFound):
+		httputil.NotFound(w, "Member not found")
+	case errors.Is(err, repository.ErrUserNotFound):
+		httputil.NotFound(w, "User not found")
+	default:
+		httputil.InternalError(w, r, err)
+	}
+}
+
+// MarkRead godoc
+//
+//	@Summary		Mark a room as read up to a message
+//	@Tags			room
+//	@Accept			json
+//	@Produce		json
+//	@Param			roomSlug	path	string			true	"Room Slug"
+//	@Param			input		body	MarkReadRequest	true	"Last read message"
+//	@Security		BearerAuth
+//	@Description	Advances the read marker of the current user. The marker never moves back. The user's other connections receive a read.updated event.
+//	@Success		200	{object}	model.RoomReadState
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		422	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/read [put]
+func (h *Handler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	var req MarkReadRequest
+	if err := httputil.Read(r, &req); err != nil {
+		httputil.InvalidRequestBody(w)
+		return
+	}
+
+	if fieldErrs := h.validator.Validate(&req); fieldErrs != nil {
+		httputil.ValidationError(w, fieldErrs)
+		return
+	}
+
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	room, advanced, err := h.service.MarkRead(r.Context(), user.ID, roomSlug, req.MessageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRoomNotFound):
+			httputil.NotFound(w, "Room not found")
+			return
+		case errors.Is(err, ErrNotRoomMember):
+			httputil.Forbidden(w, "You are not a member of this room")
+			return
+		case errors.Is(err, repository.ErrMessageNotFound):
+			httputil.BadRequest(w, "Message not found in this room")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	if advanced {
+		h.wsService.PublishReadState(r.Context(), user.ID, room)
+	}
+
+	httputil.SuccessData(w, room.ReadState)
+}
+
+// ListPins godoc
+//
+//	@Summary		List pinned messages of a room
+//	@Tags			room
+//	@Produce		json
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Security		BearerAuth
+//	@Description	Returns the pinned messages in the order they were pinned.
+//	@Success		200	{object}	PinsResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/pins [get]
+func (h *Handler) ListPins(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	pins, err := h.service.ListPins(r.Context(), user.ID, roomSlug)
+	if err != nil {
+		h.pinError(w, r, err)
+		return
+	}
+
+	httputil.SuccessData(w, PinsResponse{Pins: pins})
+}
+
+// PinMessage godoc
+//
+//	@Summary		Pin a message
+//	@Tags			room
+//	@Produce		json
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			messageId	path	string	true	"Message ID"
+//	@Security		BearerAuth
+//	@Description	Pins a message of the room. Only moderators can pin and a room has a limited number of pins. The room receives a pin.added event.
+//	@Success		200	{object}	model.RoomPin
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		409	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/pins/{messageId} [put]
+func (h *Handler) PinMessage(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	messageID, err := uuid.Parse(r.PathValue("messageId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid message ID")
+		return
+	}
+
+	room, pin, changed, err := h.service.PinMessage(r.Context(), user.ID, roomSlug, messageID)
+	if err != nil {
+		h.pinError(w, r, err)
+		return
+	}
+
+	if changed {
+		h.wsService.PublishRoomEvent(r.Context(), room, ws.EventPinAdded, pin)
+	}
+
+	httputil.SuccessData(w, pin)
+}
+
+// UnpinMessage godoc
+//
+//	@Summary		Unpin a message
+//	@Tags			room
+//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			messageId	path	string	true	"Message ID"
+//	@Security		BearerAuth
+//	@Description	Only moderators can unpin. The room receives a pin.removed event.
+//	@Success		200
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/rooms/{roomSlug}/pins/{messageId} [delete]
+func (h *Handler) UnpinMessage(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	roomSlug := r.PathValue("roomSlug")
+
+	messageID, err := uuid.Parse(r.PathValue("messageId"))
+	if err != nil {
+		httputil.BadRequest(w, "Invalid message ID")
+		return
+	}
+
+	room, changed, err := h.service.UnpinMessage(r.Context(), user.ID, roomSlug, messageID)
+	if err != nil {
+		h.pinError(w, r, err)
+		return
+	}
+
+	if changed {
+		h.wsService.PublishRoomEvent(r.Context(), room, ws.EventPinRemoved, ws.PinRemovedEvent{
+			MessageID: messageID,
+			UserID:    user.ID,
+		})
+	}
+
+	httputil.Success(w)
+}
+
+func (h *Handler) pinError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, repository.ErrRoomNotFound):
+		httputil.NotFound(w, "Room not found")
+	case errors.Is(err, ErrNotRoomMember):
+		httputil.Forbidden(w, "You are not a member of this room")
+	case errors.Is(err, ErrPermissionDenied):
+		httputil.Forbidden(w, "You are not allowed to pin messages in this room")
+	case errors.Is(err, repository.ErrMessageNotFound):
+		httputil.NotFound(w, "Message not found")
+	case errors.Is(err, model.ErrTooManyPins):
+		httputil.Conflict(w, "The room has too many pinned messages")
+	default:
+		httputil.InternalError(w, r, err)
+	}
+}
+
 // Websocket sockets
 //
 //	@Summary		Connect to the websocket in a room
 //	@Tags			room
-//	@Param			roomSlug	path	string	true	"Room Slug"
+//	@Param			roomSlug		path	string	true	"Room Slug"
+//	@Param			lastSeq			query	int		false	"Replay room events after this sequence number before going live"
+//	@Param			lastMessageId	query	string	false	"Replay messages sent after this one when lastSeq is unavailable"
 //	@Security		WebSocketQueryAuth
-//	@Description	Connect to the websocket to receive real-time notifications in a room
+//	@Description	Connect to the websocket to receive real-time notifications in a room. Only members can connect; join the room first.
 //	@Schemes		ws
+//	@Failure		400	{object}	httputil.ErrorResponse
 //	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		403	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
 //	@Failure		500	{object}	httputil.ErrorResponse
 //	@Router			/rooms/{roomSlug}/ws [get]
 func (h *Handler) Websocket(w http.ResponseWriter, r *http.Request) {
 	user := httputil.UserFromRequest(r)
 	roomSlug := r.PathValue("roomSlug")
 
-	room, err := h.service.JoinUserToRoom(r.Context(), user.ID, roomSlug)
+	var resume ws.Resume
+	if lastSeq := r.URL.Query().Get("lastSeq"); lastSeq != "" {
+		seq, err := strconv.ParseUint(lastSeq, 10, 64)
+		if err != nil {
+			httputil.BadRequest(w, "Invalid last sequence number")
+			return
+		}
+		resume.LastSeq = &seq
+	}
+	if lastMessageID := r.URL.Query().Get("lastMessageId"); lastMessageID != "" {
+		id, err := uuid.Parse(lastMessageID)
+		if err != nil {
+			httputil.BadRequest(w, "Invalid last message ID")
+			return
+		}
+		resume.LastMessageID = id
+	}
+
+	room, err := h.service.GetMemberRoom(r.Context(), user.ID, roomSlug)
 	if err != nil {
-		httputil.InternalError(w, r, err)
+		switch {
+		case errors.Is(err, repository.ErrRoomNotFound):
+			httputil.NotFound(w, "Room not found")
+		case errors.Is(err, ErrNotRoomMember):
+			httputil.Forbidden(w, "You are not a member of this room")
+		default:
+			httputil.InternalError(w, r, err)
+		}
 		return
 	}
 
-	if err := h.wsService.HandleWebSocket(w, r, room, user.ID); err != nil {
+	if err := h.wsService.HandleWebSocket(w, r, room, user.ID, resume); err != nil {
 		slog.Error("websocket error", "err", err)
 	}
 }
@@ -2,37 +2,442 @@ package room
 
 import (
 	"context"
+	"errors"
+	"lunar/internal/access"
 	"lunar/internal/model"
+	"lunar/internal/pagination"
 	"lunar/internal/repository"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrNotRoomMember    = access.ErrNotRoomMember
+	ErrPermissionDenied = access.ErrPermissionDenied
+	ErrInviteNotFound   = repository.ErrInviteNotFound
+	ErrBanNotFound      = repository.ErrBanNotFound
+)
+
 type Service struct {
-	repo repository.RoomRepository
+	access       *access.Checker
+	repo         repository.RoomRepository
+	messageRepo  repository.MessageRepository
+	inviteRepo   repository.InviteRepository
+	presenceRepo repository.PresenceRepository
+	maxPins      int
+}
+
+func NewService(
+	access *access.Checker,
+	repo repository.RoomRepository,
+	messageRepo repository.MessageRepository,
+	inviteRepo repository.InviteRepository,
+	presenceRepo repository.PresenceRepository,
+	maxPins int,
+) *Service {
+	return &Service{access, repo, messageRepo, inviteRepo, presenceRepo, maxPins}
 }
 
-func NewService(repo repository.RoomRepository) *Service {
-	return &Service{repo}
+// RoomUpdate holds the settings of a room to change; nil fields are kept. A
+// MaxPins of zero restores the instance-wide limit.
+type RoomUpdate struct {
+	Name       *string
+	Visibility *model.RoomVisibility
+	MaxPins    *int
 }
 
 func (s *Service) ListUserRooms(ctx context.Context, userID uuid.UUID) ([]model.Room, error) {
-	return s.repo.ListUserRooms(ctx, userID)
+	return s.repo.ListUserRoomsWithReadState(ctx, userID)
 }
 
-func (s *Service) CreateRoom(ctx context.Context, name string) (model.Room, error) {
-	room, err := model.NewRoom(name)
+// CreateRoom creates a room owned by the user.
+func (s *Service) CreateRoom(ctx context.Context, userID uuid.UUID, name string, visibility model.RoomVisibility) (model.Room, error) {
+	room, err := model.NewRoom(name, visibility)
 	if err != nil {
 		return model.Room{}, err
 	}
-	return s.repo.Create(ctx, room)
+	return s.repo.Create(ctx, room, userID)
 }
 
+// JoinUserToRoom adds the user to a public room. Private rooms can only be
+// joined through an invite; their members get the room as is. Banned users
+// can't join.
 func (s *Service) JoinUserToRoom(ctx context.Context, userID uuid.UUID, roomSlug string) (model.Room, error) {
 	room, err := s.repo.GetBySlug(ctx, roomSlug)
 	if err != nil {
 		return model.Room{}, err
 	}
 
-	return room, s.repo.AddMember(ctx, userID, room.ID)
+	_, err = s.repo.GetActiveBan(ctx, room.ID, userID, time.Now())
+	if err == nil {
+		return model.Room{}, model.ErrBannedFromRoom
+	}
+	if !errors.Is(err, repository.ErrBanNotFound) {
+		return model.Room{}, err
+	}
+
+	if room.Visibility == model.RoomPrivate {
+		isMember, err := s.repo.IsMember(ctx, room.ID, userID)
+		if err != nil {
+			return model.Room{}, err
+		}
+		if !isMember {
+			return model.Room{}, model.ErrRoomPrivate
+		}
+		return room, nil
+	}
+
+	return room, s.repo.AddMember(ctx, room.ID, userID)
+}
+
+// GetMemberRoom loads a room the user is a member of.
+func (s *Service) GetMemberRoom(ctx context.Context, userID uuid.UUID, roomSlug string) (model.Room, error) {
+	room, _, err := s.access.Member(ctx, userID, roomSlug)
+	return room, err
+}
+
+// JoinWithInvite adds the user to the room of the invite.
+func (s *Service) JoinWithInvite(ctx context.Context, userID uuid.UUID, code string) (model.Room, error) {
+	invite, err := s.inviteRepo.RedeemInvite(ctx, code, userID, time.Now())
+	if err != nil {
+		return model.Room{}, err
+	}
+	return s.repo.Get(ctx, invite.RoomID)
+}
+
+// MemberPage is a page of room members. HasMore tells whether there are
+// members after it.
+type MemberPage struct {
+	Members []model.RoomMemberProfile
+	HasMore bool
+}
+
+// ListMembers pages through the members of a room the user is a member of in
+// the order they joined, only those whose username starts with usernamePrefix
+// if it is set. One extra member is fetched to tell if there are more.
+func (s *Service) ListMembers(ctx context.Context, userID uuid.UUID, roomSlug string, usernamePrefix string, limit int, cursor *pagination.Cursor) (MemberPage, error) {
+	room, err := s.GetMemberRoom(ctx, userID, roomSlug)
+	if err != nil {
+		return MemberPage{}, err
+	}
+
+	limit = max(limit, 1)
+	members, err := s.repo.ListMembers(ctx, room.ID, usernamePrefix, limit+1, cursor)
+	if err != nil {
+		return MemberPage{}, err
+	}
+
+	hasMore := len(members) > limit
+	if hasMore {
+		members = members[:limit]
+	}
+
+	memberIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.UserID)
+	}
+
+	presences, err := s.presenceRepo.GetPresences(ctx, memberIDs)
+	if err != nil {
+		return MemberPage{}, err
+	}
+	for i := range members {
+		presence := presences[members[i].UserID]
+		members[i].Status = presence.Status
+		members[i].LastSeenAt = presence.LastSeenAt
+	}
+	return MemberPage{Members: members, HasMore: hasMore}, nil
+}
+
+func (s *Service) GenerateCursor(member model.RoomMemberProfile) string {
+	c := pagination.Cursor{
+		ID:        member.UserID,
+		CreatedAt: member.JoinedAt,
+	}
+
+	return c.Encode()
+}
+
+// LeaveRoom removes the user from the room. The owner has to transfer the
+// room before leaving.
+func (s *Service) LeaveRoom(ctx context.Context, userID uuid.UUID, roomSlug string) (model.Room, error) {
+	room, role, err := s.access.Member(ctx, userID, roomSlug)
+	if err != nil {
+		return model.Room{}, err
+	}
+	if role == model.RoomRoleOwner {
+		return model.Room{}, model.ErrOwnerCannotLeave
+	}
+
+	if err := s.repo.RemoveMember(ctx, room.ID, userID); err != nil {
+		return model.Room{}, err
+	}
+	return room, nil
+}
+
+// KickMember removes another member from the room; they may join again. The
+// user needs PermKickMembers and has to outrank the member.
+func (s *Service) KickMember(ctx context.Context, userID uuid.UUID, roomSlug string, memberID uuid.UUID) (model.Room, error) {
+	room, err := s.getRemovableMember(ctx, userID, roomSlug, memberID, model.PermKickMembers)
+	if err != nil {
+		return model.Room{}, err
+	}
+
+	if err := s.repo.RemoveMember(ctx, room.ID, memberID); err != nil {
+		return model.Room{}, err
+	}
+	return room, nil
+}
+
+// BanMember removes another member from the room and keeps them from joining
+// again until expiresAt, or for good if it is nil. The user needs
+// PermBanMembers and has to outrank the member. Users who are not members,
+// for example because they left first, can be banned as well.
+func (s *Service) BanMember(ctx context.Context, userID uuid.UUID, roomSlug string, memberID uuid.UUID, reason string, expiresAt *time.Time) (model.Room, model.RoomBan, error) {
+	room, err := s.getRemovableMember(ctx, userID, roomSlug, memberID, model.PermBanMembers)
+	if err != nil {
+		return model.Room{}, model.RoomBan{}, err
+	}
+
+	ban := model.NewRoomBan(room.ID, memberID, userID, reason, expiresAt)
+	if err := s.repo.BanMember(ctx, ban); err != nil {
+		return model.Room{}, model.RoomBan{}, err
+	}
+	return room, ban, nil
+}
+
+// UnbanUser lifts the ban of a user from the room. It needs PermBanMembers.
+func (s *Service) UnbanUser(ctx context.Context, userID uuid.UUID, roomSlug string, bannedID uuid.UUID) error {
+	room, _, err := s.access.Require(ctx, userID, roomSlug, model.PermBanMembers)
+	if err != nil {
+		return err
+	}
+	return s.repo.Unban(ctx, room.ID, bannedID)
+}
+
+// getRemovableMember loads the room if the user has the permission and
+// outranks the member. Anyone outranks a user who is not a member; removing
+// them is left to fail or not.
+func (s *Service) getRemovableMember(ctx context.Context, userID uuid.UUID, roomSlug string, memberID uuid.UUID, permission model.RoomPermission) (model.Room, error) {
+	room, role, err := s.access.Require(ctx, userID, roomSlug, permission)
+	if err != nil {
+		return model.Room{}, err
+	}
+
+	memberRole, err := s.repo.GetMemberRole(ctx, room.ID, memberID)
+	if errors.Is(err, repository.ErrRoomMemberNotFound) {
+		return room, nil
+	}
+	if err != nil {
+		return model.Room{}, err
+	}
+	if !role.Outranks(memberRole) {
+		return model.Room{}, ErrPermissionDenied
+	}
+	return room, nil
+}
+
+// UpdateRoom changes the settings of the room. Renaming needs PermRenameRoom
+// and changing the visibility PermManageInvites.
+func (s *Service) UpdateRoom(ctx context.Context, userID uuid.UUID, roomSlug string, update RoomUpdate) (model.Room, error) {
+	room, role, err := s.access.Member(ctx, userID, roomSlug)
+	if err != nil {
+		return model.Room{}, err
+	}
+
+	if update.Name != nil {
+		if !role.Can(model.PermRenameRoom) {
+			return model.Room{}, ErrPermissionDenied
+		}
+		room.Name = *update.Name
+	}
+	if update.Visibility != nil {
+		if !role.Can(model.PermManageInvites) {
+			return model.Room{}, ErrPermissionDenied
+		}
+		room.Visibility = *update.Visibility
+	}
+	if update.MaxPins != nil {
+		if !role.Can(model.PermSetPinLimit) {
+			return model.Room{}, ErrPermissionDenied
+		}
+		if *update.MaxPins < 0 {
+			return model.Room{}, model.ErrInvalidPinLimit
+		}
+		room.MaxPins = update.MaxPins
+		if *update.MaxPins == 0 {
+			room.MaxPins = nil
+		}
+	}
+
+	return s.repo.Update(ctx, room)
+}
+
+// CreateInvite creates an invite to the room that expires after ttl and can
+// be used maxUses times, or any number of times if maxUses is nil. It needs
+// PermManageInvites.
+func (s *Service) CreateInvite(ctx context.Context, userID uuid.UUID, roomSlug string, ttl time.Duration, maxUses *int) (model.RoomInvite, error) {
+	room, _, err := s.access.Require(ctx, userID, roomSlug, model.PermManageInvites)
+	if err != nil {
+		return model.RoomInvite{}, err
+	}
+
+	invite, err := model.NewRoomInvite(room.ID, userID, ttl, maxUses)
+	if err != nil {
+		return model.RoomInvite{}, err
+	}
+	return s.inviteRepo.CreateInvite(ctx, invite)
+}
+
+// ListInvites returns the invites of the room that can still be used. It
+// needs PermManageInvites.
+func (s *Service) ListInvites(ctx context.Context, userID uuid.UUID, roomSlug string) ([]model.RoomInvite, error) {
+	room, _, err := s.access.Require(ctx, userID, roomSlug, model.PermManageInvites)
+	if err != nil {
+		return nil, err
+	}
+	return s.inviteRepo.ListActiveInvites(ctx, room.ID, time.Now())
+}
+
+// RevokeInvite makes an invite to the room unusable. It needs
+// PermManageInvites.
+func (s *Service) RevokeInvite(ctx context.Context, userID uuid.UUID, roomSlug string, inviteID uuid.UUID) error {
+	room, _, err := s.access.Require(ctx, userID, roomSlug, model.PermManageInvites)
+	if err != nil {
+		return err
+	}
+	return s.inviteRepo.RevokeInvite(ctx, room.ID, inviteID, time.Now())
+}
+
+// SetMemberRole changes the role of another member. The user needs
+// PermManageRoles and has to outrank both the member's current and new role.
+func (s *Service) SetMemberRole(ctx context.Context, userID uuid.UUID, roomSlug string, memberID uuid.UUID, role model.RoomRole) (model.Room, error) {
+	room, actorRole, err := s.access.Require(ctx, userID, roomSlug, model.PermManageRoles)
+	if err != nil {
+		return model.Room{}, err
+	}
+
+	current, err := s.repo.GetMemberRole(ctx, room.ID, memberID)
+	if err != nil {
+		return model.Room{}, err
+	}
+	if !actorRole.CanAssign(current, role) {
+		return model.Room{}, ErrPermissionDenied
+	}
+
+	if err := s.repo.SetMemberRole(ctx, room.ID, memberID, role); err != nil {
+		return model.Room{}, err
+	}
+	return room, nil
+}
+
+// TransferOwnership hands the room over to another member. Only the owner may
+// do so and stays on as an admin.
+func (s *Service) TransferOwnership(ctx context.Context, userID uuid.UUID, roomSlug string, newOwnerID uuid.UUID) (model.Room, error) {
+	room, _, err := s.access.Require(ctx, userID, roomSlug, model.PermTransferOwnership)
+	if err != nil {
+		return model.Room{}, err
+	}
+	if newOwnerID == userID {
+		return model.Room{}, model.ErrAlreadyRoomOwner
+	}
+
+	if err := s.repo.TransferOwnership(ctx, room.ID, userID, newOwnerID); err != nil {
+		return model.Room{}, err
+	}
+	return room, nil
+}
+
+// MarkRead advances the user's read marker in the room to the message. It
+// never moves the marker back; advanced is false if nothing changed. The
+// returned room carries the new read state.
+func (s *Service) MarkRead(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID) (model.Room, bool, error) {
+	room, err := s.GetMemberRoom(ctx, userID, roomSlug)
+	if err != nil {
+		return model.Room{}, false, err
+	}
+
+	message, err := s.messageRepo.GetMessage(ctx, messageID)
+	if err != nil {
+		return model.Room{}, false, err
+	}
+	if message.RoomID != room.ID {
+		return model.Room{}, false, repository.ErrMessageNotFound
+	}
+
+	cursor := pagination.Cursor{ID: message.ID, CreatedAt: message.CreatedAt}
+	advanced, err := s.repo.AdvanceReadMarker(ctx, room.ID, userID, cursor)
+	if err != nil {
+		return model.Room{}, false, err
+	}
+
+	readState, err := s.repo.GetReadState(ctx, room.ID, userID)
+	if err != nil {
+		return model.Room{}, false, err
+	}
+	room.ReadState = &readState
+
+	return room, advanced, nil
+}
+
+// ListPins returns the pinned messages of the room in pin order.
+func (s *Service) ListPins(ctx context.Context, userID uuid.UUID, roomSlug string) ([]model.RoomPin, error) {
+	room, err := s.GetMemberRoom(ctx, userID, roomSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListPins(ctx, room.ID)
+}
+
+// pinLimit returns the number of messages the room may pin.
+func (s *Service) pinLimit(room model.Room) int {
+	if room.MaxPins != nil {
+		return *room.MaxPins
+	}
+	return s.maxPins
+}
+
+// PinMessage pins a message of the room. It needs PermPinMessages. changed
+// is false if the message already was pinned.
+func (s *Service) PinMessage(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID) (room model.Room, pin model.RoomPin, changed bool, err error) {
+	room, _, err = s.access.Require(ctx, userID, roomSlug, model.PermPinMessages)
+	if err != nil {
+		return model.Room{}, model.RoomPin{}, false, err
+	}
+
+	message, err := s.messageRepo.GetMessage(ctx, messageID)
+	if err != nil {
+		return model.Room{}, model.RoomPin{}, false, err
+	}
+	if message.RoomID != room.ID || message.DeletedAt != nil {
+		return model.Room{}, model.RoomPin{}, false, repository.ErrMessageNotFound
+	}
+
+	changed, err = s.repo.PinMessage(ctx, room.ID, message.ID, userID, s.pinLimit(room))
+	if err != nil {
+		return model.Room{}, model.RoomPin{}, false, err
+	}
+
+	pin, err = s.repo.GetPin(ctx, room.ID, message.ID)
+	if err != nil {
+		return model.Room{}, model.RoomPin{}, false, err
+	}
+	return room, pin, changed, nil
+}
+
+// UnpinMessage unpins a message of the room. It needs PermPinMessages.
+// changed is false if the message was not pinned.
+func (s *Service) UnpinMessage(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID) (model.Room, bool, error) {
+	room, _, err := s.access.Require(ctx, userID, roomSlug, model.PermPinMessages)
+	if err != nil {
+		return model.Room{}, false, err
+	}
+
+	changed, err := s.repo.UnpinMessage(ctx, room.ID, messageID)
+	if err != nil {
+		return model.Room{}, false, err
+	}
+	return room, changed, nil
 }
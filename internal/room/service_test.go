@@ -0,0 +1,479 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"lunar/internal/access"
+	"lunar/internal/model"
+	"lunar/internal/pagination"
+	"lunar/internal/repository"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeRooms keeps a single room with its members and bans. profiles lists
+// the members in the order they joined.
+type fakeRooms struct {
+	repository.RoomRepository
+	room     model.Room
+	members  map[uuid.UUID]model.RoomRole
+	bans     map[uuid.UUID]model.RoomBan
+	profiles []model.RoomMemberProfile
+}
+
+func newFakeRooms(visibility model.RoomVisibility) *fakeRooms {
+	return &fakeRooms{
+		room:    model.Room{ID: uuid.New(), Name: "General", Slug: "general", Visibility: visibility},
+		members: make(map[uuid.UUID]model.RoomRole),
+		bans:    make(map[uuid.UUID]model.RoomBan),
+	}
+}
+
+func (r *fakeRooms) Get(_ context.Context, id uuid.UUID) (model.Room, error) {
+	if id != r.room.ID {
+		return model.Room{}, repository.ErrRoomNotFound
+	}
+	return r.room, nil
+}
+
+func (r *fakeRooms) GetBySlug(_ context.Context, slug string) (model.Room, error) {
+	if slug != r.room.Slug {
+		return model.Room{}, repository.ErrRoomNotFound
+	}
+	return r.room, nil
+}
+
+func (r *fakeRooms) Update(_ context.Context, room model.Room) (model.Room, error) {
+	r.room = room
+	return room, nil
+}
+
+func (r *fakeRooms) IsMember(_ context.Context, _ uuid.UUID, userID uuid.UUID) (bool, error) {
+	_, ok := r.members[userID]
+	return ok, nil
+}
+
+func (r *fakeRooms) GetMemberRole(_ context.Context, _ uuid.UUID, userID uuid.UUID) (model.RoomRole, error) {
+	role, ok := r.members[userID]
+	if !ok {
+		return "", repository.ErrRoomMemberNotFound
+	}
+	return role, nil
+}
+
+func (r *fakeRooms) AddMember(_ context.Context, _ uuid.UUID, userID uuid.UUID) error {
+	if _, ok := r.members[userID]; !ok {
+		r.members[userID] = model.RoomRoleMember
+	}
+	return nil
+}
+
+func (r *fakeRooms) GetActiveBan(_ context.Context, _ uuid.UUID, userID uuid.UUID, now time.Time) (model.RoomBan, error) {
+	ban, ok := r.bans[userID]
+	if !ok || (ban.ExpiresAt != nil && !now.Before(*ban.ExpiresAt)) {
+		return model.RoomBan{}, repository.ErrBanNotFound
+	}
+	return ban, nil
+}
+
+type fakeInvites struct {
+	repository.InviteRepository
+	invite model.RoomInvite
+	err    error
+}
+
+func (r fakeInvites) RedeemInvite(_ context.Context, code string, _ uuid.UUID, _ time.Time) (model.RoomInvite, error) {
+	if r.err != nil {
+		return model.RoomInvite{}, r.err
+	}
+	if code != r.invite.Code {
+		return model.RoomInvite{}, repository.ErrInviteNotFound
+	}
+	return r.invite, nil
+}
+
+type fakePresences struct {
+	repository.PresenceRepository
+}
+
+func (fakePresences) GetPresences(_ context.Context, userIDs []uuid.UUID) (map[uuid.UUID]model.Presence, error) {
+	presences := make(map[uuid.UUID]model.Presence, len(userIDs))
+	for _, id := range userIDs {
+		presences[id] = model.Presence{UserID: id, Status: model.PresenceOnline}
+	}
+	return presences, nil
+}
+
+func newTestService(rooms *fakeRooms, invites repository.InviteRepository) *Service {
+	return NewService(access.NewChecker(rooms), rooms, nil, invites, fakePresences{}, 50)
+}
+
+func TestJoinUserToRoom(t *testing.T) {
+	member := uuid.New()
+	tests := []struct {
+		name       string
+		visibility model.RoomVisibility
+		userID     uuid.UUID
+		want       error
+	}{
+		{"public", model.RoomPublic, uuid.New(), nil},
+		{"public member", model.RoomPublic, member, nil},
+		{"private", model.RoomPrivate, uuid.New(), model.ErrRoomPrivate},
+		{"private member", model.RoomPrivate, member, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := newFakeRooms(tt.visibility)
+			rooms.members[member] = model.RoomRoleModerator
+			s := newTestService(rooms, nil)
+
+			room, err := s.JoinUserToRoom(context.Background(), tt.userID, "general")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("JoinUserToRoom = %v, want %v", err, tt.want)
+			}
+			_, joined := rooms.members[tt.userID]
+			if joined != (tt.want == nil) {
+				t.Errorf("member after joining: %t", joined)
+			}
+			if err == nil && room.ID != rooms.room.ID {
+				t.Errorf("JoinUserToRoom = room %v", room.ID)
+			}
+		})
+	}
+}
+
+func TestJoinUserToRoomKeepsRole(t *testing.T) {
+	rooms := newFakeRooms(model.RoomPublic)
+	admin := uuid.New()
+	rooms.members[admin] = model.RoomRoleAdmin
+	s := newTestService(rooms, nil)
+
+	if _, err := s.JoinUserToRoom(context.Background(), admin, "general"); err != nil {
+		t.Fatal(err)
+	}
+	if role := rooms.members[admin]; role != model.RoomRoleAdmin {
+		t.Errorf("role after joining again = %q", role)
+	}
+}
+
+func TestJoinWithInvite(t *testing.T) {
+	rooms := newFakeRooms(model.RoomPrivate)
+	invite := model.RoomInvite{ID: uuid.New(), RoomID: rooms.room.ID, Code: "abcdefgh"}
+
+	tests := []struct {
+		name   string
+		code   string
+		redeem error
+		want   error
+	}{
+		{"valid", "abcdefgh", nil, nil},
+		{"unknown", "zzzzzzzz", nil, ErrInviteNotFound},
+		{"expired", "abcdefgh", model.ErrInviteExpired, model.ErrInviteExpired},
+		{"used up", "abcdefgh", model.ErrInviteUsedUp, model.ErrInviteUsedUp},
+		{"revoked", "abcdefgh", model.ErrInviteRevoked, model.ErrInviteRevoked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(rooms, fakeInvites{invite: invite, err: tt.redeem})
+
+			room, err := s.JoinWithInvite(context.Background(), uuid.New(), tt.code)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("JoinWithInvite = %v, want %v", err, tt.want)
+			}
+			if err == nil && room.ID != rooms.room.ID {
+				t.Errorf("JoinWithInvite = room %v", room.ID)
+			}
+		})
+	}
+}
+
+func (r *fakeRooms) RemoveMember(_ context.Context, _ uuid.UUID, userID uuid.UUID) error {
+	if _, ok := r.members[userID]; !ok {
+		return repository.ErrRoomMemberNotFound
+	}
+	delete(r.members, userID)
+	return nil
+}
+
+func (r *fakeRooms) BanMember(_ context.Context, ban model.RoomBan) error {
+	delete(r.members, ban.UserID)
+	r.bans[ban.UserID] = ban
+	return nil
+}
+
+func (r *fakeRooms) Unban(_ context.Context, _ uuid.UUID, userID uuid.UUID) error {
+	if _, ok := r.bans[userID]; !ok {
+		return repository.ErrBanNotFound
+	}
+	delete(r.bans, userID)
+	return nil
+}
+
+func TestRemoveMembers(t *testing.T) {
+	tests := []struct {
+		name   string
+		actor  model.RoomRole
+		member model.RoomRole
+		kick   error
+		ban    error
+	}{
+		{"owner removes admin", model.RoomRoleOwner, model.RoomRoleAdmin, nil, nil},
+		{"admin removes moderator", model.RoomRoleAdmin, model.RoomRoleModerator, nil, nil},
+		{"admin removes admin", model.RoomRoleAdmin, model.RoomRoleAdmin, ErrPermissionDenied, ErrPermissionDenied},
+		{"admin removes owner", model.RoomRoleAdmin, model.RoomRoleOwner, ErrPermissionDenied, ErrPermissionDenied},
+		{"moderator removes member", model.RoomRoleModerator, model.RoomRoleMember, nil, ErrPermissionDenied},
+		{"moderator removes moderator", model.RoomRoleModerator, model.RoomRoleModerator, ErrPermissionDenied, ErrPermissionDenied},
+		{"member removes member", model.RoomRoleMember, model.RoomRoleMember, ErrPermissionDenied, ErrPermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor, member := uuid.New(), uuid.New()
+			rooms := newFakeRooms(model.RoomPublic)
+			s := newTestService(rooms, nil)
+
+			rooms.members[actor], rooms.members[member] = tt.actor, tt.member
+			_, err := s.KickMember(context.Background(), actor, "general", member)
+			if !errors.Is(err, tt.kick) {
+				t.Fatalf("KickMember = %v, want %v", err, tt.kick)
+			}
+			if _, stayed := rooms.members[member]; stayed != (tt.kick != nil) {
+				t.Errorf("member stayed after kick: %t", stayed)
+			}
+
+			rooms.members[member] = tt.member
+			_, ban, err := s.BanMember(context.Background(), actor, "general", member, "spam", nil)
+			if !errors.Is(err, tt.ban) {
+				t.Fatalf("BanMember = %v, want %v", err, tt.ban)
+			}
+			if err == nil && (ban.UserID != member || *ban.BannedBy != actor) {
+				t.Errorf("BanMember = ban of %v by %v", ban.UserID, ban.BannedBy)
+			}
+			if _, banned := rooms.bans[member]; banned != (tt.ban == nil) {
+				t.Errorf("member banned: %t", banned)
+			}
+		})
+	}
+}
+
+func TestRemoveNonMember(t *testing.T) {
+	rooms := newFakeRooms(model.RoomPublic)
+	admin := uuid.New()
+	rooms.members[admin] = model.RoomRoleAdmin
+	s := newTestService(rooms, nil)
+
+	_, err := s.KickMember(context.Background(), admin, "general", uuid.New())
+	if !errors.Is(err, repository.ErrRoomMemberNotFound) {
+		t.Fatalf("KickMember = %v, want %v", err, repository.ErrRoomMemberNotFound)
+	}
+}
+
+func TestBanAfterLeave(t *testing.T) {
+	rooms := newFakeRooms(model.RoomPublic)
+	admin, member := uuid.New(), uuid.New()
+	rooms.members[admin], rooms.members[member] = model.RoomRoleAdmin, model.RoomRoleMember
+	s := newTestService(rooms, nil)
+
+	if _, err := s.LeaveRoom(context.Background(), member, "general"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.BanMember(context.Background(), admin, "general", member, "spam", nil); err != nil {
+		t.Fatalf("BanMember after leave = %v", err)
+	}
+	if _, err := s.JoinUserToRoom(context.Background(), member, "general"); !errors.Is(err, model.ErrBannedFromRoom) {
+		t.Fatalf("JoinUserToRoom after ban = %v, want %v", err, model.ErrBannedFromRoom)
+	}
+}
+
+func TestBannedUserCannotJoin(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		want      error
+	}{
+		{"for good", nil, model.ErrBannedFromRoom},
+		{"until later", &future, model.ErrBannedFromRoom},
+		{"expired", &past, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := newFakeRooms(model.RoomPublic)
+			banned := uuid.New()
+			rooms.bans[banned] = model.NewRoomBan(rooms.room.ID, banned, uuid.New(), "", tt.expiresAt)
+			s := newTestService(rooms, nil)
+
+			_, err := s.JoinUserToRoom(context.Background(), banned, "general")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("JoinUserToRoom = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnbanLetsUserJoin(t *testing.T) {
+	rooms := newFakeRooms(model.RoomPublic)
+	admin, banned := uuid.New(), uuid.New()
+	rooms.members[admin] = model.RoomRoleAdmin
+	rooms.bans[banned] = model.NewRoomBan(rooms.room.ID, banned, admin, "", nil)
+	s := newTestService(rooms, nil)
+
+	if err := s.UnbanUser(context.Background(), admin, "general", banned); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.JoinUserToRoom(context.Background(), banned, "general"); err != nil {
+		t.Fatalf("JoinUserToRoom after unban = %v", err)
+	}
+}
+
+func TestLeaveRoom(t *testing.T) {
+	tests := []struct {
+		role model.RoomRole
+		want error
+	}{
+		{model.RoomRoleMember, nil},
+		{model.RoomRoleAdmin, nil},
+		{model.RoomRoleOwner, model.ErrOwnerCannotLeave},
+		{"", ErrNotRoomMember},
+	}
+	for _, tt := range tests {
+		rooms := newFakeRooms(model.RoomPublic)
+		userID := uuid.New()
+		if tt.role != "" {
+			rooms.members[userID] = tt.role
+		}
+		s := newTestService(rooms, nil)
+
+		_, err := s.LeaveRoom(context.Background(), userID, "general")
+		if !errors.Is(err, tt.want) {
+			t.Errorf("LeaveRoom as %q = %v, want %v", tt.role, err, tt.want)
+		}
+		if _, stayed := rooms.members[userID]; stayed != (tt.role == model.RoomRoleOwner) {
+			t.Errorf("%q stayed after leaving: %t", tt.role, stayed)
+		}
+	}
+}
+
+func (r *fakeRooms) ListMembers(_ context.Context, _ uuid.UUID, _ string, limit int, cursor *pagination.Cursor) ([]model.RoomMemberProfile, error) {
+	if limit < 1 {
+		panic("ListMembers with a non-positive limit")
+	}
+	var result []model.RoomMemberProfile
+	for _, profile := range r.profiles {
+		if cursor != nil && !profile.JoinedAt.After(cursor.CreatedAt) {
+			continue
+		}
+		if len(result) == limit {
+			break
+		}
+		result = append(result, profile)
+	}
+	return result, nil
+}
+
+func TestListMembersPages(t *testing.T) {
+	rooms := newFakeRooms(model.RoomPublic)
+	joined := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		profile := model.RoomMemberProfile{UserID: uuid.New(), Role: model.RoomRoleMember, JoinedAt: joined.Add(time.Duration(i) * time.Hour)}
+		rooms.members[profile.UserID] = profile.Role
+		rooms.profiles = append(rooms.profiles, profile)
+	}
+	viewer := rooms.profiles[0].UserID
+	s := newTestService(rooms, nil)
+
+	tests := []struct {
+		limit       int
+		wantPages   []int
+		wantHasMore []bool
+	}{
+		{-1, []int{1, 1, 1, 1, 1}, []bool{true, true, true, true, false}},
+		{0, []int{1, 1, 1, 1, 1}, []bool{true, true, true, true, false}},
+		{2, []int{2, 2, 1}, []bool{true, true, false}},
+		{5, []int{5}, []bool{false}},
+		{50, []int{5}, []bool{false}},
+	}
+	for _, tt := range tests {
+		var cursor *pagination.Cursor
+		var pages []int
+		var hasMore []bool
+		for range 10 {
+			page, err := s.ListMembers(context.Background(), viewer, "general", "", tt.limit, cursor)
+			if err != nil {
+				t.Fatalf("ListMembers(%d): %v", tt.limit, err)
+			}
+			pages = append(pages, len(page.Members))
+			hasMore = append(hasMore, page.HasMore)
+			for _, member := range page.Members {
+				if member.Status != model.PresenceOnline {
+					t.Errorf("ListMembers(%d) left out the presence of %v", tt.limit, member.UserID)
+				}
+			}
+			if !page.HasMore {
+				break
+			}
+			last := page.Members[len(page.Members)-1]
+			cursor = &pagination.Cursor{ID: last.UserID, CreatedAt: last.JoinedAt}
+		}
+		if !slices.Equal(pages, tt.wantPages) || !slices.Equal(hasMore, tt.wantHasMore) {
+			t.Errorf("ListMembers(%d) pages = %v %v, want %v %v", tt.limit, pages, hasMore, tt.wantPages, tt.wantHasMore)
+		}
+	}
+}
+
+func TestListMembersEmpty(t *testing.T) {
+	rooms := newFakeRooms(model.RoomPublic)
+	viewer := uuid.New()
+	rooms.members[viewer] = model.RoomRoleMember
+	s := newTestService(rooms, nil)
+
+	page, err := s.ListMembers(context.Background(), viewer, "general", "nobody", 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Members) != 0 || page.HasMore {
+		t.Errorf("ListMembers = %d members, has more %t", len(page.Members), page.HasMore)
+	}
+}
+
+func TestUpdateRoomPinLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    model.RoomRole
+		maxPins int
+		// want is the pin limit of the room afterwards, 0 for the default.
+		want    int
+		wantErr error
+	}{
+		{"set", model.RoomRoleAdmin, 10, 10, nil},
+		{"restore default", model.RoomRoleAdmin, 0, 0, nil},
+		{"negative", model.RoomRoleAdmin, -1, 5, model.ErrInvalidPinLimit},
+		{"moderator", model.RoomRoleModerator, 10, 5, ErrPermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := newFakeRooms(model.RoomPublic)
+			maxPins := 5
+			rooms.room.MaxPins = &maxPins
+			user := uuid.New()
+			rooms.members[user] = tt.role
+			s := newTestService(rooms, nil)
+
+			_, err := s.UpdateRoom(context.Background(), user, "general", RoomUpdate{MaxPins: &tt.maxPins})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateRoom = %v, want %v", err, tt.wantErr)
+			}
+			var got int
+			if rooms.room.MaxPins != nil {
+				got = *rooms.room.MaxPins
+			}
+			if got != tt.want {
+				t.Errorf("room pin limit = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,16 +2,69 @@ package room
 
 import (
 	"lunar/internal/model"
+
+	"github.com/google/uuid"
 )
 
 type CreateRequest struct {
 	Name string `json:"name" validate:"min=3,max=50,alphanumspace"`
+	// Visibility defaults to public.
+	Visibility model.RoomVisibility `json:"visibility,omitempty" validate:"omitempty,oneof=public private"`
+}
+
+type UpdateRequest struct {
+	Name       *string               `json:"name,omitempty" validate:"omitempty,min=3,max=50,alphanumspace"`
+	Visibility *model.RoomVisibility `json:"visibility,omitempty" validate:"omitempty,oneof=public private"`
+	// MaxPins limits the pinned messages of the room; 0 restores the default.
+	MaxPins *int `json:"maxPins,omitempty" validate:"omitempty,min=0,max=1000"`
+}
+
+type CreateInviteRequest struct {
+	// ExpiresIn is the lifetime of the invite in seconds, 7 days by default.
+	ExpiresIn int `json:"expiresIn,omitempty" validate:"omitempty,min=60,max=2592000"`
+	// MaxUses limits how many users can join with the invite. Without it the
+	// invite works until it expires.
+	MaxUses *int `json:"maxUses,omitempty" validate:"omitempty,min=1,max=1000"`
+}
+
+type SetRoleRequest struct {
+	Role model.RoomRole `json:"role" validate:"required,oneof=admin moderator member"`
+}
+
+type TransferOwnershipRequest struct {
+	UserID uuid.UUID `json:"userId" validate:"required"`
+}
+
+type BanRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=500"`
+	// ExpiresIn is the duration of the ban in seconds. Without it the ban is
+	// permanent.
+	ExpiresIn int `json:"expiresIn,omitempty" validate:"omitempty,min=60"`
 }
 
 type CreateResponse struct {
 	Slug string `json:"slug" binding:"required"`
 }
 
+type MarkReadRequest struct {
+	MessageID uuid.UUID `json:"messageId" validate:"required"`
+}
+
 type ListResponse struct {
 	Rooms []model.Room `json:"rooms" binding:"required"`
 }
+
+// MembersResponse lists members in the order they joined. NextCursor continues
+// with the members who joined later and is empty when there are no more.
+type MembersResponse struct {
+	Members    []model.RoomMemberProfile `json:"members" binding:"required"`
+	NextCursor string                    `json:"nextCursor"`
+}
+
+type InvitesResponse struct {
+	Invites []model.RoomInvite `json:"invites" binding:"required"`
+}
+
+type PinsResponse struct {
+	Pins []model.RoomPin `json:"pins" binding:"required"`
+}
@@ -0,0 +1,130 @@
+package search
+
+import (
+	"errors"
+	"lunar/internal/httputil"
+	"lunar/internal/pagination"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxQueryLength bounds the search terms.
+const maxQueryLength = 256
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// SearchMessages godoc
+//
+//	@Summary		Search messages
+//	@Tags			search
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			q				query	string	true	"Search terms, in web search syntax"
+//	@Param			room			query	string	false	"Only this room (slug)"
+//	@Param			sender			query	string	false	"Only messages of this user (ID)"
+//	@Param			from			query	string	false	"Sent at or after this time (RFC 3339)"
+//	@Param			to				query	string	false	"Sent before this time (RFC 3339)"
+//	@Param			hasAttachment	query	bool	false	"Only messages with or without attachments"
+//	@Param			limit			query	int		false	"Limit"
+//	@Param			cursor			query	string	false	"Cursor"
+//	@Description	Full-text search over the messages of the rooms the current user is a member of. Results are ranked by relevance and carry an excerpt with the matches wrapped in <mark> tags. The excerpt is otherwise not escaped.
+//	@Success		200	{object}	MessagesResponse
+//	@Failure		400	{object}	httputil.ErrorResponse
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/search/messages [get]
+func (h *Handler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+	query := r.URL.Query()
+	limit := pagination.NormalizeLimit(query.Get("limit"), 50, 20)
+
+	filter := MessageFilter{
+		Query:    query.Get("q"),
+		RoomSlug: query.Get("room"),
+	}
+	if filter.Query == "" || utf8.RuneCountInString(filter.Query) > maxQueryLength {
+		httputil.BadRequest(w, "Invalid search query")
+		return
+	}
+
+	if sender := query.Get("sender"); sender != "" {
+		id, err := uuid.Parse(sender)
+		if err != nil {
+			httputil.BadRequest(w, "Invalid sender ID")
+			return
+		}
+		filter.SenderID = &id
+	}
+
+	var err error
+	if filter.From, err = parseTime(query.Get("from")); err != nil {
+		httputil.BadRequest(w, "Invalid from time")
+		return
+	}
+	if filter.To, err = parseTime(query.Get("to")); err != nil {
+		httputil.BadRequest(w, "Invalid to time")
+		return
+	}
+
+	if hasAttachment := query.Get("hasAttachment"); hasAttachment != "" {
+		value, err := strconv.ParseBool(hasAttachment)
+		if err != nil {
+			httputil.BadRequest(w, "Invalid hasAttachment")
+			return
+		}
+		filter.HasAttachment = &value
+	}
+
+	var cursor *pagination.RankedCursor
+	if cursorStr := query.Get("cursor"); cursorStr != "" {
+		c, err := pagination.ParseRankedCursor(cursorStr)
+		if err != nil {
+			httputil.BadRequest(w, "Invalid cursor")
+			return
+		}
+		cursor = &c
+	}
+
+	results, err := h.service.SearchMessages(r.Context(), user.ID, filter, limit, cursor)
+	if err != nil {
+		if errors.Is(err, ErrRoomNotFound) {
+			httputil.NotFound(w, "Room not found")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	var nextCursor string
+	if len(results) == limit {
+		nextCursor = h.service.GenerateCursor(results[len(results)-1])
+	}
+
+	httputil.SuccessData(w, MessagesResponse{
+		Results:    results,
+		NextCursor: nextCursor,
+	})
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
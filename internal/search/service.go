@@ -0,0 +1,90 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"lunar/internal/model"
+	"lunar/internal/pagination"
+	"lunar/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrRoomNotFound = errors.New("room not found")
+
+// MessageFilter narrows a message search. Empty and nil fields don't filter.
+type MessageFilter struct {
+	Query         string
+	RoomSlug      string
+	SenderID      *uuid.UUID
+	From          *time.Time
+	To            *time.Time
+	HasAttachment *bool
+}
+
+type Service struct {
+	roomRepo       repository.RoomRepository
+	messageRepo    repository.MessageRepository
+	attachmentRepo repository.AttachmentRepository
+}
+
+func NewService(roomRepo repository.RoomRepository, messageRepo repository.MessageRepository, attachmentRepo repository.AttachmentRepository) *Service {
+	return &Service{roomRepo, messageRepo, attachmentRepo}
+}
+
+// SearchMessages runs a full-text search over the messages of the rooms the
+// user is a member of, best matches first.
+func (s *Service) SearchMessages(ctx context.Context, userID uuid.UUID, filter MessageFilter, limit int, cursor *pagination.RankedCursor) ([]model.MessageSearchResult, error) {
+	search := repository.MessageSearch{
+		Query:         filter.Query,
+		SenderID:      filter.SenderID,
+		SentAfter:     filter.From,
+		SentBefore:    filter.To,
+		HasAttachment: filter.HasAttachment,
+	}
+
+	if filter.RoomSlug != "" {
+		room, err := s.roomRepo.GetBySlug(ctx, filter.RoomSlug)
+		if err != nil {
+			if errors.Is(err, repository.ErrRoomNotFound) {
+				return nil, ErrRoomNotFound
+			}
+			return nil, err
+		}
+		search.RoomID = &room.ID
+	}
+
+	results, err := s.messageRepo.SearchMessages(ctx, userID, search, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	ids := make([]uuid.UUID, len(results))
+	for i, result := range results {
+		ids[i] = result.Message.ID
+	}
+	attachments, err := s.attachmentRepo.ListAttachments(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Message.Attachments = attachments[results[i].Message.ID]
+	}
+	return results, nil
+}
+
+func (s *Service) GenerateCursor(result model.MessageSearchResult) string {
+	c := pagination.RankedCursor{
+		Rank: result.Rank,
+		Cursor: pagination.Cursor{
+			ID:        result.Message.ID,
+			CreatedAt: result.Message.CreatedAt,
+		},
+	}
+
+	return c.Encode()
+}
@@ -0,0 +1,10 @@
+package search
+
+import (
+	"lunar/internal/model"
+)
+
+type MessagesResponse struct {
+	Results    []model.MessageSearchResult `json:"results" binding:"required"`
+	NextCursor string                      `json:"nextCursor"`
+}
@@ -2,8 +2,10 @@ package user
 
 import (
 	"errors"
+	"io"
 	"lunar/internal/httputil"
 	"net/http"
+	"strconv"
 )
 
 type Handler struct {
@@ -129,6 +131,40 @@ func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	httputil.Success(w)
 }
 
+// UpdatePrivacy updates the user's privacy settings
+//
+//	@Summary	Update user privacy settings
+//	@Tags		user
+//	@Accept		json
+//	@Security	BearerAuth
+//	@Param		input	body	UpdatePrivacyRequest	true	"Privacy settings"
+//	@Success	204
+//	@Failure	400	{object}	httputil.ErrorResponse
+//	@Failure	401	{object}	httputil.ErrorResponse
+//	@Failure	422	{object}	httputil.ErrorResponse
+//	@Failure	500	{object}	httputil.ErrorResponse
+//	@Router		/users/me/privacy [put]
+func (h *Handler) UpdatePrivacy(w http.ResponseWriter, r *http.Request) {
+	var req UpdatePrivacyRequest
+	if err := httputil.Read(r, &req); err != nil {
+		httputil.InvalidRequestBody(w)
+		return
+	}
+
+	if fieldErrs := h.validator.Validate(&req); fieldErrs != nil {
+		httputil.ValidationError(w, fieldErrs)
+		return
+	}
+
+	userCtx := httputil.UserFromRequest(r)
+	if err := h.service.UpdatePrivacy(r.Context(), userCtx.ID, *req.ShareReadReceipts); err != nil {
+		httputil.InternalError(w, r, err)
+		return
+	}
+
+	httputil.Success(w)
+}
+
 // UploadAvatar uploads a new avatar for the user
 //
 //	@Summary	Upload user avatar
@@ -155,7 +191,7 @@ func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	filename, err := h.service.UploadAvatar(file)
+	filename, err := h.service.UploadAvatar(r.Context(), file)
 	if err != nil {
 		switch {
 		case errors.Is(err, ErrInvalidImage), errors.Is(err, ErrUploadAvatar):
@@ -175,3 +211,36 @@ func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
 
 	httputil.Success(w)
 }
+
+// Avatar serves an avatar image
+//
+//	@Summary		Get an avatar image
+//	@Tags			user
+//	@Produce		image/jpeg
+//	@Param			filename	path	string	true	"Avatar file name from avatarUrl"
+//	@Description	Avatars are public and served from the file store, whichever backend it uses.
+//	@Success		200	{file}		file
+//	@Failure		404	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/uploads/avatars/{filename} [get]
+func (h *Handler) Avatar(w http.ResponseWriter, r *http.Request) {
+	avatar, err := h.service.OpenAvatar(r.Context(), r.PathValue("filename"))
+	if err != nil {
+		if errors.Is(err, ErrAvatarNotFound) {
+			httputil.NotFound(w, "Avatar not found")
+			return
+		}
+		httputil.InternalError(w, r, err)
+		return
+	}
+	defer avatar.Close()
+
+	w.Header().Set("Content-Type", avatar.ContentType)
+	if avatar.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(avatar.Size, 10))
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// Avatar file names are never reused.
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	_, _ = io.Copy(w, avatar)
+}
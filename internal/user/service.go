@@ -1,33 +1,34 @@
 package user
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"image"
 	"image/jpeg"
 	"log/slog"
 	"lunar/internal/auth"
+	"lunar/internal/filestore"
 	"lunar/internal/model"
 	"lunar/internal/repository"
 	"mime/multipart"
-	"os"
-	"path/filepath"
 
 	"github.com/disintegration/imaging"
 	"github.com/google/uuid"
 )
 
 type Service struct {
-	repo             repository.UserRepository
-	authService      *auth.Service
-	avatarsUploadDir string
+	repo        repository.UserRepository
+	authService *auth.Service
+	avatars     filestore.Store
 }
 
-func NewService(repo repository.UserRepository, authService *auth.Service, avatarsUploadDir string) *Service {
+func NewService(repo repository.UserRepository, authService *auth.Service, avatars filestore.Store) *Service {
 	return &Service{
 		repo,
 		authService,
-		avatarsUploadDir,
+		avatars,
 	}
 }
 
@@ -68,7 +69,11 @@ func (s *Service) UpdatePassword(ctx context.Context, id uuid.UUID, currentPassw
 	return s.repo.UpdatePassword(ctx, user.ID, user.PasswordHash)
 }
 
-func (s *Service) UploadAvatar(file multipart.File) (string, error) {
+func (s *Service) UpdatePrivacy(ctx context.Context, id uuid.UUID, shareReadReceipts bool) error {
+	return s.repo.UpdateShareReadReceipts(ctx, id, shareReadReceipts)
+}
+
+func (s *Service) UploadAvatar(ctx context.Context, file multipart.File) (string, error) {
 	img, format, err := image.Decode(file)
 	if err != nil {
 		return "", err
@@ -78,18 +83,29 @@ func (s *Service) UploadAvatar(file multipart.File) (string, error) {
 	}
 	dstImg := imaging.Fill(img, 128, 128, imaging.Center, imaging.Lanczos)
 
-	resultFilename := fmt.Sprintf("%s.%s", uuid.New().String(), format)
-	filePath := filepath.Join(s.avatarsUploadDir, resultFilename)
-	out, err := os.Create(filePath)
-	if err != nil {
-		slog.Error("file upload", "err", err, "dir", s.avatarsUploadDir)
-		return "", ErrUploadAvatar
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dstImg, &jpeg.Options{Quality: 80}); err != nil {
+		return "", err
 	}
-	defer out.Close()
 
-	if err := jpeg.Encode(out, dstImg, &jpeg.Options{Quality: 80}); err != nil {
-		return "", err
+	resultFilename := fmt.Sprintf("%s.jpg", uuid.New().String())
+	if err := s.avatars.Put(ctx, resultFilename, &buf, int64(buf.Len()), "image/jpeg"); err != nil {
+		slog.Error("file upload", "err", err)
+		return "", ErrUploadAvatar
 	}
 
 	return resultFilename, nil
 }
+
+// OpenAvatar opens an avatar image by the file name stored on the user. The
+// caller closes it.
+func (s *Service) OpenAvatar(ctx context.Context, filename string) (*filestore.Object, error) {
+	avatar, err := s.avatars.Get(ctx, filename)
+	if err != nil {
+		if errors.Is(err, filestore.ErrNotFound) {
+			return nil, ErrAvatarNotFound
+		}
+		return nil, err
+	}
+	return avatar, nil
+}
@@ -10,6 +10,7 @@ var (
 	ErrInvalidCurrentPassword = errors.New("invalid current password")
 	ErrInvalidImage           = errors.New("invalid image")
 	ErrUploadAvatar           = errors.New("failed to upload avatar")
+	ErrAvatarNotFound         = errors.New("avatar not found")
 )
 
 type UpdateEmailRequest struct {
@@ -21,6 +22,10 @@ type UpdatePasswordRequest struct {
 	NewPassword     string `json:"newPassword" validate:"required,min=6"`
 }
 
+type UpdatePrivacyRequest struct {
+	ShareReadReceipts *bool `json:"shareReadReceipts" validate:"required"`
+}
+
 type SendVerificationCodeRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
@@ -3,12 +3,21 @@ package util
 import "crypto/rand"
 
 const (
-	roomSlugLength = 11
-	alphabet       = "abcdefghijklmnopqrstuvwxyz23456789"
+	roomSlugLength   = 11
+	inviteCodeLength = 10
+	alphabet         = "abcdefghijklmnopqrstuvwxyz23456789"
 )
 
 func GenerateRoomSlug() (string, error) {
-	bytes := make([]byte, roomSlugLength)
+	return randomString(roomSlugLength)
+}
+
+func GenerateInviteCode() (string, error) {
+	return randomString(inviteCodeLength)
+}
+
+func randomString(length int) (string, error) {
+	bytes := make([]byte, length)
 	if _, err := rand.Read(bytes); err != nil {
 		return "", err
 	}
@@ -0,0 +1,276 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"lunar/internal/model"
+	"lunar/internal/pagination"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	errSendQueueFull   = errors.New("send queue full")
+	errRemovedFromRoom = errors.New("removed from room")
+)
+
+// client is a single WebSocket connection. Everything written to the
+// connection goes through send, so the outgoing loop is its only writer.
+type client struct {
+	conn   *websocket.Conn
+	send   chan []byte
+	done   <-chan struct{}
+	cancel context.CancelCauseFunc
+	user   model.User
+
+	// defaultRoom is the room slug a per-room connection is bound to;
+	// commands without a room target it.
+	defaultRoom string
+	// sessionID identifies the connection in the user's presence.
+	sessionID uuid.UUID
+
+	mu      sync.Mutex
+	subs    map[string]*subscription
+	userSub *subscription
+	status  model.PresenceStatus
+	closed  bool
+}
+
+func newClient(ctx context.Context, cancel context.CancelCauseFunc, conn *websocket.Conn, user model.User, queueSize int) *client {
+	return &client{
+		conn:      conn,
+		send:      make(chan []byte, queueSize),
+		done:      ctx.Done(),
+		cancel:    cancel,
+		user:      user,
+		sessionID: uuid.New(),
+		subs:      make(map[string]*subscription),
+		status:    model.PresenceOnline,
+	}
+}
+
+// enqueue waits for room in the send queue. It is used by the connection's
+// own goroutines, which may as well wait for the outgoing loop.
+func (c *client) enqueue(payload []byte) error {
+	select {
+	case c.send <- payload:
+		return nil
+	case <-c.done:
+		return context.Canceled
+	}
+}
+
+// offer queues a payload without waiting. The hub shares one goroutine
+// between every subscriber of a room, so a client that can't keep up is
+// disconnected rather than allowed to stall the others.
+func (c *client) offer(payload []byte) error {
+	select {
+	case c.send <- payload:
+		return nil
+	default:
+		c.cancel(errSendQueueFull)
+		return errSendQueueFull
+	}
+}
+
+// closeWith sends a close frame. It may be called while the outgoing loop is
+// writing because control frames don't go through the send queue.
+func (c *client) closeWith(code int, reason string) error {
+	msg := websocket.FormatCloseMessage(code, reason)
+	return c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+}
+
+func (c *client) emit(eventType, room, id string, data any) error {
+	payload, err := encodeEnvelope(eventType, room, id, data)
+	if err != nil {
+		return err
+	}
+	return c.enqueue(payload)
+}
+
+func (c *client) subscription(room string) (*subscription, bool) {
+	if room == "" {
+		room = c.defaultRoom
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sub, ok := c.subs[room]
+	return sub, ok
+}
+
+// addSubscription reports false once the client has been torn down.
+func (c *client) addSubscription(sub *subscription) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	c.subs[sub.room.Slug] = sub
+	return true
+}
+
+func (c *client) removeSubscription(room string) (*subscription, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sub, ok := c.subs[room]
+	delete(c.subs, room)
+	return sub, ok
+}
+
+// dropSubscription removes sub unless the room was resubscribed since.
+func (c *client) dropSubscription(sub *subscription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.subs[sub.room.Slug] == sub {
+		delete(c.subs, sub.room.Slug)
+	}
+}
+
+func (c *client) presenceStatus() model.PresenceStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.status
+}
+
+func (c *client) setPresenceStatus(status model.PresenceStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.status = status
+}
+
+// setUserSubscription reports false once the client has been torn down.
+func (c *client) setUserSubscription(sub *subscription) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	c.userSub = sub
+	return true
+}
+
+// removeSubscriptions tears the client down and returns its room
+// subscriptions and its user subscription, if any.
+func (c *client) removeSubscriptions() ([]*subscription, *subscription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	userSub := c.userSub
+	c.userSub = nil
+
+	subs := make([]*subscription, 0, len(c.subs))
+	for room, sub := range c.subs {
+		subs = append(subs, sub)
+		delete(c.subs, room)
+	}
+	return subs, userSub
+}
+
+// subscription is a client's interest in one topic: a room, or the user
+// itself for events addressed to all of the user's connections. lastID and
+// watermark are owned by whoever feeds the subscription from the stream.
+type subscription struct {
+	client *client
+	topic  uuid.UUID
+	// room is zero for user subscriptions.
+	room model.Room
+
+	// lastID is the stream ID of the last event delivered.
+	lastID string
+	// watermark is set after a database replay; live messages up to it were
+	// already sent and are dropped.
+	watermark *pagination.Cursor
+
+	typingMu     sync.Mutex
+	typingTimer  *time.Timer
+	typingSentAt time.Time
+}
+
+func newSubscription(c *client, room model.Room) *subscription {
+	return &subscription{client: c, topic: room.ID, room: room}
+}
+
+func newUserSubscription(c *client) *subscription {
+	return &subscription{client: c, topic: c.user.ID}
+}
+
+// deliver forwards a stream event unless the subscription has already seen it.
+func (sub *subscription) deliver(event streamEvent) error {
+	if compareStreamIDs(event.ID, sub.lastID) <= 0 {
+		return nil
+	}
+	sub.lastID = event.ID
+
+	if event.Ephemeral && event.Sender == sub.client.user.ID {
+		return nil
+	}
+	if sub.replayed(event.Payload) {
+		return nil
+	}
+	if err := sub.client.offer(event.Payload); err != nil {
+		return err
+	}
+
+	// Every instance subscribed to the room sees the event, so this drops
+	// the removed user's subscriptions wherever they are. A per-room
+	// connection has nothing left to serve and is closed.
+	if event.Removed == sub.client.user.ID {
+		if sub.client.defaultRoom != "" {
+			sub.client.cancel(errRemovedFromRoom)
+			return errRemovedFromRoom
+		}
+		return sub.remove()
+	}
+	return nil
+}
+
+// remove drops the subscription from its client and tells the client so. It
+// returns errRemovedFromRoom for the hub to drop it as well.
+func (sub *subscription) remove() error {
+	sub.client.dropSubscription(sub)
+
+	payload, err := encodeEnvelope(EventRoomRemoved, sub.room.Slug, "", RoomRemovedEvent{RoomID: sub.room.ID})
+	if err != nil {
+		return err
+	}
+	if err := sub.client.offer(payload); err != nil {
+		return err
+	}
+	return errRemovedFromRoom
+}
+
+// replayed reports whether a live payload was already sent during replay.
+func (sub *subscription) replayed(payload []byte) bool {
+	if sub.watermark == nil {
+		return false
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(payload, &env); err != nil || env.Type != EventMessageCreated {
+		return false
+	}
+
+	var message model.Message
+	if err := json.Unmarshal(env.Data, &message); err != nil {
+		return false
+	}
+
+	if afterCursor(message, *sub.watermark) {
+		sub.watermark = nil
+		return false
+	}
+	return true
+}
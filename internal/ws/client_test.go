@@ -0,0 +1,173 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"lunar/internal/model"
+	"lunar/internal/pagination"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestSubscription(t *testing.T, queueSize int) (*subscription, context.Context) {
+	t.Helper()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	t.Cleanup(func() { cancel(nil) })
+
+	user := model.User{ID: uuid.New()}
+	c := newClient(ctx, cancel, nil, user, queueSize)
+	return newSubscription(c, model.Room{ID: uuid.New(), Slug: "room"}), ctx
+}
+
+func sequenced(t *testing.T, seq uint64, eventType string, data any) streamEvent {
+	t.Helper()
+	return streamEvent{ID: streamID(seq), Seq: seq, Payload: []byte(mustEnvelope(t, eventType, data))}
+}
+
+func TestDeliverSkipsSeenEvents(t *testing.T) {
+	sub, _ := newTestSubscription(t, 8)
+	sub.lastID = streamID(5)
+
+	for _, seq := range []uint64{4, 5, 6, 6, 7} {
+		if err := sub.deliver(sequenced(t, seq, EventMessageDeleted, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(sub.client.send); got != 2 {
+		t.Errorf("delivered %d events, want 2", got)
+	}
+	if sub.lastID != streamID(7) {
+		t.Errorf("lastID = %s, want %s", sub.lastID, streamID(7))
+	}
+}
+
+func TestDeliverDropsReplayedMessages(t *testing.T) {
+	sub, _ := newTestSubscription(t, 8)
+
+	now := time.Now()
+	replayed := model.Message{ID: uuid.New(), CreatedAt: now}
+	fresh := model.Message{ID: uuid.New(), CreatedAt: now.Add(time.Second)}
+	sub.watermark = &pagination.Cursor{ID: replayed.ID, CreatedAt: replayed.CreatedAt}
+
+	if err := sub.deliver(sequenced(t, 1, EventMessageCreated, replayed)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.deliver(sequenced(t, 2, EventMessageCreated, fresh)); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(sub.client.send); got != 1 {
+		t.Errorf("delivered %d messages, want 1", got)
+	}
+	if sub.watermark != nil {
+		t.Error("watermark should be cleared once live messages pass it")
+	}
+}
+
+func TestDeliverDisconnectsSlowConsumer(t *testing.T) {
+	sub, ctx := newTestSubscription(t, 1)
+
+	if err := sub.deliver(sequenced(t, 1, EventMessageDeleted, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.deliver(sequenced(t, 2, EventMessageDeleted, nil)); !errors.Is(err, errSendQueueFull) {
+		t.Fatalf("deliver = %v, want errSendQueueFull", err)
+	}
+	if !errors.Is(context.Cause(ctx), errSendQueueFull) {
+		t.Errorf("cause = %v, want errSendQueueFull", context.Cause(ctx))
+	}
+}
+
+func TestDeliverSkipsOwnEphemeralEvents(t *testing.T) {
+	sub, _ := newTestSubscription(t, 8)
+
+	own := streamEvent{ID: "1-1", Ephemeral: true, Sender: sub.client.user.ID, Payload: []byte("{}")}
+	other := streamEvent{ID: "1-2", Ephemeral: true, Sender: uuid.New(), Payload: []byte("{}")}
+	for _, event := range []streamEvent{own, other} {
+		if err := sub.deliver(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(sub.client.send); got != 1 {
+		t.Errorf("delivered %d events, want 1", got)
+	}
+}
+
+func TestDeliverClosesRemovedRoomSocket(t *testing.T) {
+	sub, ctx := newTestSubscription(t, 8)
+	sub.client.defaultRoom = sub.room.Slug
+
+	other := sequenced(t, 1, EventMemberRemoved, MemberRemovedEvent{UserID: uuid.New()})
+	other.Removed = uuid.New()
+	if err := sub.deliver(other); err != nil {
+		t.Fatalf("removing someone else: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("client was closed for another member's removal")
+	}
+
+	own := sequenced(t, 2, EventMemberRemoved, MemberRemovedEvent{UserID: sub.client.user.ID})
+	own.Removed = sub.client.user.ID
+	if err := sub.deliver(own); !errors.Is(err, errRemovedFromRoom) {
+		t.Fatalf("deliver = %v, want errRemovedFromRoom", err)
+	}
+	if !errors.Is(context.Cause(ctx), errRemovedFromRoom) {
+		t.Errorf("cause = %v, want errRemovedFromRoom", context.Cause(ctx))
+	}
+	if got := len(sub.client.send); got != 2 {
+		t.Errorf("queued %d events, want the removal to be delivered too", got)
+	}
+}
+
+func TestDeliverKeepsOtherRoomsAfterRemoval(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	t.Cleanup(func() { cancel(nil) })
+	c := newClient(ctx, cancel, nil, model.User{ID: uuid.New()}, 8)
+
+	topics := make(map[string]*hubTopic)
+	for _, slug := range []string{"left", "stayed"} {
+		sub := newSubscription(c, model.Room{ID: uuid.New(), Slug: slug})
+		c.addSubscription(sub)
+		topics[slug] = &hubTopic{subs: map[*subscription]struct{}{sub: {}}}
+	}
+
+	removal := sequenced(t, 1, EventMemberRemoved, MemberRemovedEvent{UserID: c.user.ID})
+	removal.Removed = c.user.ID
+	if !topics["left"].fanOut([]streamEvent{removal}) {
+		t.Fatal("removed subscription was not dropped from its topic")
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("connection was closed: %v", context.Cause(ctx))
+	}
+	if _, ok := c.subscription("left"); ok {
+		t.Error("client is still subscribed to the room it was removed from")
+	}
+
+	if got := drainTypes(t, c); !slices.Equal(got, []string{EventMemberRemoved, EventRoomRemoved}) {
+		t.Errorf("sent %v, want member.removed and room.removed", got)
+	}
+
+	topics["left"].fanOut([]streamEvent{sequenced(t, 2, EventMessageCreated, model.Message{})})
+	topics["stayed"].fanOut([]streamEvent{sequenced(t, 1, EventMessageCreated, model.Message{})})
+	if got := drainTypes(t, c); !slices.Equal(got, []string{EventMessageCreated}) {
+		t.Errorf("sent %v after the removal, want one message.created", got)
+	}
+}
+
+// drainTypes empties the send queue of the client and returns the types of
+// the events in it.
+func drainTypes(t *testing.T, c *client) []string {
+	t.Helper()
+	var types []string
+	for len(c.send) > 0 {
+		var env Envelope
+		if err := json.Unmarshal(<-c.send, &env); err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, env.Type)
+	}
+	return types
+}
@@ -0,0 +1,272 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"lunar/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProtocolVersion is announced to clients in the hello event. It is bumped
+// only on breaking changes; new event types are added without a bump and
+// clients are expected to ignore types they don't know.
+const ProtocolVersion = 1
+
+// Inbound commands sent by clients.
+const (
+	CommandSubscribe     = "subscribe"
+	CommandUnsubscribe   = "unsubscribe"
+	CommandSendMessage   = "message.send"
+	CommandEditMessage   = "message.edit"
+	CommandDeleteMessage = "message.delete"
+	CommandTypingStart   = "typing.start"
+	CommandTypingStop    = "typing.stop"
+	CommandSetPresence   = "presence.set"
+	CommandAck           = "ack"
+)
+
+// Outbound events sent by the server.
+const (
+	EventHello           = "hello"
+	EventSubscribed      = "subscribed"
+	EventUnsubscribed    = "unsubscribed"
+	EventResumed         = "resumed"
+	EventResync          = "resync"
+	EventMessageCreated  = "message.created"
+	EventMessageUpdated  = "message.updated"
+	EventMessageDeleted  = "message.deleted"
+	EventReactionAdded   = "reaction.added"
+	EventReactionRemoved = "reaction.removed"
+	EventThreadReply     = "thread.reply.created"
+	EventPinAdded        = "pin.added"
+	EventPinRemoved      = "pin.removed"
+	EventRoomUpdated     = "room.updated"
+	EventMemberRole      = "member.role.updated"
+	EventMemberRemoved   = "member.removed"
+	EventRoomRemoved     = "room.removed"
+	EventThreadActivity  = "thread.activity"
+	EventTypingStarted   = "typing.started"
+	EventTypingStopped   = "typing.stopped"
+	EventPresence        = "presence.updated"
+	EventReadUpdated     = "read.updated"
+	EventReceipt         = "receipt.updated"
+	EventError           = "error"
+)
+
+// Error codes carried by the error event.
+const (
+	ErrCodeInvalidEnvelope = "invalid_envelope"
+	ErrCodeUnknownType     = "unknown_type"
+	ErrCodeInvalidPayload  = "invalid_payload"
+	ErrCodeUnsupported     = "unsupported"
+	ErrCodeRoomNotFound    = "room_not_found"
+	ErrCodeNotSubscribed   = "not_subscribed"
+	ErrCodeSubscribed      = "already_subscribed"
+	ErrCodeMessageNotFound = "message_not_found"
+	ErrCodeForbidden       = "forbidden"
+	ErrCodeInternal        = "internal_error"
+)
+
+// Close codes used when the server drops a connection.
+const (
+	// CloseSlowConsumer means the client did not read fast enough and its send
+	// queue overflowed. Events were lost; the client should reconnect and
+	// resubscribe with lastSeq to resync.
+	CloseSlowConsumer = 4008
+	// CloseRemovedFromRoom means the user left, was kicked or was banned from
+	// the room of a per-room connection. The client should not reconnect to
+	// that room. On /ws the connection stays open and gets room.removed.
+	CloseRemovedFromRoom = 4003
+)
+
+// Envelope wraps every frame exchanged over the socket. For commands ID is
+// chosen by the client; the server echoes it on the events the command causes
+// so the client can correlate them. Room is the slug of the room an event
+// belongs to or a command targets; on a per-room connection it may be omitted
+// from commands. Seq is set on events delivered from the room stream and grows
+// by exactly one per event, so a jump means the client missed something and
+// should resubscribe with lastSeq. Ephemeral events like typing indicators
+// carry no Seq and are never replayed.
+type Envelope struct {
+	Type string          `json:"type"`
+	ID   string          `json:"id,omitempty"`
+	Room string          `json:"room,omitempty"`
+	Seq  uint64          `json:"seq,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type SubscribeCommand struct {
+	LastSeq       *uint64   `json:"lastSeq,omitempty"`
+	LastMessageID uuid.UUID `json:"lastMessageId,omitempty"`
+}
+
+// SendMessageCommand posts a message to the room. With ThreadID set the
+// message is posted in the thread under that root message instead of the
+// timeline. With ReplyToID set it quotes that message of the same room and
+// thread. AttachmentIDs are files the sender uploaded to the room before.
+type SendMessageCommand struct {
+	Content       string      `json:"content"`
+	ThreadID      *uuid.UUID  `json:"threadId,omitempty"`
+	ReplyToID     *uuid.UUID  `json:"replyToId,omitempty"`
+	AttachmentIDs []uuid.UUID `json:"attachmentIds,omitempty"`
+}
+
+type EditMessageCommand struct {
+	MessageID uuid.UUID `json:"messageId"`
+	Content   string    `json:"content"`
+}
+
+type DeleteMessageCommand struct {
+	MessageID uuid.UUID `json:"messageId"`
+}
+
+// AckCommand advances the read marker of the room to MessageID.
+type AckCommand struct {
+	MessageID uuid.UUID `json:"messageId"`
+}
+
+// SetPresenceCommand lets a client report that its user went idle or came
+// back. Status is "online" or "idle".
+type SetPresenceCommand struct {
+	Status model.PresenceStatus `json:"status"`
+}
+
+type HelloEvent struct {
+	Version int `json:"version"`
+}
+
+type SubscribedEvent struct {
+	RoomID uuid.UUID `json:"roomId"`
+	Seq    uint64    `json:"seq"`
+}
+
+type ResumedEvent struct {
+	Replayed int    `json:"replayed"`
+	Seq      uint64 `json:"seq"`
+}
+
+// ResyncEvent tells the client that the missed events can't be replayed and
+// that it should refetch the history over REST instead.
+type ResyncEvent struct {
+	Reason string `json:"reason"`
+}
+
+// TypingEvent is broadcast to the other members of a room. Clients should
+// drop a typing indicator on their own after ExpiresIn milliseconds without a
+// new typing.started.
+type TypingEvent struct {
+	UserID    uuid.UUID `json:"userId"`
+	Username  string    `json:"username"`
+	ExpiresIn int64     `json:"expiresIn,omitempty"`
+}
+
+// ReactionEvent tells that UserID added or removed a reaction. Count is the
+// number of reactions with the emoji on the message afterwards.
+type ReactionEvent struct {
+	MessageID uuid.UUID `json:"messageId"`
+	UserID    uuid.UUID `json:"userId"`
+	Emoji     string    `json:"emoji"`
+	Count     int       `json:"count"`
+}
+
+// ThreadReplyEvent carries a new thread reply along with the updated summary
+// of its thread. It is sent to the room; followers of the thread also get it
+// as thread.activity on their user stream.
+type ThreadReplyEvent struct {
+	Message      model.Message         `json:"message"`
+	ReplyCount   int                   `json:"replyCount"`
+	LastReplyAt  time.Time             `json:"lastReplyAt"`
+	Participants []model.MessageSender `json:"participants"`
+}
+
+// PinRemovedEvent tells that a message was unpinned by UserID. pin.added
+// carries the whole model.RoomPin instead.
+type PinRemovedEvent struct {
+	MessageID uuid.UUID `json:"messageId"`
+	UserID    uuid.UUID `json:"userId"`
+}
+
+// MemberRoleEvent tells that the role of UserID in the room was changed by
+// ChangedBy.
+type MemberRoleEvent struct {
+	UserID    uuid.UUID      `json:"userId"`
+	Role      model.RoomRole `json:"role"`
+	ChangedBy uuid.UUID      `json:"changedBy"`
+}
+
+// Reasons a member.removed event is sent for.
+const (
+	RemovedLeft   = "left"
+	RemovedKicked = "kicked"
+	RemovedBanned = "banned"
+)
+
+// MemberRemovedEvent tells that UserID is no longer a member of the room.
+// Reason is one of "left", "kicked" or "banned"; By is the moderator who
+// removed them. Bans carry the reason given and, unless permanent, when they
+// expire.
+type MemberRemovedEvent struct {
+	UserID       uuid.UUID  `json:"userId"`
+	Reason       string     `json:"reason"`
+	By           *uuid.UUID `json:"by,omitempty"`
+	BanReason    string     `json:"banReason,omitempty"`
+	BanExpiresAt *time.Time `json:"banExpiresAt,omitempty"`
+}
+
+// RoomRemovedEvent follows the member.removed event of the connection's own
+// user on /ws. The subscription to the room has been dropped; the client
+// should not resubscribe.
+type RoomRemovedEvent struct {
+	RoomID uuid.UUID `json:"roomId"`
+}
+
+type ErrorEvent struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// CommandError rejects a single command without closing the connection.
+type CommandError struct {
+	Code    string
+	Message string
+}
+
+func (e *CommandError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func newCommandError(code, message string) *CommandError {
+	return &CommandError{Code: code, Message: message}
+}
+
+func encodeEnvelope(eventType, room, id string, data any) ([]byte, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(Envelope{
+		Type: eventType,
+		ID:   id,
+		Room: room,
+		Data: raw,
+	})
+}
+
+func decodeData(env Envelope, dst any) error {
+	if len(env.Data) == 0 {
+		return newCommandError(ErrCodeInvalidPayload, "missing data")
+	}
+	if err := json.Unmarshal(env.Data, dst); err != nil {
+		return newCommandError(ErrCodeInvalidPayload, "malformed data")
+	}
+	return nil
+}
+
+func asCommandError(err error) (*CommandError, bool) {
+	var cmdErr *CommandError
+	ok := errors.As(err, &cmdErr)
+	return cmdErr, ok
+}
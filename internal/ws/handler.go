@@ -0,0 +1,33 @@
+package ws
+
+import (
+	"log/slog"
+	"lunar/internal/httputil"
+	"net/http"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service}
+}
+
+// Connect godoc
+//
+//	@Summary		Connect to the multiplexed websocket
+//	@Tags			ws
+//	@Security		WebSocketQueryAuth
+//	@Description	Open a single websocket for all of the user's rooms. Send a "subscribe" command with the room slug to start receiving a room's events and "unsubscribe" to stop.
+//	@Schemes		ws
+//	@Failure		401	{object}	httputil.ErrorResponse
+//	@Failure		500	{object}	httputil.ErrorResponse
+//	@Router			/ws [get]
+func (h *Handler) Connect(w http.ResponseWriter, r *http.Request) {
+	user := httputil.UserFromRequest(r)
+
+	if err := h.service.HandleConnection(w, r, user.ID); err != nil {
+		slog.Error("websocket error", "err", err)
+	}
+}
@@ -0,0 +1,247 @@
+package ws
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sharedReadBlock bounds each blocking XREAD of a shared hub. A topic
+// subscribed while the read blocks is picked up by the next one, so its first
+// events may be late by up to this long; none are lost.
+const sharedReadBlock = time.Second
+
+// hub shares stream readers among all the subscriptions on this instance. A
+// topic is active while it has at least one subscription. By default every
+// active topic has a reader of its own; a shared hub reads all of its topics
+// with one XREAD so it holds a single connection however many topics it has.
+type hub struct {
+	stream *EventStream
+	shared bool
+	// wake starts the reader of a shared hub that is idle for lack of topics.
+	wake chan struct{}
+
+	mu     sync.Mutex
+	topics map[uuid.UUID]*hubTopic
+}
+
+type hubTopic struct {
+	mu sync.Mutex
+	// lastID is the stream ID of the last event the reader fanned out.
+	lastID string
+	subs   map[*subscription]struct{}
+	// cancel stops the reader of the topic; it is nil in a shared hub.
+	cancel context.CancelFunc
+}
+
+func newHub(stream *EventStream) *hub {
+	return &hub{
+		stream: stream,
+		topics: make(map[uuid.UUID]*hubTopic),
+	}
+}
+
+// newSharedHub creates a hub that reads all of its topics on one connection
+// until ctx is done. It suits topics such as users, which are many and each
+// carry few events.
+func newSharedHub(ctx context.Context, stream *EventStream) *hub {
+	h := &hub{
+		stream: stream,
+		shared: true,
+		wake:   make(chan struct{}, 1),
+		topics: make(map[uuid.UUID]*hubTopic),
+	}
+	go h.runShared(ctx)
+	return h
+}
+
+// subscribe adds sub to its topic. The subscription has already received
+// everything up to fromID; if the topic reader is further along, the events in
+// between are read from the stream so nothing is skipped.
+func (h *hub) subscribe(ctx context.Context, sub *subscription, fromID string) error {
+	id := sub.topic
+
+	h.mu.Lock()
+	topic, ok := h.topics[id]
+	if !ok {
+		topic = &hubTopic{
+			lastID: fromID,
+			subs:   make(map[*subscription]struct{}),
+		}
+		h.topics[id] = topic
+		if h.shared {
+			select {
+			case h.wake <- struct{}{}:
+			default:
+			}
+		} else {
+			readerCtx, cancel := context.WithCancel(context.Background())
+			topic.cancel = cancel
+			go h.run(readerCtx, id, topic)
+		}
+	}
+	topic.mu.Lock()
+	h.mu.Unlock()
+	defer topic.mu.Unlock()
+
+	sub.lastID = fromID
+	if compareStreamIDs(fromID, topic.lastID) < 0 {
+		events, err := h.stream.rangeAfter(ctx, id, fromID, topic.lastID)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := sub.deliver(event); err != nil {
+				return err
+			}
+		}
+		sub.lastID = topic.lastID
+	}
+
+	topic.subs[sub] = struct{}{}
+	return nil
+}
+
+// unsubscribe removes sub from its topic and stops the topic reader once
+// nobody on this instance listens to the topic anymore.
+func (h *hub) unsubscribe(sub *subscription) {
+	id := sub.topic
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	topic, ok := h.topics[id]
+	if !ok {
+		return
+	}
+
+	topic.mu.Lock()
+	delete(topic.subs, sub)
+	empty := len(topic.subs) == 0
+	topic.mu.Unlock()
+
+	if empty {
+		if topic.cancel != nil {
+			topic.cancel()
+		}
+		delete(h.topics, id)
+	}
+}
+
+func (h *hub) run(ctx context.Context, id uuid.UUID, topic *hubTopic) {
+	for ctx.Err() == nil {
+		topic.mu.Lock()
+		lastID := topic.lastID
+		topic.mu.Unlock()
+
+		events, err := h.stream.read(ctx, id, lastID, streamReadBlock)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("read event stream", "topic", id, "err", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		topic.mu.Lock()
+		dropped := topic.fanOut(events)
+		topic.mu.Unlock()
+
+		if dropped {
+			h.removeIfEmpty(id, topic)
+		}
+	}
+}
+
+// removeIfEmpty stops the reader of a topic whose subscriptions were all
+// dropped on delivery.
+func (h *hub) removeIfEmpty(id uuid.UUID, topic *hubTopic) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.topics[id] != topic {
+		return
+	}
+	topic.mu.Lock()
+	empty := len(topic.subs) == 0
+	topic.mu.Unlock()
+
+	if empty {
+		topic.cancel()
+		delete(h.topics, id)
+	}
+}
+
+func (h *hub) runShared(ctx context.Context) {
+	for ctx.Err() == nil {
+		afterIDs := h.lastIDs()
+		if len(afterIDs) == 0 {
+			select {
+			case <-h.wake:
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		events, err := h.stream.readMany(ctx, afterIDs, sharedReadBlock)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("read event streams", "topics", len(afterIDs), "err", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		h.mu.Lock()
+		for id, batch := range events {
+			if topic, ok := h.topics[id]; ok {
+				topic.mu.Lock()
+				if topic.fanOut(batch) && len(topic.subs) == 0 {
+					delete(h.topics, id)
+				}
+				topic.mu.Unlock()
+			}
+		}
+		h.mu.Unlock()
+	}
+}
+
+// lastIDs returns where the reader of a shared hub is in each topic.
+func (h *hub) lastIDs() map[uuid.UUID]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	lastIDs := make(map[uuid.UUID]string, len(h.topics))
+	for id, topic := range h.topics {
+		topic.mu.Lock()
+		lastIDs[id] = topic.lastID
+		topic.mu.Unlock()
+	}
+	return lastIDs
+}
+
+// fanOut delivers events to the subscriptions of the topic and drops those
+// that fail, reporting whether it dropped any. Events the topic is already
+// past, which a shared reader may bring after the topic was subscribed again,
+// are skipped.
+func (topic *hubTopic) fanOut(events []streamEvent) bool {
+	dropped := false
+	for _, event := range events {
+		if compareStreamIDs(event.ID, topic.lastID) <= 0 {
+			continue
+		}
+		topic.lastID = event.ID
+		for sub := range topic.subs {
+			if err := sub.deliver(event); err != nil {
+				delete(topic.subs, sub)
+				dropped = true
+			}
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,56 @@
+package ws
+
+import (
+	"context"
+	"lunar/internal/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSharedHubReadsManyTopicsOnOneConnection(t *testing.T) {
+	stream := newTestStream(t, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	h := newSharedHub(ctx, stream)
+
+	// The test stream has a single reader connection, so a reader per topic
+	// would leave all but one of them waiting for the pool.
+	var clients []*client
+	for range 20 {
+		clientCtx, clientCancel := context.WithCancelCause(context.Background())
+		t.Cleanup(func() { clientCancel(nil) })
+		c := newClient(clientCtx, clientCancel, nil, model.User{ID: uuid.New()}, 4)
+
+		sub := newUserSubscription(c)
+		if err := h.subscribe(context.Background(), sub, "0-0"); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { h.unsubscribe(sub) })
+		clients = append(clients, c)
+	}
+
+	for _, c := range clients {
+		payload, err := encodeEnvelope(EventPresence, "", "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stream.publish(context.Background(), c.user.ID, payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	timeout := time.After(3 * sharedReadBlock)
+	for i, c := range clients {
+		select {
+		case <-c.send:
+		case <-timeout:
+			t.Fatalf("client %d got no event", i)
+		}
+	}
+
+	if conns := stream.reader.PoolStats().TotalConns; conns > 1 {
+		t.Errorf("reader holds %d connections, want 1", conns)
+	}
+}
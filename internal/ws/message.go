@@ -0,0 +1,91 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+)
+
+func (s *Service) editMessage(ctx context.Context, sub *subscription, env Envelope) error {
+	var cmd EditMessageCommand
+	if err := decodeData(env, &cmd); err != nil {
+		return err
+	}
+
+	room, message, err := s.messages.EditMessage(ctx, sub.client.user.ID, sub.room.Slug, cmd.MessageID, cmd.Content)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrMessageNotFound):
+			return newCommandError(ErrCodeMessageNotFound, "message not found in this room")
+		case errors.Is(err, model.ErrNotMessageAuthor):
+			return newCommandError(ErrCodeForbidden, "only the author can edit a message")
+		case errors.Is(err, model.ErrMessageDeleted):
+			return newCommandError(ErrCodeMessageNotFound, "message has been deleted")
+		case errors.Is(err, model.ErrInvalidMessageContent):
+			return newCommandError(ErrCodeInvalidPayload, err.Error())
+		}
+		return err
+	}
+
+	return s.publish(ctx, room, EventMessageUpdated, env.ID, message)
+}
+
+func (s *Service) deleteMessage(ctx context.Context, sub *subscription, env Envelope) error {
+	var cmd DeleteMessageCommand
+	if err := decodeData(env, &cmd); err != nil {
+		return err
+	}
+
+	room, message, err := s.messages.DeleteMessage(ctx, sub.client.user.ID, sub.room.Slug, cmd.MessageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrMessageNotFound), errors.Is(err, model.ErrMessageDeleted):
+			return newCommandError(ErrCodeMessageNotFound, "message not found in this room")
+		case errors.Is(err, model.ErrNotMessageAuthor):
+			return newCommandError(ErrCodeForbidden, "only the author or a higher-ranked moderator can delete a message")
+		}
+		return err
+	}
+
+	return s.publish(ctx, room, EventMessageDeleted, env.ID, message)
+}
+
+// publishThreadReply broadcasts a new thread reply to the room and notifies
+// the followers of the thread on their user streams.
+func (s *Service) publishThreadReply(ctx context.Context, room model.Room, id string, reply model.Message) error {
+	summary, followerIDs, err := s.messages.RecordThreadReply(ctx, reply)
+	if err != nil {
+		return err
+	}
+
+	event := ThreadReplyEvent{
+		Message:      reply,
+		ReplyCount:   summary.ReplyCount,
+		LastReplyAt:  summary.LastReplyAt,
+		Participants: summary.Participants,
+	}
+	if err := s.publish(ctx, room, EventThreadReply, id, event); err != nil {
+		return err
+	}
+
+	payload, err := encodeEnvelope(EventThreadActivity, room.Slug, "", event)
+	if err != nil {
+		return err
+	}
+	for _, userID := range followerIDs {
+		if err := s.userStream.publishEphemeral(ctx, userID, reply.Sender.ID, payload); err != nil {
+			slog.Warn("Error publishing thread activity", "user", userID, "err", err)
+		}
+	}
+	return nil
+}
+
+// PublishRoomEvent broadcasts a change made outside the socket, such as over
+// REST, to the room.
+func (s *Service) PublishRoomEvent(ctx context.Context, room model.Room, eventType string, data any) {
+	if err := s.publish(ctx, room, eventType, "", data); err != nil {
+		slog.Warn("Error publishing room event", "type", eventType, "room", room.ID, "err", err)
+	}
+}
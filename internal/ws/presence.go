@@ -0,0 +1,145 @@
+package ws
+
+import (
+	"context"
+	"log/slog"
+	"lunar/internal/model"
+	"time"
+)
+
+// connectPresence registers the connection as a presence session and
+// subscribes it to events addressed to its user, such as friends' presence.
+func (s *Service) connectPresence(ctx context.Context, c *client) error {
+	fromID, err := s.userStream.lastID(ctx, c.user.ID)
+	if err != nil {
+		return err
+	}
+
+	sub := newUserSubscription(c)
+	if err := s.userHub.subscribe(ctx, sub, fromID); err != nil {
+		s.userHub.unsubscribe(sub)
+		return err
+	}
+	if !c.setUserSubscription(sub) {
+		s.userHub.unsubscribe(sub)
+		return nil
+	}
+
+	return s.updatePresence(ctx, c, model.PresenceOnline)
+}
+
+// disconnectPresence runs when the connection is already gone.
+func (s *Service) disconnectPresence(c *client) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeWait)
+	defer cancel()
+
+	presence, changed, err := s.presence.Disconnect(ctx, c.user.ID, c.sessionID)
+	if err != nil {
+		slog.Warn("Error removing presence session", "user", c.user.ID, "err", err)
+		return
+	}
+	if changed {
+		s.broadcastPresence(ctx, presence)
+	}
+}
+
+func (s *Service) updatePresence(ctx context.Context, c *client, status model.PresenceStatus) error {
+	c.setPresenceStatus(status)
+
+	presence, changed, err := s.presence.SetStatus(ctx, c.user.ID, c.sessionID, status)
+	if err != nil {
+		return err
+	}
+	if changed {
+		s.broadcastPresence(ctx, presence)
+	}
+	return nil
+}
+
+// keepPresence sends the heartbeats that keep the session from expiring.
+func (s *Service) keepPresence(ctx context.Context, c *client) {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.updatePresence(ctx, c, c.presenceStatus()); err != nil && ctx.Err() == nil {
+				slog.Warn("Error refreshing presence session", "user", c.user.ID, "err", err)
+			}
+		}
+	}
+}
+
+func (s *Service) setPresence(ctx context.Context, c *client, env Envelope) error {
+	var cmd SetPresenceCommand
+	if err := decodeData(env, &cmd); err != nil {
+		return err
+	}
+	if cmd.Status != model.PresenceOnline && cmd.Status != model.PresenceIdle {
+		return newCommandError(ErrCodeInvalidPayload, "status must be online or idle")
+	}
+
+	return s.updatePresence(ctx, c, cmd.Status)
+}
+
+// broadcastPresence tells the rooms the user is in and the user's friends
+// about a presence change. Failures are only logged; clients converge on the
+// next change or through the REST endpoints.
+func (s *Service) broadcastPresence(ctx context.Context, presence model.Presence) {
+	rooms, friendIDs, err := s.presence.Watchers(ctx, presence.UserID)
+	if err != nil {
+		slog.Warn("Error listing presence watchers", "user", presence.UserID, "err", err)
+		return
+	}
+
+	for _, room := range rooms {
+		payload, err := encodeEnvelope(EventPresence, room.Slug, "", presence)
+		if err != nil {
+			slog.Warn("Error marshaling event", "type", EventPresence, "err", err)
+			return
+		}
+		if err := s.stream.publishEphemeral(ctx, room.ID, presence.UserID, payload); err != nil {
+			slog.Warn("Error publishing presence", "room", room.ID, "err", err)
+		}
+	}
+
+	payload, err := encodeEnvelope(EventPresence, "", "", presence)
+	if err != nil {
+		slog.Warn("Error marshaling event", "type", EventPresence, "err", err)
+		return
+	}
+	for _, friendID := range friendIDs {
+		if err := s.userStream.publishEphemeral(ctx, friendID, presence.UserID, payload); err != nil {
+			slog.Warn("Error publishing presence", "user", friendID, "err", err)
+		}
+	}
+}
+
+// SweepPresence periodically expires the presence sessions of connections
+// that vanished without disconnecting, e.g. because their instance crashed,
+// and broadcasts the resulting changes. It returns when ctx is done.
+func (s *Service) SweepPresence(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changes, err := s.presence.ReapExpired(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Error("sweep presence", "err", err)
+				}
+				continue
+			}
+			for _, presence := range changes {
+				s.broadcastPresence(ctx, presence)
+			}
+		}
+	}
+}
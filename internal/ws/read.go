@@ -0,0 +1,76 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+func (s *Service) ack(ctx context.Context, sub *subscription, env Envelope) error {
+	var cmd AckCommand
+	if err := decodeData(env, &cmd); err != nil {
+		return err
+	}
+
+	user := sub.client.user
+	room, advanced, err := s.rooms.MarkRead(ctx, user.ID, sub.room.Slug, cmd.MessageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMessageNotFound) {
+			return newCommandError(ErrCodeInvalidPayload, "unknown message")
+		}
+		return accessError(err)
+	}
+
+	if advanced {
+		s.PublishReadState(ctx, user.ID, room)
+	}
+	return nil
+}
+
+// PublishReadState sends the user's new read state in a room to all of the
+// user's connections, so a room read on one device stops showing as unread on
+// the others. Unless the user opted out, the other members get a read receipt.
+func (s *Service) PublishReadState(ctx context.Context, userID uuid.UUID, room model.Room) {
+	payload, err := encodeEnvelope(EventReadUpdated, room.Slug, "", room.ReadState)
+	if err != nil {
+		slog.Warn("Error marshaling event", "type", EventReadUpdated, "err", err)
+		return
+	}
+
+	// The event is meant for the user's own connections, so it must not be
+	// attributed to the user or they would all skip it.
+	if err := s.userStream.publishEphemeral(ctx, userID, uuid.Nil, payload); err != nil {
+		slog.Warn("Error publishing read state", "user", userID, "err", err)
+	}
+
+	if room.ReadState == nil || room.ReadState.LastReadMessageID == nil {
+		return
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		slog.Warn("Error loading user", "user", userID, "err", err)
+		return
+	}
+	if !user.ShareReadReceipts {
+		return
+	}
+
+	payload, err = encodeEnvelope(EventReceipt, room.Slug, "", model.ReadReceipt{
+		UserID:    user.ID,
+		Username:  user.Username,
+		AvatarURL: user.AvatarURL,
+		MessageID: *room.ReadState.LastReadMessageID,
+	})
+	if err != nil {
+		slog.Warn("Error marshaling event", "type", EventReceipt, "err", err)
+		return
+	}
+	if err := s.stream.publishEphemeral(ctx, room.ID, userID, payload); err != nil {
+		slog.Warn("Error publishing read receipt", "room", room.ID, "err", err)
+	}
+}
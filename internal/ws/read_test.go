@@ -0,0 +1,54 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"lunar/internal/access"
+	"lunar/internal/model"
+	"lunar/internal/repository"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type fakeRooms struct {
+	err error
+}
+
+func (r fakeRooms) GetMemberRoom(context.Context, uuid.UUID, string) (model.Room, error) {
+	return model.Room{}, r.err
+}
+
+func (r fakeRooms) MarkRead(context.Context, uuid.UUID, string, uuid.UUID) (model.Room, bool, error) {
+	return model.Room{}, false, r.err
+}
+
+func TestAckErrors(t *testing.T) {
+	internal := errors.New("connection refused")
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{repository.ErrMessageNotFound, ErrCodeInvalidPayload},
+		{access.ErrRoomNotFound, ErrCodeRoomNotFound},
+		{access.ErrNotRoomMember, ErrCodeForbidden},
+		{internal, ""},
+	}
+	for _, tt := range tests {
+		s := &Service{rooms: fakeRooms{err: tt.err}}
+		sub, _ := newTestSubscription(t, 1)
+		env := Envelope{Type: CommandAck, Data: []byte(`{"messageId":"` + uuid.NewString() + `"}`)}
+
+		err := s.ack(context.Background(), sub, env)
+		cmdErr, ok := asCommandError(err)
+		if tt.code == "" {
+			if ok || !errors.Is(err, internal) {
+				t.Errorf("ack with %v = %v, want the error passed through", tt.err, err)
+			}
+			continue
+		}
+		if !ok || cmdErr.Code != tt.code {
+			t.Errorf("ack with %v = %v, want command error %q", tt.err, err, tt.code)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"lunar/internal/model"
+	"lunar/internal/pagination"
+	"lunar/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// replayLimit caps how many missed events are replayed on resume. Clients
+// that fell further behind are asked to resync over REST.
+const replayLimit = 200
+
+var errReplayUnavailable = errors.New("replay unavailable")
+
+// Resume describes where a reconnecting client left off in a room. LastSeq is
+// preferred; LastMessageID is used when the stream no longer holds the missed
+// events.
+type Resume struct {
+	LastSeq       *uint64
+	LastMessageID uuid.UUID
+}
+
+// resume replays everything the subscription missed up to head. It must run
+// before the subscription starts receiving live events from head on.
+func (s *Service) resume(ctx context.Context, sub *subscription, resume Resume, head uint64) error {
+	if resume.LastSeq != nil {
+		replayed, err := s.replayStream(ctx, sub, *resume.LastSeq, head)
+		if err == nil {
+			return sub.client.emit(EventResumed, sub.room.Slug, "", ResumedEvent{Replayed: replayed, Seq: head})
+		}
+		if !errors.Is(err, errReplayUnavailable) {
+			return err
+		}
+	}
+
+	if resume.LastMessageID != uuid.Nil {
+		return s.replayMessages(ctx, sub, resume.LastMessageID, head)
+	}
+
+	if resume.LastSeq != nil {
+		return sub.client.emit(EventResync, sub.room.Slug, "", ResyncEvent{Reason: "missed events are no longer available"})
+	}
+	return nil
+}
+
+func (s *Service) replayStream(ctx context.Context, sub *subscription, lastSeq, head uint64) (int, error) {
+	if lastSeq > head || head-lastSeq > replayLimit {
+		return 0, errReplayUnavailable
+	}
+	if lastSeq == head {
+		return 0, nil
+	}
+
+	oldest, err := s.stream.oldest(ctx, sub.room.ID)
+	if err != nil {
+		return 0, err
+	}
+	if oldest == 0 || oldest > lastSeq+1 {
+		return 0, errReplayUnavailable
+	}
+
+	events, err := s.stream.rangeAfter(ctx, sub.room.ID, streamID(lastSeq), streamID(head))
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, event := range events {
+		if event.Ephemeral {
+			continue
+		}
+		if err := sub.client.enqueue(event.Payload); err != nil {
+			return 0, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// replayMessages replays from the database. Live delivery starts at a head
+// read before the query, so messages published meanwhile may arrive twice;
+// the watermark lets the subscription drop those duplicates.
+func (s *Service) replayMessages(ctx context.Context, sub *subscription, lastMessageID uuid.UUID, head uint64) error {
+	c := sub.client
+	room := sub.room
+
+	last, err := s.messageRepo.GetMessage(ctx, lastMessageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMessageNotFound) {
+			return c.emit(EventResync, room.Slug, "", ResyncEvent{Reason: "unknown message"})
+		}
+		return err
+	}
+	if last.RoomID != room.ID {
+		return c.emit(EventResync, room.Slug, "", ResyncEvent{Reason: "unknown message"})
+	}
+
+	cursor := pagination.Cursor{ID: last.ID, CreatedAt: last.CreatedAt}
+
+	messages, err := s.messageRepo.ListMessagesAfter(ctx, room.ID, replayLimit+1, cursor)
+	if err != nil {
+		return err
+	}
+	if len(messages) > replayLimit {
+		return c.emit(EventResync, room.Slug, "", ResyncEvent{Reason: "too many missed messages"})
+	}
+
+	for _, message := range messages {
+		if err := c.emit(EventMessageCreated, room.Slug, "", message); err != nil {
+			return err
+		}
+		cursor = pagination.Cursor{ID: message.ID, CreatedAt: message.CreatedAt}
+	}
+	sub.watermark = &cursor
+
+	return c.emit(EventResumed, room.Slug, "", ResumedEvent{Replayed: len(messages), Seq: head})
+}
+
+func afterCursor(message model.Message, cursor pagination.Cursor) bool {
+	if !message.CreatedAt.Equal(cursor.CreatedAt) {
+		return message.CreatedAt.After(cursor.CreatedAt)
+	}
+	return bytes.Compare(message.ID[:], cursor.ID[:]) > 0
+}
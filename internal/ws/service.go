@@ -3,30 +3,99 @@ package ws
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"lunar/internal/access"
 	"lunar/internal/model"
+	"lunar/internal/presence"
 	"lunar/internal/repository"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
-	"github.com/redis/go-redis/v9"
 )
 
+const (
+	// streamReadBlock bounds each blocking XREAD so hub readers notice when
+	// their room has no subscribers left.
+	streamReadBlock = 5 * time.Second
+
+	writeWait  = 10 * time.Second
+	pingPeriod = 30 * time.Second
+	// pongWait must exceed pingPeriod; a client that answers no ping in time
+	// is considered gone.
+	pongWait = 2 * pingPeriod
+	// maxMessageSize fits the largest command, a message.send with 5000
+	// bytes of content, with room to spare.
+	maxMessageSize = 16 << 10
+)
+
+// Rooms is the part of the room service the socket relies on.
+type Rooms interface {
+	// GetMemberRoom resolves a room by slug if the user is a member of it.
+	GetMemberRoom(ctx context.Context, userID uuid.UUID, roomSlug string) (model.Room, error)
+	// MarkRead advances the user's read marker. The returned room carries the
+	// new read state.
+	MarkRead(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID) (model.Room, bool, error)
+}
+
+// Messages is the part of the message service the socket relies on.
+type Messages interface {
+	// EditMessage replaces the content of a message sent by the user and
+	// returns the room it belongs to.
+	EditMessage(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID, content string) (model.Room, model.Message, error)
+	// DeleteMessage leaves a tombstone in place of a message deleted by its
+	// author or a moderator.
+	DeleteMessage(ctx context.Context, userID uuid.UUID, roomSlug string, messageID uuid.UUID) (model.Room, model.Message, error)
+	// RecordThreadReply updates the followers of the thread a new reply was
+	// posted in and returns its summary and the followers to notify.
+	RecordThreadReply(ctx context.Context, reply model.Message) (model.ThreadSummary, []uuid.UUID, error)
+	// AttachFiles attaches uploads of the sender to a message about to be
+	// sent.
+	AttachFiles(ctx context.Context, msg *model.Message, attachmentIDs []uuid.UUID) error
+}
+
 type Service struct {
-	rdb         *redis.Client
-	upgrader    *websocket.Upgrader
-	userRepo    repository.UserRepository
-	messageRepo repository.MessageRepository
+	stream        *EventStream
+	userStream    *EventStream
+	hub           *hub
+	userHub       *hub
+	upgrader      *websocket.Upgrader
+	rooms         Rooms
+	messages      Messages
+	presence      *presence.Service
+	userRepo      repository.UserRepository
+	messageRepo   repository.MessageRepository
+	sendQueueSize int
 }
 
-func NewService(rdb *redis.Client, userRepo repository.UserRepository, messageRepo repository.MessageRepository, allowedOrigins []string) *Service {
+// NewService creates the WebSocket service. stream carries room events and
+// userStream events addressed to all connections of a user. User streams are
+// read together on a single connection, as every online user has one.
+func NewService(
+	stream *EventStream,
+	userStream *EventStream,
+	rooms Rooms,
+	messages Messages,
+	presence *presence.Service,
+	userRepo repository.UserRepository,
+	messageRepo repository.MessageRepository,
+	sendQueueSize int,
+	allowedOrigins []string,
+) *Service {
 	return &Service{
-		rdb:         rdb,
-		userRepo:    userRepo,
-		messageRepo: messageRepo,
+		stream:        stream,
+		userStream:    userStream,
+		hub:           newHub(stream),
+		userHub:       newSharedHub(context.Background(), userStream),
+		rooms:         rooms,
+		messages:      messages,
+		presence:      presence,
+		userRepo:      userRepo,
+		messageRepo:   messageRepo,
+		sendQueueSize: sendQueueSize,
 		upgrader: &websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -44,11 +113,34 @@ func NewService(rdb *redis.Client, userRepo repository.UserRepository, messageRe
 	}
 }
 
+// HandleConnection serves the multiplexed socket. The connection starts with
+// no rooms; the client subscribes and unsubscribes to rooms by slug.
+func (s *Service) HandleConnection(w http.ResponseWriter, r *http.Request, userID uuid.UUID) error {
+	return s.serve(w, r, userID, nil)
+}
+
+// HandleWebSocket serves the per-room socket. The connection is subscribed to
+// room for its whole lifetime.
 func (s *Service) HandleWebSocket(
 	w http.ResponseWriter,
 	r *http.Request,
 	room model.Room,
 	userID uuid.UUID,
+	resume Resume,
+) error {
+	return s.serve(w, r, userID, func(ctx context.Context, c *client) error {
+		c.defaultRoom = room.Slug
+		return s.subscribeRoom(ctx, c, room, "", resume)
+	})
+}
+
+// serve upgrades the connection and runs it until either side gives up. An
+// optional setup runs after the hello event and before any command is read.
+func (s *Service) serve(
+	w http.ResponseWriter,
+	r *http.Request,
+	userID uuid.UUID,
+	setup func(ctx context.Context, c *client) error,
 ) error {
 	user, err := s.userRepo.GetByID(r.Context(), userID)
 	if err != nil {
@@ -61,17 +153,33 @@ func (s *Service) HandleWebSocket(
 	}
 	defer conn.Close()
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
 
-	sub := s.rdb.Subscribe(ctx, room.ID.String())
-	defer sub.Close()
+	c := newClient(ctx, cancel, conn, user, s.sendQueueSize)
+	defer s.unsubscribeAll(c)
 
-	inErr := make(chan error, 1)
 	outErr := make(chan error, 1)
+	go s.handleOutgoing(ctx, c, outErr)
+
+	if err := c.emit(EventHello, "", "", HelloEvent{Version: ProtocolVersion}); err != nil {
+		return err
+	}
+
+	defer s.disconnectPresence(c)
+	if err := s.connectPresence(ctx, c); err != nil {
+		return err
+	}
+	go s.keepPresence(ctx, c)
 
-	go s.handleIncoming(ctx, conn, room.ID, user, inErr)
-	go s.handleOutgoing(ctx, conn, sub.Channel(), outErr)
+	if setup != nil {
+		if err := setup(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	inErr := make(chan error, 1)
+	go s.handleIncoming(ctx, c, inErr)
 
 	select {
 	case err := <-inErr:
@@ -79,74 +187,295 @@ func (s *Service) HandleWebSocket(
 	case err := <-outErr:
 		return err
 	case <-ctx.Done():
-		return ctx.Err()
+		err := context.Cause(ctx)
+		switch {
+		case errors.Is(err, errSendQueueFull):
+			if closeErr := c.closeWith(CloseSlowConsumer, "send queue overflow, resync"); closeErr != nil {
+				slog.Warn("Error closing slow websocket", "err", closeErr)
+			}
+		case errors.Is(err, errRemovedFromRoom):
+			if closeErr := c.closeWith(CloseRemovedFromRoom, "removed from room"); closeErr != nil {
+				slog.Warn("Error closing removed websocket", "err", closeErr)
+			}
+			return nil
+		}
+		return err
+	}
+}
+
+// subscribeRoom announces the subscription, replays what the client missed
+// and hands the subscription to the hub for live events.
+func (s *Service) subscribeRoom(ctx context.Context, c *client, room model.Room, id string, resume Resume) error {
+	head, err := s.stream.head(ctx, room.ID)
+	if err != nil {
+		return err
+	}
+
+	sub := newSubscription(c, room)
+
+	if err := c.emit(EventSubscribed, room.Slug, id, SubscribedEvent{RoomID: room.ID, Seq: head}); err != nil {
+		return err
+	}
+
+	if err := s.resume(ctx, sub, resume, head); err != nil {
+		return err
+	}
+
+	err = s.hub.subscribe(ctx, sub, streamID(head))
+	if err != nil || !c.addSubscription(sub) {
+		s.hub.unsubscribe(sub)
+	}
+	return err
+}
+
+func (s *Service) subscribe(ctx context.Context, c *client, env Envelope) error {
+	if env.Room == "" {
+		return newCommandError(ErrCodeInvalidPayload, "missing room")
+	}
+	if _, ok := c.subscription(env.Room); ok {
+		return newCommandError(ErrCodeSubscribed, "already subscribed to this room")
+	}
+
+	var cmd SubscribeCommand
+	if len(env.Data) > 0 {
+		if err := decodeData(env, &cmd); err != nil {
+			return err
+		}
+	}
+
+	room, err := s.rooms.GetMemberRoom(ctx, c.user.ID, env.Room)
+	if err != nil {
+		return accessError(err)
+	}
+
+	return s.subscribeRoom(ctx, c, room, env.ID, Resume{LastSeq: cmd.LastSeq, LastMessageID: cmd.LastMessageID})
+}
+
+// accessError turns the errors of room access checks into command errors.
+func accessError(err error) error {
+	switch {
+	case errors.Is(err, access.ErrRoomNotFound):
+		return newCommandError(ErrCodeRoomNotFound, "room not found")
+	case errors.Is(err, access.ErrNotRoomMember):
+		return newCommandError(ErrCodeForbidden, "not a member of this room")
+	}
+	return err
+}
+
+func (s *Service) unsubscribe(c *client, env Envelope) error {
+	sub, ok := c.removeSubscription(env.Room)
+	if !ok {
+		return newCommandError(ErrCodeNotSubscribed, "not subscribed to this room")
 	}
+	s.hub.unsubscribe(sub)
+	s.expireTyping(sub)
 
+	return c.emit(EventUnsubscribed, sub.room.Slug, env.ID, struct{}{})
+}
+
+func (s *Service) unsubscribeAll(c *client) {
+	subs, userSub := c.removeSubscriptions()
+	for _, sub := range subs {
+		s.hub.unsubscribe(sub)
+		s.expireTyping(sub)
+	}
+	if userSub != nil {
+		s.userHub.unsubscribe(userSub)
+	}
 }
 
 func (s *Service) handleIncoming(
 	ctx context.Context,
-	conn *websocket.Conn,
-	roomID uuid.UUID,
-	user model.User,
+	c *client,
 	errChan chan error,
 ) {
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		default:
-			msgType, msgBytes, err := conn.ReadMessage()
+			msgType, msgBytes, err := c.conn.ReadMessage()
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
-					errChan <- fmt.Errorf("websocket closed unexpectedly: %w", err)
+					err = fmt.Errorf("websocket closed unexpectedly: %w", err)
+				} else {
+					err = nil
 				}
+				errChan <- err
 				return
 			}
 			if msgType != websocket.TextMessage {
 				continue
 			}
 
-			message, err := s.processMessage(ctx, roomID, string(msgBytes), user)
-			if err != nil {
-				slog.Warn("Error creating message", "err", err)
+			var env Envelope
+			if err := json.Unmarshal(msgBytes, &env); err != nil || env.Type == "" {
+				s.replyError(c, "", "", newCommandError(ErrCodeInvalidEnvelope, "malformed envelope"))
 				continue
 			}
 
-			payload, err := json.Marshal(message)
-			if err != nil {
-				slog.Warn("Error marshaling message", "err", err)
-				continue
+			if err := s.dispatch(ctx, c, env); err != nil {
+				s.replyError(c, env.Room, env.ID, err)
 			}
+		}
+	}
+}
+
+func (s *Service) dispatch(ctx context.Context, c *client, env Envelope) error {
+	switch env.Type {
+	case CommandSubscribe:
+		if c.defaultRoom != "" {
+			return newCommandError(ErrCodeUnsupported, "subscriptions are only available on /ws")
+		}
+		return s.subscribe(ctx, c, env)
+	case CommandUnsubscribe:
+		if c.defaultRoom != "" {
+			return newCommandError(ErrCodeUnsupported, "subscriptions are only available on /ws")
+		}
+		return s.unsubscribe(c, env)
+	case CommandSetPresence:
+		return s.setPresence(ctx, c, env)
+	}
+
+	sub, ok := c.subscription(env.Room)
+	if !ok {
+		return newCommandError(ErrCodeNotSubscribed, "not subscribed to this room")
+	}
 
-			s.rdb.Publish(ctx, roomID.String(), payload)
+	switch env.Type {
+	case CommandSendMessage:
+		var cmd SendMessageCommand
+		if err := decodeData(env, &cmd); err != nil {
+			return err
+		}
+
+		message, err := s.processMessage(ctx, sub.room.ID, cmd, c.user)
+		if err != nil {
+			return err
+		}
 
+		if message.ThreadID != nil {
+			if err := s.publishThreadReply(ctx, sub.room, env.ID, message); err != nil {
+				return err
+			}
+		} else if err := s.publish(ctx, sub.room, EventMessageCreated, env.ID, message); err != nil {
+			return err
 		}
+		return s.stopTyping(ctx, sub, "")
+	case CommandTypingStart:
+		return s.startTyping(ctx, sub, env.ID)
+	case CommandTypingStop:
+		return s.stopTyping(ctx, sub, env.ID)
+	case CommandAck:
+		return s.ack(ctx, sub, env)
+	case CommandEditMessage:
+		return s.editMessage(ctx, sub, env)
+	case CommandDeleteMessage:
+		return s.deleteMessage(ctx, sub, env)
+	default:
+		return newCommandError(ErrCodeUnknownType, fmt.Sprintf("unknown command type %q", env.Type))
 	}
 }
 
-func (s *Service) processMessage(ctx context.Context, roomID uuid.UUID, content string, sender model.User) (model.Message, error) {
-	msg, err := model.NewMessage(roomID, content, sender)
+func (s *Service) processMessage(ctx context.Context, roomID uuid.UUID, cmd SendMessageCommand, sender model.User) (model.Message, error) {
+	msg, err := model.NewMessage(roomID, cmd.Content, sender)
 	if err != nil {
-		return model.Message{}, err
+		return model.Message{}, newCommandError(ErrCodeInvalidPayload, err.Error())
+	}
+
+	if cmd.ThreadID != nil {
+		root, err := s.messageRepo.GetMessage(ctx, *cmd.ThreadID)
+		if err != nil {
+			if errors.Is(err, repository.ErrMessageNotFound) {
+				return model.Message{}, newCommandError(ErrCodeMessageNotFound, "thread root not found")
+			}
+			return model.Message{}, err
+		}
+		if err := msg.PostInThread(root); err != nil {
+			if errors.Is(err, model.ErrInvalidThreadRoot) {
+				return model.Message{}, newCommandError(ErrCodeInvalidPayload, "not a thread root of this room")
+			}
+			return model.Message{}, newCommandError(ErrCodeInvalidPayload, "cannot reply in the thread of a deleted message")
+		}
+	}
+
+	if cmd.ReplyToID != nil {
+		parent, err := s.messageRepo.GetMessage(ctx, *cmd.ReplyToID)
+		if err != nil {
+			if errors.Is(err, repository.ErrMessageNotFound) {
+				return model.Message{}, newCommandError(ErrCodeMessageNotFound, "reply parent not found")
+			}
+			return model.Message{}, err
+		}
+		if err := msg.ReplyToMessage(parent); err != nil {
+			if errors.Is(err, model.ErrInvalidReplyParent) {
+				return model.Message{}, newCommandError(ErrCodeMessageNotFound, "reply parent not found in this room or thread")
+			}
+			return model.Message{}, newCommandError(ErrCodeInvalidPayload, "cannot reply to a deleted message")
+		}
+	}
+
+	if len(cmd.AttachmentIDs) > 0 {
+		if err := s.messages.AttachFiles(ctx, &msg, cmd.AttachmentIDs); err != nil {
+			return model.Message{}, attachmentError(err)
+		}
 	}
 
 	createdMessage, err := s.messageRepo.CreateMessage(ctx, msg)
 	if err != nil {
-		return model.Message{}, err
+		return model.Message{}, attachmentError(err)
 	}
 
 	return createdMessage, nil
 
 }
 
+// attachmentError turns the errors of attaching files to a message into
+// command errors.
+func attachmentError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrTooManyAttachments):
+		return newCommandError(ErrCodeInvalidPayload, fmt.Sprintf("a message can carry at most %d attachments", model.MaxMessageAttachments))
+	case errors.Is(err, model.ErrAttachmentUnavailable):
+		return newCommandError(ErrCodeInvalidPayload, "attachment not found or already sent")
+	}
+	return err
+}
+
+func (s *Service) publish(ctx context.Context, room model.Room, eventType, id string, data any) error {
+	payload, err := encodeEnvelope(eventType, room.Slug, id, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.stream.publish(ctx, room.ID, payload)
+	return err
+}
+
+func (s *Service) replyError(c *client, room, id string, err error) {
+	cmdErr, ok := asCommandError(err)
+	if !ok {
+		slog.Warn("Error handling command", "err", err)
+		cmdErr = newCommandError(ErrCodeInternal, "internal error")
+	}
+
+	if err := c.emit(EventError, room, id, ErrorEvent{Code: cmdErr.Code, Message: cmdErr.Message}); err != nil {
+		slog.Warn("Error sending event", "type", EventError, "err", err)
+	}
+}
+
 func (s *Service) handleOutgoing(
 	ctx context.Context,
-	conn *websocket.Conn,
-	ch <-chan *redis.Message,
+	c *client,
 	errChan chan error,
 ) {
-	ticker := time.NewTicker(30 * time.Second)
+	ticker := time.NewTicker(pingPeriod)
 	defer ticker.Stop()
 
 	for {
@@ -154,17 +483,15 @@ func (s *Service) handleOutgoing(
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			err := conn.WriteMessage(websocket.PingMessage, nil)
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err := c.conn.WriteMessage(websocket.PingMessage, nil)
 			if err != nil {
 				errChan <- err
 				return
 			}
-		case msg, ok := <-ch:
-			if !ok {
-				errChan <- fmt.Errorf("redis channel closed")
-				return
-			}
-			err := conn.WriteMessage(websocket.TextMessage, []byte(msg.Payload))
+		case payload := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err := c.conn.WriteMessage(websocket.TextMessage, payload)
 			if err != nil {
 				errChan <- err
 				return
@@ -0,0 +1,297 @@
+package ws
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	streamEventField  = "event"
+	streamSenderField = "sender"
+)
+
+// publishScript assigns the next sequence number of the topic and appends the
+// event under the stream ID "<seq>-0", so stream IDs and sequence numbers are
+// the same thing and consumers can resume with a plain XREAD. Only the stream
+// expires: the sequence counter must never restart, or readers holding an
+// older ID would skip the new events. PERSIST clears the TTL that earlier
+// versions put on the counter.
+var publishScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[2])
+redis.call('PERSIST', KEYS[2])
+redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[2], seq .. '-0', 'event', ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return seq
+`)
+
+// publishEphemeralScript appends an event without taking a sequence number.
+// It lands under "<current seq>-*", between two sequenced events, which is how
+// readers tell it apart: ephemeral events have a non-zero sequence part.
+var publishEphemeralScript = redis.NewScript(`
+local seq = redis.call('GET', KEYS[2]) or '0'
+redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[3], seq .. '-*', 'event', ARGV[1], 'sender', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return seq
+`)
+
+// streamEvent is an event read from the stream. Ephemeral events such as
+// typing indicators have no Seq and are never replayed. Removed is set on
+// member.removed events to the user whose connections have to be closed.
+type streamEvent struct {
+	ID        string
+	Seq       uint64
+	Ephemeral bool
+	Sender    uuid.UUID
+	Removed   uuid.UUID
+	Payload   []byte
+}
+
+// EventStream stores events in a capped Redis Stream per topic. Topics are
+// rooms, or users for events addressed to all of a user's connections.
+type EventStream struct {
+	rdb       *redis.Client
+	reader    *redis.Client
+	keyPrefix string
+	maxLen    int64
+	ttl       time.Duration
+}
+
+// NewEventStream creates an event stream. Blocking reads hold a connection for
+// their whole duration, so they use their own pool of readerPoolSize
+// connections and never starve regular commands.
+func NewEventStream(rdb *redis.Client, keyPrefix string, maxLen int64, ttl time.Duration, readerPoolSize int) *EventStream {
+	opts := *rdb.Options()
+	opts.PoolSize = readerPoolSize
+
+	return &EventStream{
+		rdb:       rdb,
+		reader:    redis.NewClient(&opts),
+		keyPrefix: keyPrefix,
+		maxLen:    maxLen,
+		ttl:       ttl,
+	}
+}
+
+// WithKeyPrefix returns a stream for another set of topics that shares the
+// Redis clients of s.
+func (s *EventStream) WithKeyPrefix(keyPrefix string) *EventStream {
+	stream := *s
+	stream.keyPrefix = keyPrefix
+	return &stream
+}
+
+func (s *EventStream) Close() error {
+	return s.reader.Close()
+}
+
+func (s *EventStream) streamKey(topic uuid.UUID) string {
+	return s.keyPrefix + topic.String()
+}
+
+func (s *EventStream) seqKey(topic uuid.UUID) string {
+	return s.keyPrefix + topic.String() + ":seq"
+}
+
+func (s *EventStream) publish(ctx context.Context, topic uuid.UUID, payload []byte) (uint64, error) {
+	keys := []string{s.streamKey(topic), s.seqKey(topic)}
+
+	seq, err := publishScript.Run(ctx, s.rdb, keys, payload, s.maxLen, s.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(seq), nil
+}
+
+func (s *EventStream) publishEphemeral(ctx context.Context, topic, senderID uuid.UUID, payload []byte) error {
+	keys := []string{s.streamKey(topic), s.seqKey(topic)}
+
+	return publishEphemeralScript.Run(ctx, s.rdb, keys, payload, senderID.String(), s.maxLen, s.ttl.Milliseconds()).Err()
+}
+
+// head returns the sequence number of the latest event published to the topic.
+func (s *EventStream) head(ctx context.Context, topic uuid.UUID) (uint64, error) {
+	seq, err := s.rdb.Get(ctx, s.seqKey(topic)).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return seq, err
+}
+
+// lastID returns the ID of the newest entry, sequenced or not, so a reader
+// of a topic without sequenced events can start from now.
+func (s *EventStream) lastID(ctx context.Context, topic uuid.UUID) (string, error) {
+	entries, err := s.rdb.XRevRangeN(ctx, s.streamKey(topic), "+", "-", 1).Result()
+	if err != nil || len(entries) == 0 {
+		return streamID(0), err
+	}
+	return entries[0].ID, nil
+}
+
+// oldest returns the sequence number of the oldest sequenced event still
+// retained, or zero when the stream is empty.
+func (s *EventStream) oldest(ctx context.Context, topic uuid.UUID) (uint64, error) {
+	entries, err := s.rdb.XRangeN(ctx, s.streamKey(topic), "-", "+", 1).Result()
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+
+	seq, sub := splitStreamID(entries[0].ID)
+	if sub > 0 {
+		// The sequenced event this ephemeral one followed was trimmed.
+		seq++
+	}
+	return seq, nil
+}
+
+// rangeAfter returns the retained events after the stream ID afterID up to
+// and including untilID.
+func (s *EventStream) rangeAfter(ctx context.Context, topic uuid.UUID, afterID, untilID string) ([]streamEvent, error) {
+	entries, err := s.rdb.XRange(ctx, s.streamKey(topic), "("+afterID, untilID).Result()
+	if err != nil {
+		return nil, err
+	}
+	return toStreamEvents(entries)
+}
+
+// read blocks for up to block waiting for events after the given stream ID.
+// It returns no events and no error when the block times out.
+func (s *EventStream) read(ctx context.Context, topic uuid.UUID, afterID string, block time.Duration) ([]streamEvent, error) {
+	streams, err := s.reader.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{s.streamKey(topic), afterID},
+		Count:   100,
+		Block:   block,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var events []streamEvent
+	for _, stream := range streams {
+		batch, err := toStreamEvents(stream.Messages)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, batch...)
+	}
+	return events, nil
+}
+
+// readMany is read over several topics at once, each after its own stream ID.
+// Only topics with new events are in the result.
+func (s *EventStream) readMany(ctx context.Context, afterIDs map[uuid.UUID]string, block time.Duration) (map[uuid.UUID][]streamEvent, error) {
+	keys := make([]string, 0, 2*len(afterIDs))
+	ids := make([]string, 0, len(afterIDs))
+	for topic, afterID := range afterIDs {
+		keys = append(keys, s.streamKey(topic))
+		ids = append(ids, afterID)
+	}
+
+	streams, err := s.reader.XRead(ctx, &redis.XReadArgs{
+		Streams: append(keys, ids...),
+		Count:   100,
+		Block:   block,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	events := make(map[uuid.UUID][]streamEvent, len(streams))
+	for _, stream := range streams {
+		topic, err := uuid.Parse(strings.TrimPrefix(stream.Stream, s.keyPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("stream key %s: %w", stream.Stream, err)
+		}
+		batch, err := toStreamEvents(stream.Messages)
+		if err != nil {
+			return nil, err
+		}
+		events[topic] = batch
+	}
+	return events, nil
+}
+
+func toStreamEvents(entries []redis.XMessage) ([]streamEvent, error) {
+	events := make([]streamEvent, 0, len(entries))
+	for _, entry := range entries {
+		event, err := toStreamEvent(entry)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// toStreamEvent stamps the stored envelope with the sequence number taken
+// from the entry ID.
+func toStreamEvent(entry redis.XMessage) (streamEvent, error) {
+	seq, sub := splitStreamID(entry.ID)
+
+	raw, ok := entry.Values[streamEventField].(string)
+	if !ok {
+		return streamEvent{}, fmt.Errorf("stream entry %s has no event", entry.ID)
+	}
+
+	if sub > 0 {
+		sender, _ := entry.Values[streamSenderField].(string)
+		senderID, _ := uuid.Parse(sender)
+		return streamEvent{ID: entry.ID, Ephemeral: true, Sender: senderID, Payload: []byte(raw)}, nil
+	}
+
+	var env Envelope
+	if err := json.Unmarshal([]byte(raw), &env); err != nil {
+		return streamEvent{}, err
+	}
+	env.Seq = seq
+
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return streamEvent{}, err
+	}
+
+	event := streamEvent{ID: entry.ID, Seq: seq, Payload: payload}
+	if env.Type == EventMemberRemoved {
+		var removed MemberRemovedEvent
+		if err := json.Unmarshal(env.Data, &removed); err != nil {
+			return streamEvent{}, err
+		}
+		event.Removed = removed.UserID
+	}
+	return event, nil
+}
+
+func streamID(seq uint64) string {
+	return strconv.FormatUint(seq, 10) + "-0"
+}
+
+// compareStreamIDs orders two stream IDs of the form "<ms>-<seq>".
+func compareStreamIDs(a, b string) int {
+	aMs, aSeq := splitStreamID(a)
+	bMs, bSeq := splitStreamID(b)
+	if aMs != bMs {
+		return cmp.Compare(aMs, bMs)
+	}
+	return cmp.Compare(aSeq, bSeq)
+}
+
+func splitStreamID(id string) (uint64, uint64) {
+	ms, seq, _ := strings.Cut(id, "-")
+	msValue, _ := strconv.ParseUint(ms, 10, 64)
+	seqValue, _ := strconv.ParseUint(seq, 10, 64)
+	return msValue, seqValue
+}
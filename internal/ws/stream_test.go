@@ -0,0 +1,197 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestStream returns a stream on the Redis at APP_REDIS_ADDR under a key
+// prefix of its own. Tests that need Redis are skipped without it.
+func newTestStream(t *testing.T, ttl time.Duration) *EventStream {
+	t.Helper()
+	addr := os.Getenv("APP_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("APP_REDIS_ADDR is not set")
+	}
+
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { rdb.Close() })
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("connect to redis at %s: %v", addr, err)
+	}
+
+	stream := NewEventStream(rdb, "test:events:"+uuid.NewString()+":", 100, ttl, 1)
+	t.Cleanup(func() { stream.Close() })
+	return stream
+}
+
+func TestCompareStreamIDs(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1-0", "1-0", 0},
+		{"1-0", "2-0", -1},
+		{"10-0", "9-0", 1},
+		{"3-1", "3-0", 1},
+		{"3-0", "3-2", -1},
+		{"3-9", "4-0", -1},
+		{"0-0", "", 0},
+	}
+	for _, tt := range tests {
+		if got := compareStreamIDs(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareStreamIDs(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestStreamIDRoundTrip(t *testing.T) {
+	for _, seq := range []uint64{0, 1, 42, 1 << 40} {
+		got, sub := splitStreamID(streamID(seq))
+		if got != seq || sub != 0 {
+			t.Errorf("splitStreamID(streamID(%d)) = %d, %d", seq, got, sub)
+		}
+	}
+}
+
+func TestToStreamEventStampsSeq(t *testing.T) {
+	raw := mustEnvelope(t, EventMessageCreated, map[string]string{"content": "hi"})
+
+	event, err := toStreamEvent(redis.XMessage{ID: "7-0", Values: map[string]any{streamEventField: raw}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Seq != 7 || event.Ephemeral || event.ID != "7-0" {
+		t.Fatalf("got %+v, want seq 7 and not ephemeral", event)
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(event.Payload, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Seq != 7 || env.Type != EventMessageCreated {
+		t.Errorf("payload envelope = %+v, want seq 7", env)
+	}
+}
+
+func TestToStreamEventEphemeral(t *testing.T) {
+	sender := uuid.New()
+	raw := mustEnvelope(t, EventTypingStarted, nil)
+
+	event, err := toStreamEvent(redis.XMessage{ID: "7-3", Values: map[string]any{
+		streamEventField:  raw,
+		streamSenderField: sender.String(),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !event.Ephemeral || event.Seq != 0 || event.Sender != sender {
+		t.Errorf("got %+v, want an ephemeral event from %s", event, sender)
+	}
+	if string(event.Payload) != raw {
+		t.Errorf("ephemeral payload was rewritten: %s", event.Payload)
+	}
+}
+
+func TestToStreamEventWithoutEvent(t *testing.T) {
+	if _, err := toStreamEvent(redis.XMessage{ID: "1-0", Values: map[string]any{}}); err == nil {
+		t.Error("expected an error for an entry without event")
+	}
+}
+
+func mustEnvelope(t *testing.T, eventType string, data any) string {
+	t.Helper()
+	payload, err := encodeEnvelope(eventType, "room", "", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(payload)
+}
+
+func TestPublishKeepsSeqAfterStreamExpires(t *testing.T) {
+	stream := newTestStream(t, 100*time.Millisecond)
+	ctx := context.Background()
+	topic := uuid.New()
+	t.Cleanup(func() { stream.rdb.Del(ctx, stream.streamKey(topic), stream.seqKey(topic)) })
+	payload := []byte(mustEnvelope(t, EventMessageDeleted, nil))
+
+	for want := uint64(1); want <= 2; want++ {
+		seq, err := stream.publish(ctx, topic, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq != want {
+			t.Fatalf("publish = seq %d, want %d", seq, want)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for stream.rdb.Exists(ctx, stream.streamKey(topic)).Val() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream did not expire")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if ttl := stream.rdb.PTTL(ctx, stream.seqKey(topic)).Val(); ttl != -1 {
+		t.Fatalf("seq key TTL = %s, want none", ttl)
+	}
+
+	seq, err := stream.publish(ctx, topic, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != 3 {
+		t.Fatalf("publish after expiry = seq %d, want 3", seq)
+	}
+
+	events, err := stream.rangeAfter(ctx, topic, streamID(0), "+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].ID != streamID(3) {
+		t.Errorf("stream after expiry = %+v, want only seq 3", events)
+	}
+}
+
+func TestPublishClearsSeqTTL(t *testing.T) {
+	stream := newTestStream(t, time.Hour)
+	ctx := context.Background()
+	topic := uuid.New()
+	t.Cleanup(func() { stream.rdb.Del(ctx, stream.streamKey(topic), stream.seqKey(topic)) })
+
+	// Earlier versions put the stream TTL on the seq key too.
+	if err := stream.rdb.Set(ctx, stream.seqKey(topic), 41, time.Minute).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	seq, err := stream.publish(ctx, topic, []byte(mustEnvelope(t, EventMessageDeleted, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != 42 {
+		t.Errorf("publish = seq %d, want 42", seq)
+	}
+	if ttl := stream.rdb.PTTL(ctx, stream.seqKey(topic)).Val(); ttl != -1 {
+		t.Errorf("seq key TTL = %s, want none", ttl)
+	}
+}
+
+func TestToStreamEventMemberRemoved(t *testing.T) {
+	removed := uuid.New()
+	raw := mustEnvelope(t, EventMemberRemoved, MemberRemovedEvent{UserID: removed, Reason: RemovedKicked})
+
+	event, err := toStreamEvent(redis.XMessage{ID: "2-0", Values: map[string]any{streamEventField: raw}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Removed != removed {
+		t.Errorf("Removed = %s, want %s", event.Removed, removed)
+	}
+}
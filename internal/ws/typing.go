@@ -0,0 +1,80 @@
+package ws
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	// typingThrottle is the minimum interval between two typing.started
+	// broadcasts for the same user in a room. Clients may send typing.start
+	// on every keystroke.
+	typingThrottle = 3 * time.Second
+	// typingTimeout stops a typing indicator that was not refreshed, e.g.
+	// because the client crashed before sending typing.stop.
+	typingTimeout = 2 * typingThrottle
+)
+
+func (s *Service) startTyping(ctx context.Context, sub *subscription, id string) error {
+	sub.typingMu.Lock()
+	now := time.Now()
+	broadcast := sub.typingTimer == nil || now.Sub(sub.typingSentAt) >= typingThrottle
+	if sub.typingTimer == nil {
+		sub.typingTimer = time.AfterFunc(typingTimeout, func() {
+			s.expireTyping(sub)
+		})
+	} else {
+		sub.typingTimer.Reset(typingTimeout)
+	}
+	if broadcast {
+		sub.typingSentAt = now
+	}
+	sub.typingMu.Unlock()
+
+	if !broadcast {
+		return nil
+	}
+	return s.publishTyping(ctx, sub, EventTypingStarted, id, typingTimeout)
+}
+
+// stopTyping broadcasts typing.stopped if the user was shown as typing.
+func (s *Service) stopTyping(ctx context.Context, sub *subscription, id string) error {
+	sub.typingMu.Lock()
+	timer := sub.typingTimer
+	sub.typingTimer = nil
+	sub.typingMu.Unlock()
+
+	if timer == nil {
+		return nil
+	}
+	timer.Stop()
+
+	return s.publishTyping(ctx, sub, EventTypingStopped, id, 0)
+}
+
+// expireTyping runs outside of any request, e.g. from the typing timer or
+// when the connection is already gone.
+func (s *Service) expireTyping(sub *subscription) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeWait)
+	defer cancel()
+
+	if err := s.stopTyping(ctx, sub, ""); err != nil {
+		slog.Warn("Error stopping typing indicator", "room", sub.room.ID, "err", err)
+	}
+}
+
+func (s *Service) publishTyping(ctx context.Context, sub *subscription, eventType, id string, expiresIn time.Duration) error {
+	user := sub.client.user
+
+	payload, err := encodeEnvelope(eventType, sub.room.Slug, id, TypingEvent{
+		UserID:    user.ID,
+		Username:  user.Username,
+		ExpiresIn: expiresIn.Milliseconds(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.stream.publishEphemeral(ctx, sub.room.ID, user.ID, payload)
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE room_members
+    ADD COLUMN last_read_message_id UUID,
+    ADD COLUMN last_read_at         TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE room_members
+    DROP COLUMN last_read_message_id,
+    DROP COLUMN last_read_at;
+-- +goose StatementEnd
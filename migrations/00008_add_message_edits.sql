@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN edited_at TIMESTAMPTZ;
+
+CREATE TABLE message_revisions
+(
+    id         UUID PRIMARY KEY,
+    message_id UUID        NOT NULL REFERENCES messages (id) ON DELETE CASCADE,
+    content    TEXT        NOT NULL,
+    edited_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_message_revisions_message_edited
+    ON message_revisions (message_id, edited_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE message_revisions;
+ALTER TABLE messages DROP COLUMN edited_at;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by UUID REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_messages_deleted_at ON messages (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_messages_deleted_at;
+ALTER TABLE messages
+    DROP COLUMN deleted_by,
+    DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN reply_to_id UUID REFERENCES messages (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_messages_reply_to_id ON messages (reply_to_id) WHERE reply_to_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_messages_reply_to_id;
+ALTER TABLE messages DROP COLUMN reply_to_id;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN thread_id UUID REFERENCES messages (id) ON DELETE CASCADE;
+
+CREATE INDEX idx_messages_thread_created ON messages (thread_id, created_at, id) WHERE thread_id IS NOT NULL;
+
+CREATE TABLE thread_follows
+(
+    thread_id  UUID        NOT NULL REFERENCES messages (id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    following  BOOLEAN     NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (thread_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE thread_follows;
+DROP INDEX idx_messages_thread_created;
+ALTER TABLE messages DROP COLUMN thread_id;
+-- +goose StatementEnd
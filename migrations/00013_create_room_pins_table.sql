@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE room_pins
+(
+    room_id    UUID        NOT NULL REFERENCES rooms (id) ON DELETE CASCADE,
+    message_id UUID        NOT NULL REFERENCES messages (id) ON DELETE CASCADE,
+    pinned_by  UUID        REFERENCES users (id) ON DELETE SET NULL,
+    pinned_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (room_id, message_id)
+);
+
+CREATE INDEX idx_room_pins_room_pinned_at ON room_pins (room_id, pinned_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE room_pins;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE attachments
+(
+    id            UUID PRIMARY KEY,
+    room_id       UUID         NOT NULL REFERENCES rooms (id) ON DELETE CASCADE,
+    uploader_id   UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    message_id    UUID         REFERENCES messages (id) ON DELETE CASCADE,
+    name          VARCHAR(255) NOT NULL,
+    content_type  VARCHAR(127) NOT NULL,
+    size          BIGINT       NOT NULL,
+    width         INT,
+    height        INT,
+    storage_key   TEXT         NOT NULL,
+    thumbnail_key TEXT,
+    created_at    TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX idx_attachments_message_id ON attachments (message_id);
+CREATE INDEX idx_attachments_unclaimed_created_at ON attachments (created_at) WHERE message_id IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE attachments;
+-- +goose StatementEnd
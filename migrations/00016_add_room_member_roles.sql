@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE room_members ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'member';
+
+-- Rooms had no owner so far; the earliest member takes over.
+UPDATE room_members rm
+SET role = 'owner'
+WHERE rm.id = (SELECT first.id
+               FROM room_members first
+               WHERE first.room_id = rm.room_id
+               ORDER BY first.joined_at, first.id
+               LIMIT 1);
+
+ALTER TABLE room_members
+    ADD CONSTRAINT room_members_role_check CHECK (role IN ('owner', 'admin', 'moderator', 'member'));
+
+CREATE UNIQUE INDEX idx_room_members_owner
+    ON room_members (room_id)
+    WHERE role = 'owner';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_room_members_owner;
+ALTER TABLE room_members DROP COLUMN role;
+-- +goose StatementEnd
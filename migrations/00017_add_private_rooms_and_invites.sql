@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE rooms
+    ADD COLUMN visibility VARCHAR(16) NOT NULL DEFAULT 'public'
+        CHECK (visibility IN ('public', 'private'));
+
+CREATE TABLE room_invites
+(
+    id         UUID PRIMARY KEY,
+    room_id    UUID        NOT NULL REFERENCES rooms (id) ON DELETE CASCADE,
+    code       VARCHAR(16) NOT NULL UNIQUE,
+    created_by UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    max_uses   INT,
+    uses       INT         NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_room_invites_room_created_at ON room_invites (room_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE room_invites;
+ALTER TABLE rooms DROP COLUMN visibility;
+-- +goose StatementEnd
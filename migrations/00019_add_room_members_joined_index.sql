@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_room_members_room_joined
+    ON room_members (room_id, joined_at, user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_room_members_room_joined;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- NULL falls back to the instance-wide ROOM_MAX_PINS.
+ALTER TABLE rooms
+    ADD COLUMN max_pins INT CHECK (max_pins > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE rooms DROP COLUMN max_pins;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- name: CreateAttachment :one
+INSERT INTO attachments (id, room_id, uploader_id, name, content_type, size, width, height, storage_key,
+                         thumbnail_key, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetAttachment :one
+SELECT a.*, m.deleted_at AS message_deleted_at
+FROM attachments a
+         LEFT JOIN messages m ON m.id = a.message_id
+WHERE a.id = $1;
+
+-- name: ListAttachmentsByIDs :many
+SELECT *
+FROM attachments
+WHERE id = ANY (@ids::uuid[]);
+
+-- name: ListMessagesAttachments :many
+SELECT *
+FROM attachments
+WHERE message_id = ANY (@message_ids::uuid[])
+ORDER BY message_id, created_at, id;
+
+-- name: ClaimAttachments :execrows
+UPDATE attachments
+SET message_id = @message_id::uuid
+WHERE id = ANY (@ids::uuid[])
+  AND room_id = @room_id::uuid
+  AND uploader_id = @uploader_id::uuid
+  AND message_id IS NULL;
+
+-- name: DeleteExpiredAttachments :many
+DELETE
+FROM attachments
+WHERE id IN (SELECT a.id
+             FROM attachments a
+                      LEFT JOIN messages m ON m.id = a.message_id
+             WHERE (a.message_id IS NULL AND a.created_at < @unsent_before::timestamptz)
+                OR m.deleted_at < @deleted_before::timestamptz
+             LIMIT @limit_ FOR UPDATE OF a SKIP LOCKED)
+RETURNING *;
@@ -0,0 +1,21 @@
+-- name: UpsertRoomBan :exec
+INSERT INTO room_bans (room_id, user_id, banned_by, reason, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (room_id, user_id) DO UPDATE
+    SET banned_by  = excluded.banned_by,
+        reason     = excluded.reason,
+        expires_at = excluded.expires_at,
+        created_at = excluded.created_at;
+
+-- name: GetActiveRoomBan :one
+SELECT *
+FROM room_bans
+WHERE room_id = $1
+  AND user_id = $2
+  AND (expires_at IS NULL OR expires_at > @now::timestamptz);
+
+-- name: DeleteRoomBan :execrows
+DELETE
+FROM room_bans
+WHERE room_id = $1
+  AND user_id = $2;
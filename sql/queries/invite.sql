@@ -0,0 +1,31 @@
+-- name: CreateRoomInvite :one
+INSERT INTO room_invites (id, room_id, code, created_by, max_uses, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListActiveRoomInvites :many
+SELECT *
+FROM room_invites
+WHERE room_id = $1
+  AND revoked_at IS NULL
+  AND expires_at > @now::timestamptz
+  AND (max_uses IS NULL OR uses < max_uses)
+ORDER BY created_at DESC;
+
+-- name: GetRoomInviteByCodeForUpdate :one
+SELECT *
+FROM room_invites
+WHERE code = $1
+    FOR UPDATE;
+
+-- name: IncrementRoomInviteUses :exec
+UPDATE room_invites
+SET uses = uses + 1
+WHERE id = $1;
+
+-- name: RevokeRoomInvite :execrows
+UPDATE room_invites
+SET revoked_at = $3
+WHERE id = $1
+  AND room_id = $2
+  AND revoked_at IS NULL;
@@ -1,13 +1,22 @@
 -- name: CreateMessage :one
-INSERT INTO messages(id, room_id, sender_id, content, created_at)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO messages(id, room_id, sender_id, content, created_at, reply_to_id, thread_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetMessagesPaging :many
-SELECT m.*, u.*
+SELECT m.*,
+       u.*,
+       p.content     AS parent_content,
+       p.deleted_at  AS parent_deleted_at,
+       pu.id         AS parent_sender_id,
+       pu.username   AS parent_sender_username,
+       pu.avatar_url AS parent_sender_avatar_url
 FROM messages m
          JOIN users u ON u.id = m.sender_id
+         LEFT JOIN messages p ON p.id = m.reply_to_id
+         LEFT JOIN users pu ON pu.id = p.sender_id
 WHERE m.room_id = @room_id::uuid
+  AND m.thread_id IS NULL
   AND (
       @cursor_created_at::timestamptz IS NULL
       OR
@@ -15,3 +24,109 @@ WHERE m.room_id = @room_id::uuid
       )
 ORDER BY m.created_at DESC, m.id DESC
 LIMIT @limit_;
+
+-- name: GetThreadMessagesPaging :many
+SELECT m.*,
+       u.*,
+       p.content     AS parent_content,
+       p.deleted_at  AS parent_deleted_at,
+       pu.id         AS parent_sender_id,
+       pu.username   AS parent_sender_username,
+       pu.avatar_url AS parent_sender_avatar_url
+FROM messages m
+         JOIN users u ON u.id = m.sender_id
+         LEFT JOIN messages p ON p.id = m.reply_to_id
+         LEFT JOIN users pu ON pu.id = p.sender_id
+WHERE m.thread_id = @thread_id::uuid
+  AND (
+      @cursor_created_at::timestamptz IS NULL
+      OR
+      (m.created_at, m.id) < (@cursor_created_at::timestamptz, @cursor_id::uuid)
+      )
+ORDER BY m.created_at DESC, m.id DESC
+LIMIT @limit_;
+
+-- name: GetMessage :one
+SELECT m.*,
+       u.*,
+       p.content     AS parent_content,
+       p.deleted_at  AS parent_deleted_at,
+       pu.id         AS parent_sender_id,
+       pu.username   AS parent_sender_username,
+       pu.avatar_url AS parent_sender_avatar_url
+FROM messages m
+         JOIN users u ON u.id = m.sender_id
+         LEFT JOIN messages p ON p.id = m.reply_to_id
+         LEFT JOIN users pu ON pu.id = p.sender_id
+WHERE m.id = $1;
+
+-- name: GetMessagesAfter :many
+SELECT m.*,
+       u.*,
+       p.content     AS parent_content,
+       p.deleted_at  AS parent_deleted_at,
+       pu.id         AS parent_sender_id,
+       pu.username   AS parent_sender_username,
+       pu.avatar_url AS parent_sender_avatar_url
+FROM messages m
+         JOIN users u ON u.id = m.sender_id
+         LEFT JOIN messages p ON p.id = m.reply_to_id
+         LEFT JOIN users pu ON pu.id = p.sender_id
+WHERE m.room_id = @room_id::uuid
+  AND m.thread_id IS NULL
+  AND (m.created_at, m.id) > (@cursor_created_at::timestamptz, @cursor_id::uuid)
+ORDER BY m.created_at, m.id
+LIMIT @limit_;
+
+-- name: ListMessageReaders :many
+SELECT u.id, u.username, u.avatar_url
+FROM room_members rm
+         JOIN users u ON u.id = rm.user_id
+WHERE rm.room_id = @room_id::uuid
+  AND rm.user_id <> @sender_id::uuid
+  AND u.share_read_receipts
+  AND (rm.last_read_at, rm.last_read_message_id) >= (@created_at::timestamptz, @message_id::uuid)
+ORDER BY u.username;
+
+-- name: LockMessage :one
+SELECT *
+FROM messages
+WHERE id = $1
+    FOR UPDATE;
+
+-- name: CreateMessageRevision :exec
+INSERT INTO message_revisions (id, message_id, content, edited_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: UpdateMessageContent :exec
+UPDATE messages
+SET content   = $1,
+    edited_at = $2
+WHERE id = $3;
+
+-- name: ListMessageRevisions :many
+SELECT *
+FROM message_revisions
+WHERE message_id = $1
+ORDER BY edited_at;
+
+-- name: SoftDeleteMessage :execrows
+UPDATE messages
+SET deleted_at = $1,
+    deleted_by = $2
+WHERE id = $3
+  AND deleted_at IS NULL;
+
+-- name: PurgeDeletedMessages :execrows
+WITH purged AS (SELECT id
+                FROM messages
+                WHERE deleted_at < @deleted_before::timestamptz
+                  AND content <> ''
+                ORDER BY deleted_at
+                LIMIT @limit_ FOR UPDATE SKIP LOCKED),
+     revisions AS (
+         DELETE FROM message_revisions
+             WHERE message_id IN (SELECT id FROM purged))
+UPDATE messages
+SET content = ''
+WHERE id IN (SELECT id FROM purged);
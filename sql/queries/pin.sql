@@ -0,0 +1,64 @@
+-- name: PinMessage :execrows
+INSERT INTO room_pins (room_id, message_id, pinned_by, pinned_at)
+SELECT @room_id::uuid, @message_id::uuid, @pinned_by::uuid, @pinned_at::timestamptz
+WHERE (SELECT count(*)
+       FROM room_pins p
+                JOIN messages m ON m.id = p.message_id
+       WHERE p.room_id = @room_id::uuid
+         AND m.deleted_at IS NULL) < @max_pins::int
+ON CONFLICT DO NOTHING;
+
+-- name: UnpinMessage :execrows
+DELETE
+FROM room_pins
+WHERE room_id = $1
+  AND message_id = $2;
+
+-- name: IsMessagePinned :one
+SELECT EXISTS (SELECT 1
+               FROM room_pins
+               WHERE room_id = $1
+                 AND message_id = $2);
+
+-- name: ListRoomPins :many
+SELECT m.id,
+       m.room_id,
+       m.content,
+       m.created_at,
+       m.edited_at,
+       m.thread_id,
+       u.id          AS sender_id,
+       u.username    AS sender_username,
+       u.avatar_url  AS sender_avatar_url,
+       p.pinned_at,
+       pu.id         AS pinned_by_id,
+       pu.username   AS pinned_by_username,
+       pu.avatar_url AS pinned_by_avatar_url
+FROM room_pins p
+         JOIN messages m ON m.id = p.message_id
+         JOIN users u ON u.id = m.sender_id
+         LEFT JOIN users pu ON pu.id = p.pinned_by
+WHERE p.room_id = $1
+  AND m.deleted_at IS NULL
+ORDER BY p.pinned_at, m.id;
+
+-- name: GetRoomPin :one
+SELECT m.id,
+       m.room_id,
+       m.content,
+       m.created_at,
+       m.edited_at,
+       m.thread_id,
+       u.id          AS sender_id,
+       u.username    AS sender_username,
+       u.avatar_url  AS sender_avatar_url,
+       p.pinned_at,
+       pu.id         AS pinned_by_id,
+       pu.username   AS pinned_by_username,
+       pu.avatar_url AS pinned_by_avatar_url
+FROM room_pins p
+         JOIN messages m ON m.id = p.message_id
+         JOIN users u ON u.id = m.sender_id
+         LEFT JOIN users pu ON pu.id = p.pinned_by
+WHERE p.room_id = $1
+  AND p.message_id = $2;
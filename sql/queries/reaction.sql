@@ -0,0 +1,41 @@
+-- name: AddMessageReaction :execrows
+INSERT INTO message_reactions (message_id, user_id, emoji, created_at)
+SELECT @message_id::uuid, @user_id::uuid, @emoji::text, @created_at::timestamptz
+WHERE EXISTS (SELECT 1
+              FROM message_reactions
+              WHERE message_id = @message_id::uuid
+                AND emoji = @emoji::text)
+   OR (SELECT count(DISTINCT emoji)
+       FROM message_reactions
+       WHERE message_id = @message_id::uuid) < @max_emojis::int
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveMessageReaction :execrows
+DELETE
+FROM message_reactions
+WHERE message_id = $1
+  AND user_id = $2
+  AND emoji = $3;
+
+-- name: UserReactionExists :one
+SELECT EXISTS (SELECT 1
+               FROM message_reactions
+               WHERE message_id = $1
+                 AND user_id = $2
+                 AND emoji = $3);
+
+-- name: CountMessageReaction :one
+SELECT count(*)::int
+FROM message_reactions
+WHERE message_id = $1
+  AND emoji = $2;
+
+-- name: ListMessagesReactions :many
+SELECT message_id,
+       emoji,
+       count(*)::int                                AS count,
+       bool_or(user_id = @viewer_id::uuid)::boolean AS reacted_by_me
+FROM message_reactions
+WHERE message_id = ANY (@message_ids::uuid[])
+GROUP BY message_id, emoji
+ORDER BY message_id, min(created_at);
@@ -3,6 +3,12 @@ SELECT *
 FROM rooms
 WHERE id = $1;
 
+-- name: LockRoom :one
+SELECT id
+FROM rooms
+WHERE id = $1
+    FOR UPDATE;
+
 -- name: GetUserRooms :many
 SELECT r.*
 FROM rooms r
@@ -10,15 +16,28 @@ FROM rooms r
 WHERE rm.user_id = $1;
 
 -- name: CreateRoom :one
-INSERT INTO rooms (id, name, slug, created_at)
-VALUES ($1, $2, $3, $4)
+INSERT INTO rooms (id, name, slug, visibility, created_at)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 -- name: AddRoomMember :exec
-INSERT INTO room_members (id, room_id, user_id, joined_at)
-VALUES ($1, $2, $3, $4)
+INSERT INTO room_members (id, room_id, user_id, role, joined_at)
+VALUES ($1, $2, $3, $4, $5)
 ON CONFLICT (room_id, user_id) DO NOTHING;
-;
+
+-- name: UpdateRoom :one
+UPDATE rooms
+SET name       = $2,
+    visibility = $3,
+    max_pins   = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: RemoveRoomMember :execrows
+DELETE
+FROM room_members
+WHERE room_id = $1
+  AND user_id = $2;
 
 -- name: RoomExists :one
 SELECT EXISTS (SELECT 1
@@ -36,4 +55,99 @@ SELECT EXISTS (SELECT 1
 SELECT *
 FROM rooms
 WHERE slug = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: ListRoomMemberIDs :many
+SELECT user_id
+FROM room_members
+WHERE room_id = $1
+ORDER BY joined_at;
+
+-- name: ListRoomMembers :many
+SELECT rm.user_id, rm.role, rm.joined_at, u.username, u.avatar_url
+FROM room_members rm
+         JOIN users u ON u.id = rm.user_id
+WHERE rm.room_id = @room_id::uuid
+  AND lower(u.username) LIKE @username_pattern::text
+  AND (
+      @cursor_joined_at::timestamptz IS NULL
+      OR
+      (rm.joined_at, rm.user_id) > (@cursor_joined_at::timestamptz, @cursor_user_id::uuid)
+      )
+ORDER BY rm.joined_at, rm.user_id
+LIMIT @limit_;
+
+-- name: GetUserRoomsWithReadState :many
+SELECT r.*,
+       rm.role,
+       rm.last_read_message_id,
+       (SELECT count(*)
+        FROM messages m
+        WHERE m.room_id = r.id
+          AND m.sender_id <> rm.user_id
+          AND m.deleted_at IS NULL
+          AND m.thread_id IS NULL
+          AND (m.created_at, m.id) > (COALESCE(rm.last_read_at, rm.joined_at),
+                                      COALESCE(rm.last_read_message_id, '00000000-0000-0000-0000-000000000000'::uuid))
+       )::int AS unread_count,
+       (SELECT count(*)
+        FROM messages m
+        WHERE m.room_id = r.id
+          AND m.sender_id <> rm.user_id
+          AND m.deleted_at IS NULL
+          AND m.thread_id IS NULL
+          AND (m.created_at, m.id) > (COALESCE(rm.last_read_at, rm.joined_at),
+                                      COALESCE(rm.last_read_message_id, '00000000-0000-0000-0000-000000000000'::uuid))
+          AND m.content ~* ('(^|\s)@' || regexp_replace(u.username, '([.^$*+?()\[\]{}|\\])', '\\\1', 'g') || '\M')
+       )::int AS mention_count
+FROM rooms r
+         JOIN room_members rm ON rm.room_id = r.id
+         JOIN users u ON u.id = rm.user_id
+WHERE rm.user_id = $1;
+
+-- name: GetRoomReadState :one
+SELECT rm.last_read_message_id,
+       (SELECT count(*)
+        FROM messages m
+        WHERE m.room_id = rm.room_id
+          AND m.sender_id <> rm.user_id
+          AND m.deleted_at IS NULL
+          AND m.thread_id IS NULL
+          AND (m.created_at, m.id) > (COALESCE(rm.last_read_at, rm.joined_at),
+                                      COALESCE(rm.last_read_message_id, '00000000-0000-0000-0000-000000000000'::uuid))
+       )::int AS unread_count,
+       (SELECT count(*)
+        FROM messages m
+        WHERE m.room_id = rm.room_id
+          AND m.sender_id <> rm.user_id
+          AND m.deleted_at IS NULL
+          AND m.thread_id IS NULL
+          AND (m.created_at, m.id) > (COALESCE(rm.last_read_at, rm.joined_at),
+                                      COALESCE(rm.last_read_message_id, '00000000-0000-0000-0000-000000000000'::uuid))
+          AND m.content ~* ('(^|\s)@' || regexp_replace(u.username, '([.^$*+?()\[\]{}|\\])', '\\\1', 'g') || '\M')
+       )::int AS mention_count
+FROM room_members rm
+         JOIN users u ON u.id = rm.user_id
+WHERE rm.room_id = $1
+  AND rm.user_id = $2;
+
+-- name: AdvanceReadMarker :execrows
+UPDATE room_members
+SET last_read_message_id = @message_id::uuid,
+    last_read_at         = @message_created_at::timestamptz
+WHERE room_id = @room_id::uuid
+  AND user_id = @user_id::uuid
+  AND (last_read_at IS NULL
+    OR (last_read_at, last_read_message_id) < (@message_created_at::timestamptz, @message_id::uuid));
+
+-- name: GetRoomMemberRole :one
+SELECT role
+FROM room_members
+WHERE room_id = $1
+  AND user_id = $2;
+
+-- name: UpdateRoomMemberRole :execrows
+UPDATE room_members
+SET role = $3
+WHERE room_id = $1
+  AND user_id = $2;
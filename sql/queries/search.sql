@@ -0,0 +1,34 @@
+-- name: SearchMessages :many
+SELECT found.*,
+       ts_headline('simple', found.content, websearch_to_tsquery('simple', @query::text),
+                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20')::text AS highlight
+FROM (SELECT m.id,
+             m.room_id,
+             m.content,
+             m.created_at,
+             m.edited_at,
+             m.thread_id,
+             r.slug                                                                                 AS room_slug,
+             r.name                                                                                 AS room_name,
+             u.id                                                                                   AS sender_id,
+             u.username                                                                             AS sender_username,
+             u.avatar_url                                                                           AS sender_avatar_url,
+             ts_rank(to_tsvector('simple', m.content), websearch_to_tsquery('simple', @query::text)) AS rank
+      FROM messages m
+               JOIN room_members rm ON rm.room_id = m.room_id AND rm.user_id = @user_id::uuid
+               JOIN rooms r ON r.id = m.room_id
+               JOIN users u ON u.id = m.sender_id
+      WHERE to_tsvector('simple', m.content) @@ websearch_to_tsquery('simple', @query::text)
+        AND m.deleted_at IS NULL
+        AND (sqlc.narg(room_id)::uuid IS NULL OR m.room_id = sqlc.narg(room_id)::uuid)
+        AND (sqlc.narg(sender_id)::uuid IS NULL OR m.sender_id = sqlc.narg(sender_id)::uuid)
+        AND (sqlc.narg(sent_after)::timestamptz IS NULL OR m.created_at >= sqlc.narg(sent_after)::timestamptz)
+        AND (sqlc.narg(sent_before)::timestamptz IS NULL OR m.created_at < sqlc.narg(sent_before)::timestamptz)
+        AND (sqlc.narg(has_attachment)::boolean IS NULL OR
+             sqlc.narg(has_attachment)::boolean =
+             EXISTS (SELECT 1 FROM attachments a WHERE a.message_id = m.id))) found
+WHERE sqlc.narg(cursor_rank)::real IS NULL
+   OR (found.rank, found.created_at, found.id) <
+      (sqlc.narg(cursor_rank)::real, @cursor_created_at::timestamptz, @cursor_id::uuid)
+ORDER BY found.rank DESC, found.created_at DESC, found.id DESC
+LIMIT @limit_;
@@ -0,0 +1,47 @@
+-- name: ListThreadSummaries :many
+SELECT m.thread_id::uuid                  AS thread_id,
+       count(*)::int                      AS reply_count,
+       max(m.created_at)::timestamptz     AS last_reply_at,
+       EXISTS (SELECT 1
+               FROM thread_follows f
+               WHERE f.thread_id = m.thread_id
+                 AND f.user_id = @viewer_id::uuid
+                 AND f.following)::boolean AS following
+FROM messages m
+WHERE m.thread_id = ANY (@thread_ids::uuid[])
+  AND m.deleted_at IS NULL
+GROUP BY m.thread_id;
+
+-- name: ListThreadParticipants :many
+SELECT t.thread_id::uuid AS thread_id,
+       u.id,
+       u.username,
+       u.avatar_url
+FROM (SELECT m.thread_id,
+             m.sender_id,
+             row_number() OVER (PARTITION BY m.thread_id ORDER BY max(m.created_at) DESC) AS rank
+      FROM messages m
+      WHERE m.thread_id = ANY (@thread_ids::uuid[])
+        AND m.deleted_at IS NULL
+      GROUP BY m.thread_id, m.sender_id) t
+         JOIN users u ON u.id = t.sender_id
+WHERE t.rank <= @max_participants::int
+ORDER BY t.thread_id, t.rank;
+
+-- name: AutoFollowThread :exec
+INSERT INTO thread_follows (thread_id, user_id, following, updated_at)
+VALUES ($1, $2, TRUE, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: SetThreadFollow :exec
+INSERT INTO thread_follows (thread_id, user_id, following, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (thread_id, user_id) DO UPDATE
+    SET following  = excluded.following,
+        updated_at = excluded.updated_at;
+
+-- name: ListThreadFollowerIDs :many
+SELECT user_id
+FROM thread_follows
+WHERE thread_id = $1
+  AND following;
@@ -73,4 +73,9 @@ WHERE pending_email = $1;
 -- name: IncrementVerificationAttempts :exec
 UPDATE email_verification_codes
 SET attempts = attempts + 1
-WHERE user_id = $1;
\ No newline at end of file
+WHERE user_id = $1;
+
+-- name: UpdateUserShareReadReceipts :exec
+UPDATE users
+SET share_read_receipts = $1
+WHERE id = $2;
@@ -1,15 +1,23 @@
-import { Badge, Box, Group, ScrollArea, Stack, Text, Title, ActionIcon } from '@mantine/core';
-import { useMemo } from 'react';
+import { Badge, Box, Button, Group, Loader, ScrollArea, Stack, Text, TextInput, Title, ActionIcon, rem } from '@mantine/core';
+import { useDebouncedValue } from '@mantine/hooks';
+import { useCallback, useEffect, useState } from 'react';
 import { UserAvatar } from './UserAvatar';
-import { IconX, IconPlayerPlay } from '@tabler/icons-react';
+import { IconSearch, IconX } from '@tabler/icons-react';
+import { api } from '../api';
 
 interface Member {
-    id: string;
+    userId: string;
     username: string;
     avatarUrl?: string;
-    status: 'online' | 'offline';
-    playbackTime?: string;
-    isBuffering?: boolean;
+    role: 'owner' | 'admin' | 'moderator' | 'member';
+    joinedAt: string;
+    status: 'online' | 'idle' | 'offline';
+    lastSeenAt?: string;
+}
+
+interface MembersResponse {
+    members: Member[];
+    nextCursor: string;
 }
 
 interface RoomMembersProps {
@@ -17,11 +25,40 @@ interface RoomMembersProps {
     onClose?: () => void;
 }
 
-export function RoomMembers({ onClose }: RoomMembersProps) {
-    const members: Member[] = useMemo(() => [
-        { id: '1', username: 'yoworu', status: 'online', playbackTime: '00:12:45' },
-        { id: '5', username: 'test', status: 'offline', playbackTime: '00:00:00' },
-    ], []);
+const statusColors: Record<Member['status'], string> = {
+    online: 'var(--mantine-color-teal-filled)',
+    idle: 'var(--mantine-color-yellow-filled)',
+    offline: 'var(--mantine-color-gray-filled)',
+};
+
+export function RoomMembers({ roomSlug, onClose }: RoomMembersProps) {
+    const [members, setMembers] = useState<Member[]>([]);
+    const [nextCursor, setNextCursor] = useState('');
+    const [loading, setLoading] = useState(false);
+    const [searchQuery, setSearchQuery] = useState('');
+    const [debouncedQuery] = useDebouncedValue(searchQuery.trim(), 300);
+
+    const loadMembers = useCallback(async (cursor?: string) => {
+        if (!roomSlug) {
+            return;
+        }
+        try {
+            setLoading(true);
+            const { data } = await api.get<MembersResponse>(`/rooms/${roomSlug}/members`, {
+                params: { q: debouncedQuery || undefined, cursor: cursor || undefined },
+            });
+            setMembers(prev => cursor ? [...prev, ...data.members] : data.members);
+            setNextCursor(data.nextCursor);
+        } catch (err) {
+            console.error('Failed to load members', err);
+        } finally {
+            setLoading(false);
+        }
+    }, [roomSlug, debouncedQuery]);
+
+    useEffect(() => {
+        loadMembers();
+    }, [loadMembers]);
 
     const onlineCount = members.filter(m => m.status !== 'offline').length;
 
@@ -43,10 +80,19 @@ export function RoomMembers({ onClose }: RoomMembersProps) {
                 )}
             </Group>
 
+            <TextInput
+                mb="sm"
+                size="xs"
+                placeholder="Search members..."
+                leftSection={<IconSearch style={{ width: rem(14), height: rem(14) }} stroke={1.5} />}
+                value={searchQuery}
+                onChange={(event) => setSearchQuery(event.currentTarget.value)}
+            />
+
             <ScrollArea style={{ flex: 1 }} offsetScrollbars scrollbarSize={4}>
                 <Stack gap={4}>
                     {members.map((member) => (
-                        <Group key={member.id} wrap="nowrap" gap="sm" p="xs" style={{
+                        <Group key={member.userId} wrap="nowrap" gap="sm" p="xs" style={{
                             borderRadius: 'var(--mantine-radius-md)',
                             transition: 'all 0.2s ease',
                             cursor: 'default'
@@ -62,7 +108,7 @@ export function RoomMembers({ onClose }: RoomMembersProps) {
                                     width: 12,
                                     height: 12,
                                     borderRadius: '50%',
-                                    backgroundColor: member.status == 'offline' ? 'var(--mantine-color-gray-filled)' : 'var(--mantine-color-teal-filled)',
+                                    backgroundColor: statusColors[member.status],
                                     border: '2px solid var(--mantine-color-body)',
                                     zIndex: 1
                                 }} />
@@ -70,31 +116,24 @@ export function RoomMembers({ onClose }: RoomMembersProps) {
 
                             <Box style={{ flex: 1, overflow: 'hidden' }}>
                                 <Text size="sm" fw={500} truncate>{member.username}</Text>
-                                {member.isBuffering && (
-                                    <Text size="xs" c="orange" fw={500} style={{ fontSize: '10px' }}>Buffering...</Text>
-                                )}
+                                <Text size="xs" c="dimmed" truncate>
+                                    Joined {new Date(member.joinedAt).toLocaleDateString()}
+                                </Text>
                             </Box>
 
-                            {member.playbackTime && (
-                                    <Group
-                                        gap={4}
-                                        wrap="nowrap"
-                                        px={8}
-                                        py={2}
-                                        style={{
-                                            borderRadius: '4px',
-                                            backgroundColor: 'var(--mantine-color-default-hover)',
-                                            border: '1px solid var(--mantine-color-default-border)'
-                                        }}
-                                    >
-                                        <IconPlayerPlay size={10} fill="currentColor" />
-                                        <Text size="xs"  style={{ fontVariantNumeric: 'tabular-nums', }}>
-                                            {member.playbackTime}
-                                        </Text>
-                                    </Group>
+                            {member.role !== 'member' && (
+                                <Badge size="xs" variant="light" color={member.role === 'owner' ? 'yellow' : 'gray'}>
+                                    {member.role}
+                                </Badge>
                             )}
                         </Group>
                     ))}
+                    {loading && <Loader size="sm" mx="auto" my="xs" />}
+                    {!loading && nextCursor && (
+                        <Button variant="subtle" size="xs" onClick={() => loadMembers(nextCursor)}>
+                            Load more
+                        </Button>
+                    )}
                 </Stack>
             </ScrollArea>
 
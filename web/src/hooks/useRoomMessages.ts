@@ -8,17 +8,26 @@ export function useRoomMessages(roomSlug: string | undefined) {
     const [nextCursor, setNextCursor] = useState<string | null>(null);
     const [loading, setLoading] = useState(false);
     const [notFound, setNotFound] = useState(false);
+    // forbidden is set while the user is not a member of the room.
+    const [forbidden, setForbidden] = useState(false);
+    const [loaded, setLoaded] = useState(false);
 
     const fetchInitialMessages = useCallback(async () => {
         if (!roomSlug) return;
         setNotFound(false);
+        setForbidden(false);
+        setLoaded(false);
         try {
             const { data } = await messageApi.roomsRoomSlugMessagesGet(roomSlug, 10);
             setMessages(data.messages?.reverse() ?? []);
             setNextCursor(data.nextCursor ?? null);
+            setLoaded(true);
         } catch (error) {
             if (axios.isAxiosError(error) && error.response?.status === 404) {
                 setNotFound(true);
+            } else if (axios.isAxiosError(error) && error.response?.status === 403) {
+                setForbidden(true);
+                return;
             }
             console.error("Failed to fetch messages:", error);
         }
@@ -62,6 +71,9 @@ export function useRoomMessages(roomSlug: string | undefined) {
         nextCursor,
         loading,
         notFound,
+        forbidden,
+        loaded,
+        reload: fetchInitialMessages,
         loadOlderMessages,
         addMessage
     };
@@ -4,17 +4,31 @@ import { WS_BASE_URL } from '../config';
 import { useAuthRefresh } from './useAuthRefresh';
 import type { ModelMessage } from '../../api';
 
+interface WsEnvelope<T = unknown> {
+    type: string;
+    id?: string;
+    data?: T;
+}
+
+// Close code the server uses after the user left, was kicked or was banned.
+const CLOSE_REMOVED_FROM_ROOM = 4003;
+
 interface UseRoomWebSocketProps {
     roomSlug: string | undefined;
+    // enabled is false until the user is known to be a member; the socket
+    // rejects everyone else.
+    enabled: boolean;
     onMessageReceived: (message: ModelMessage) => void;
+    onRemoved: () => void;
 }
 
-export function useRoomWebSocket({ roomSlug, onMessageReceived }: UseRoomWebSocketProps) {
+export function useRoomWebSocket({ roomSlug, enabled, onMessageReceived, onRemoved }: UseRoomWebSocketProps) {
     const { token, ensureValidToken } = useAuthRefresh();
     const [socketUrl, setSocketUrl] = useState<string | null>(null);
 
     useEffect(() => {
-        if (!roomSlug) {
+        if (!roomSlug || !enabled) {
+            setSocketUrl(null);
             return;
         }
 
@@ -28,28 +42,55 @@ export function useRoomWebSocket({ roomSlug, onMessageReceived }: UseRoomWebSock
         };
 
         prepareSocket();
-    }, [roomSlug, token, ensureValidToken]);
+    }, [roomSlug, enabled, token, ensureValidToken]);
 
     const { sendMessage } = useWebSocket(socketUrl, {
-        shouldReconnect: () => {
+        shouldReconnect: (event) => {
+            // The socket would reject a removed member anyway.
+            if (event.code === CLOSE_REMOVED_FROM_ROOM) {
+                return false;
+            }
             ensureValidToken().catch(() => { });
             return true;
         },
         reconnectAttempts: 20,
         reconnectInterval: 3000,
+        onClose: (event) => {
+            if (event.code === CLOSE_REMOVED_FROM_ROOM) {
+                onRemoved();
+            }
+        },
         onMessage: (event) => {
+            let envelope: WsEnvelope;
             try {
-                const data = JSON.parse(event.data);
-                onMessageReceived(data);
+                envelope = JSON.parse(event.data);
             } catch (err) {
                 console.error("Failed to parse WS message:", err);
+                return;
+            }
+
+            switch (envelope.type) {
+                case 'message.created':
+                    onMessageReceived(envelope.data as ModelMessage);
+                    break;
+                case 'error':
+                    console.error("WS command rejected:", envelope.id, envelope.data);
+                    break;
+                default:
+                    // Unknown event types are ignored so the server can add new ones.
+                    break;
             }
         },
     });
 
     const sendRoomMessage = useCallback((content: string) => {
         if (content.trim()) {
-            sendMessage(content.trim().replace(/\n\n+/g, '\n\n'));
+            const envelope: WsEnvelope<{ content: string }> = {
+                type: 'message.send',
+                id: crypto.randomUUID(),
+                data: { content: content.trim().replace(/\n\n+/g, '\n\n') },
+            };
+            sendMessage(JSON.stringify(envelope));
         }
     }, [sendMessage]);
 
@@ -3,6 +3,7 @@ import {
     ActionIcon,
     Box,
     Button,
+    Center,
     Drawer,
     Flex,
     Group,
@@ -28,6 +29,7 @@ import { EmojiPicker } from "../components/EmojiPicker.tsx";
 import { isEmojiOnly } from "../utils/isEmojiOnly.ts";
 import type { EmojiClickData } from "emoji-picker-react";
 import type { ModelMessage } from "../../api";
+import { roomApi } from "../api.ts";
 
 import { useRoomMessages } from "../hooks/useRoomMessages";
 import { useRoomWebSocket } from "../hooks/useRoomWebSocket";
@@ -46,6 +48,9 @@ export default function Room() {
     const [value, setValue] = useState("");
     const [showEmojiPicker, setShowEmojiPicker] = useState(false);
     const [isVideoFullscreen, setIsVideoFullscreen] = useState(false);
+    const [removed, setRemoved] = useState(false);
+    const [joining, setJoining] = useState(false);
+    const [joinError, setJoinError] = useState<string | null>(null);
     const textareaRef = useRef<HTMLTextAreaElement>(null);
     const messageAudioRef = useRef(new Audio(messagePopAudio));
     const videoContainerRef = useRef<HTMLDivElement>(null);
@@ -55,11 +60,16 @@ export default function Room() {
     const {
         messages,
         notFound,
+        forbidden,
+        loaded,
+        reload,
         loadOlderMessages,
         addMessage,
         nextCursor
     } = useRoomMessages(roomSlug);
 
+    const needsJoin = forbidden || removed;
+
     const {
         viewportRef,
         unreadCount,
@@ -98,11 +108,36 @@ export default function Room() {
         }
     }, [user?.username, isAtBottom, isTabVisible, addMessage, scrollToBottom, showNotification, incrementUnread]);
 
+    const onRemoved = useCallback(() => setRemoved(true), []);
+
     const { sendRoomMessage } = useRoomWebSocket({
         roomSlug,
-        onMessageReceived
+        enabled: loaded && !needsJoin,
+        onMessageReceived,
+        onRemoved
     });
 
+    useEffect(() => {
+        setRemoved(false);
+        setJoinError(null);
+    }, [roomSlug]);
+
+    const joinRoom = useCallback(async () => {
+        if (!roomSlug) return;
+        setJoining(true);
+        setJoinError(null);
+        try {
+            await roomApi.roomsRoomSlugPost(roomSlug);
+            setRemoved(false);
+            await reload();
+        } catch (err: any) {
+            console.error("Failed to join room:", err);
+            setJoinError(err.response?.data?.error?.message || 'Failed to join the room');
+        } finally {
+            setJoining(false);
+        }
+    }, [roomSlug, reload]);
+
     useEffect(() => {
         if (messages.length > 0 && isAtBottom) {
             scrollToBottom("auto");
@@ -204,6 +239,24 @@ export default function Room() {
 
     if (notFound) return <NotFound />;
 
+    if (needsJoin) {
+        return (
+            <Center h="calc(100vh - 80px)">
+                <Stack align="center" gap="md" maw={400}>
+                    <Text size="lg" fw={600}>You are not a member of this room</Text>
+                    {joinError && (
+                        <Text size="sm" c="red" ta="center">
+                            {joinError}
+                        </Text>
+                    )}
+                    <Button onClick={joinRoom} loading={joining} variant="filled">
+                        Join room
+                    </Button>
+                </Stack>
+            </Center>
+        );
+    }
+
     return (
         <Flex h="100%" w="100%" direction="row" gap="md" style={{ overflow: 'hidden' }}>
             <Box style={{ flex: 1, display: 'flex', flexDirection: 'column', overflow: 'hidden' }}>
@@ -367,7 +420,7 @@ export default function Room() {
                         position: 'relative',
                     }}
                 >
-                    <RoomMembers onClose={() => setMemberSidebarOpen(false)} />
+                    <RoomMembers roomSlug={roomSlug} onClose={() => setMemberSidebarOpen(false)} />
                 </Paper>
             </Box>
 
@@ -382,7 +435,7 @@ export default function Room() {
                     content: { borderRadius: '16px 0 0 16px' }
                 }}
             >
-                <RoomMembers onClose={() => setMemberSidebarOpen(false)} />
+                <RoomMembers roomSlug={roomSlug} onClose={() => setMemberSidebarOpen(false)} />
             </Drawer>
         </Flex>
     );